@@ -0,0 +1,417 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// backupCompactionResumer implements jobs.Resumer for a job compacting a full
+// backup and the incremental backups appended to it into a new full backup.
+//
+// The compaction only reads the SSTs of the backups from external storage and
+// writes the merged SSTs back to external storage; it never touches the KV
+// layer of the cluster running it.
+type backupCompactionResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &backupCompactionResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+//
+// TODO(dt): checkpoint the spans which have already been compacted so that a
+// resumed job does not have to start over.
+func (r *backupCompactionResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	details := r.job.Details().(jobspb.BackupCompactionDetails)
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	user := p.User()
+
+	destConf, err := cloudimpl.ExternalStorageConfFromURI(details.Destination, user)
+	if err != nil {
+		return err
+	}
+	destStore, err := execCfg.DistSQLSrv.ExternalStorage(ctx, destConf)
+	if err != nil {
+		return err
+	}
+	defer destStore.Close()
+
+	// The manifest is the last file written by the compaction, so if it exists
+	// a previous run of this job has already completed the compaction.
+	if exists, err := containsManifest(ctx, destStore); err != nil {
+		return err
+	} else if exists {
+		log.Infof(ctx, "backup compaction job %d found a manifest in %s, nothing to do",
+			*r.job.ID(), RedactURIForErrorMessage(details.Destination))
+		return nil
+	}
+
+	baseStore, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.URI, user)
+	if err != nil {
+		return err
+	}
+	defer baseStore.Close()
+
+	encryption := details.EncryptionOptions
+	manifests, err := resolveBackupChain(ctx, execCfg, baseStore, details.URI, encryption, user)
+	if err != nil {
+		return err
+	}
+
+	var fileEncryption *roachpb.FileEncryptionOptions
+	if encryption != nil {
+		key, err := getEncryptionKey(ctx, encryption, execCfg.Settings, execCfg.ExternalIODirConfig)
+		if err != nil {
+			return err
+		}
+		fileEncryption = &roachpb.FileEncryptionOptions{Key: key}
+		// Copy the encryption info of the chain, so that the compacted backup can
+		// be restored with the same passphrase or KMS as the backups it replaces.
+		opts, err := readEncryptionOptions(ctx, baseStore)
+		if err != nil {
+			return err
+		}
+		if err := writeEncryptionInfoIfNotExists(ctx, opts, destStore); err != nil {
+			return err
+		}
+	}
+
+	last := manifests[len(manifests)-1]
+	entries, _, err := makeImportSpans(
+		last.Spans, manifests, nil /* backupLocalityInfo */, keys.MinKey, user, errOnMissingRange,
+	)
+	if err != nil {
+		return err
+	}
+
+	keepRevisions := true
+	for i := range manifests {
+		if manifests[i].MVCCFilter != MVCCFilter_All {
+			keepRevisions = false
+		}
+	}
+
+	pkIDs := make(map[uint64]bool)
+	for i := range last.Descriptors {
+		if t := descpb.TableFromDescriptor(&last.Descriptors[i], hlc.Timestamp{}); t != nil {
+			pkIDs[roachpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+		}
+	}
+
+	compacted := last
+	compacted.ID = uuid.MakeV4()
+	compacted.Dir = destConf
+	compacted.StartTime = hlc.Timestamp{}
+	compacted.IntroducedSpans = nil
+	compacted.Files = nil
+	compacted.EntryCounts = RowCount{}
+	compacted.PartitionDescriptorFilenames = nil
+	compacted.LocalityKVs = nil
+	compacted.DescriptorChanges = nil
+	for i := range manifests {
+		if keepRevisions {
+			compacted.DescriptorChanges = append(compacted.DescriptorChanges, manifests[i].DescriptorChanges...)
+		}
+		if compacted.RevisionStartTime.Less(manifests[i].RevisionStartTime) {
+			compacted.RevisionStartTime = manifests[i].RevisionStartTime
+		}
+	}
+	if !keepRevisions {
+		compacted.MVCCFilter = MVCCFilter_Latest
+		compacted.RevisionStartTime = hlc.Timestamp{}
+	}
+
+	var lastFraction float32
+	for i, entry := range entries {
+		file, err := compactSpanEntry(
+			ctx, execCfg, destStore, entry, fmt.Sprintf("%d.sst", i), keepRevisions, fileEncryption, pkIDs,
+		)
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			compacted.Files = append(compacted.Files, *file)
+			compacted.EntryCounts.add(file.EntryCounts)
+		}
+
+		if fraction := float32(i+1) / float32(len(entries)); fraction-lastFraction > 0.05 {
+			if err := r.job.FractionProgressed(ctx, jobs.FractionUpdater(fraction)); err != nil {
+				log.Warningf(ctx, "failed to update job progress: %+v", err)
+			}
+			lastFraction = fraction
+		}
+	}
+
+	// The table statistics of the compacted backup are those of its last
+	// layer, i.e. the statistics as of its end time.
+	lastStore, err := execCfg.DistSQLSrv.ExternalStorage(ctx, last.Dir)
+	if err != nil {
+		return err
+	}
+	defer lastStore.Close()
+	tableStatistics, err := getStatisticsFromBackup(ctx, lastStore, encryption, last)
+	if err != nil {
+		return err
+	}
+	compacted.DeprecatedStatistics = nil
+	compacted.StatisticsFilenames = nil
+	if len(tableStatistics) > 0 {
+		if err := writeTableStatistics(
+			ctx, destStore, backupStatisticsFileName, encryption, &StatsTable{Statistics: tableStatistics},
+		); err != nil {
+			return err
+		}
+		compacted.StatisticsFilenames = make(map[descpb.ID]string)
+		for _, stat := range tableStatistics {
+			compacted.StatisticsFilenames[stat.TableID] = backupStatisticsFileName
+		}
+	}
+
+	return writeBackupManifest(ctx, execCfg.Settings, destStore, backupManifestName, encryption, &compacted)
+}
+
+// resolveBackupChain returns the manifests of the full backup at uri and of
+// the incremental backups appended to it, in order.
+func resolveBackupChain(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	baseStore cloud.ExternalStorage,
+	uri string,
+	encryption *jobspb.BackupEncryptionOptions,
+	user security.SQLUsername,
+) ([]BackupManifest, error) {
+	mkStore := execCfg.DistSQLSrv.ExternalStorageFromURI
+	defaultURIs, manifests, localityInfo, err := resolveBackupManifests(
+		ctx, []cloud.ExternalStorage{baseStore}, mkStore, [][]string{{uri}}, hlc.Timestamp{}, encryption, user,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i := range manifests {
+		if len(localityInfo[i].URIsByOriginalLocalityKV) > 0 || len(manifests[i].PartitionDescriptorFilenames) > 0 {
+			return nil, errors.Newf("compacting locality-aware backups is not supported")
+		}
+	}
+	// Reload the manifests from their own URIs, so that the SSTs of every
+	// layer are read from the directory of that layer.
+	return loadBackupManifests(ctx, defaultURIs, user, mkStore, encryption)
+}
+
+// compactSpanEntry merges the SSTs covering the span of the entry into a
+// single SST written to dest under the given name. Unless keepRevisions is
+// set, only the latest revision of every key is kept and deleted keys are
+// dropped entirely. It returns nil if the span contains no data.
+func compactSpanEntry(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	dest cloud.ExternalStorage,
+	entry execinfrapb.RestoreSpanEntry,
+	name string,
+	keepRevisions bool,
+	encryption *roachpb.FileEncryptionOptions,
+	pkIDs map[uint64]bool,
+) (*BackupManifest_File, error) {
+	// Later layers come later in entry.Files, and for identical keys the
+	// multi-iterator surfaces the iterator which was added last.
+	iters := make([]storage.SimpleMVCCIterator, 0, len(entry.Files))
+	for _, file := range entry.Files {
+		dir, err := execCfg.DistSQLSrv.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := dir.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}()
+		iter, err := storageccl.ExternalSSTReader(ctx, dir, file.Path, encryption)
+		if err != nil {
+			return nil, err
+		}
+		defer iter.Close()
+		iters = append(iters, iter)
+	}
+	iter := storage.MakeMultiIterator(iters)
+	defer iter.Close()
+
+	sstFile := &storage.MemFile{}
+	sst := storage.MakeBackupSSTWriter(sstFile)
+	defer sst.Close()
+
+	var rows storage.RowCounter
+	endKeyMVCC := storage.MVCCKey{Key: entry.Span.EndKey}
+	for iter.SeekGE(storage.MVCCKey{Key: entry.Span.Key}); ; {
+		ok, err := iter.Valid()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || !iter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+		key, value := iter.UnsafeKey(), iter.UnsafeValue()
+		if !keepRevisions && len(value) == 0 {
+			// The latest revision of the key is a deletion.
+			iter.NextKey()
+			continue
+		}
+		if err := rows.Count(key.Key); err != nil {
+			return nil, err
+		}
+		rows.BulkOpSummary.DataSize += int64(len(key.Key) + len(value))
+		if key.Timestamp.IsEmpty() {
+			err = sst.PutUnversioned(key.Key, value)
+		} else {
+			err = sst.PutMVCC(key, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if keepRevisions {
+			iter.Next()
+		} else {
+			iter.NextKey()
+		}
+	}
+	if rows.BulkOpSummary.DataSize == 0 {
+		return nil, nil
+	}
+	if err := sst.Finish(); err != nil {
+		return nil, err
+	}
+
+	data := sstFile.Data()
+	checksum, err := storageccl.SHA512ChecksumData(data)
+	if err != nil {
+		return nil, err
+	}
+	if encryption != nil {
+		if data, err = storageccl.EncryptFile(data, encryption.Key); err != nil {
+			return nil, err
+		}
+	}
+	if err := dest.WriteFile(ctx, name, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "writing SST")
+	}
+	return &BackupManifest_File{
+		Span:        entry.Span,
+		Path:        name,
+		Sha512:      checksum,
+		EntryCounts: countRows(rows.BulkOpSummary, pkIDs),
+	}, nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. The files written by
+// the job are left in the destination, which cannot be restored from without
+// a manifest.
+func (r *backupCompactionResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+// compactBackup creates a job compacting the full backup at uri and the
+// incremental backups appended to it into a new full backup at destination,
+// and returns the ID of the job.
+func compactBackup(
+	evalCtx *tree.EvalContext, txn *kv.Txn, uri, destination, passphrase string,
+) (int64, error) {
+	p, ok := evalCtx.Planner.(sql.PlanHookState)
+	if !ok {
+		return 0, errors.AssertionFailedf("unexpected planner type %T", evalCtx.Planner)
+	}
+	ctx := evalCtx.Context
+	execCfg := p.ExecCfg()
+	if err := utilccl.CheckEnterpriseEnabled(
+		execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(), "backup compaction",
+	); err != nil {
+		return 0, err
+	}
+	if err := p.RequireAdminRole(ctx, "compact a backup"); err != nil {
+		return 0, err
+	}
+
+	destStore, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, destination, p.User())
+	if err != nil {
+		return 0, err
+	}
+	defer destStore.Close()
+	if err := checkForPreviousBackup(ctx, destStore, destination); err != nil {
+		return 0, err
+	}
+
+	var encryption *jobspb.BackupEncryptionOptions
+	if passphrase != "" {
+		baseStore, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, uri, p.User())
+		if err != nil {
+			return 0, err
+		}
+		defer baseStore.Close()
+		opts, err := readEncryptionOptions(ctx, baseStore)
+		if err != nil {
+			return 0, err
+		}
+		encryption = &jobspb.BackupEncryptionOptions{
+			Mode: jobspb.EncryptionMode_Passphrase,
+			Key:  storageccl.GenerateKey([]byte(passphrase), opts.Salt),
+		}
+	}
+
+	record := jobs.Record{
+		Description: fmt.Sprintf("COMPACT BACKUP %s INTO %s",
+			RedactURIForErrorMessage(uri), RedactURIForErrorMessage(destination)),
+		Username: p.User(),
+		Details: jobspb.BackupCompactionDetails{
+			URI:               uri,
+			Destination:       destination,
+			EncryptionOptions: encryption,
+		},
+		Progress: jobspb.BackupCompactionProgress{},
+	}
+	job, err := execCfg.JobRegistry.CreateAdoptableJobWithTxn(ctx, record, txn)
+	if err != nil {
+		return 0, err
+	}
+	return *job.ID(), nil
+}
+
+func init() {
+	builtins.CompactBackup = compactBackup
+	jobs.RegisterConstructor(
+		jobspb.TypeBackupCompaction,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &backupCompactionResumer{
+				job: job,
+			}
+		},
+	)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	for _, tc := range []struct {
+		name       string
+		passphrase string
+	}{
+		{name: "unencrypted"},
+		{name: "encrypted", passphrase: "abcdefg"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			collection := LocalFoo + "/" + tc.name
+			dest := LocalFoo + "/" + tc.name + "-compacted"
+			var opts, compactArgs string
+			if tc.passphrase != "" {
+				opts = fmt.Sprintf(` WITH encryption_passphrase = '%s'`, tc.passphrase)
+				compactArgs = fmt.Sprintf(`, '%s'`, tc.passphrase)
+			}
+
+			// Build a chain of a full backup and two incremental backups which
+			// insert, update and delete rows.
+			sqlDB.Exec(t, `BACKUP data.bank INTO $1`+opts, collection)
+			sqlDB.Exec(t, `UPSERT INTO data.bank VALUES (1000, 1, 'inserted')`)
+			sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+			sqlDB.Exec(t, `BACKUP data.bank INTO LATEST IN $1`+opts, collection)
+			sqlDB.Exec(t, `DELETE FROM data.bank WHERE id >= 90 AND id < 1000`)
+			sqlDB.Exec(t, `BACKUP data.bank INTO LATEST IN $1`+opts, collection)
+			subdir := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[0][0]
+
+			var jobID int64
+			sqlDB.QueryRow(t, `SELECT crdb_internal.compact_backup($1, $2`+compactArgs+`)`,
+				collection+subdir, dest).Scan(&jobID)
+			jobutils.WaitForJob(t, sqlDB, jobID)
+
+			// The compacted backup is a single full backup.
+			require.Equal(t, [][]string{{"full"}}, sqlDB.QueryStr(t,
+				`SELECT DISTINCT backup_type FROM [SHOW BACKUP $1`+opts+`]`, dest))
+
+			// Restoring it yields the data as of the last incremental backup.
+			restoredDB := fmt.Sprintf("restored_%s", tc.name)
+			sqlDB.Exec(t, fmt.Sprintf(`CREATE DATABASE %s`, restoredDB))
+			restoreOpts := fmt.Sprintf(` WITH into_db = '%s'`, restoredDB)
+			if tc.passphrase != "" {
+				restoreOpts += fmt.Sprintf(`, encryption_passphrase = '%s'`, tc.passphrase)
+			}
+			sqlDB.Exec(t, `RESTORE data.bank FROM $1`+restoreOpts, dest)
+			require.Equal(t,
+				sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`),
+				sqlDB.QueryStr(t, fmt.Sprintf(`SELECT * FROM %s.bank ORDER BY id`, restoredDB)))
+
+			// A backup cannot be compacted into a destination which already
+			// contains one.
+			sqlDB.ExpectErr(t, "already contains a BACKUP file",
+				`SELECT crdb_internal.compact_backup($1, $2`+compactArgs+`)`, collection+subdir, dest)
+		})
+	}
+}
@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
 	"github.com/gogo/protobuf/types"
 )
 
@@ -214,6 +215,7 @@ func backup(
 		roachpb.MVCCFilter(backupManifest.MVCCFilter),
 		backupManifest.StartTime,
 		backupManifest.EndTime,
+		*job.ID(),
 	)
 	if err != nil {
 		return RowCount{}, err
@@ -600,6 +602,7 @@ func (b *backupResumer) maybeNotifyScheduledJobCompletion(
 		}
 	}
 
+	var scheduleID int64
 	if err := exec.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		// Do not rely on b.job containing created_by_id.  Query it directly.
 		datums, err := exec.InternalExecutor.QueryRowEx(
@@ -620,7 +623,7 @@ func (b *backupResumer) maybeNotifyScheduledJobCompletion(
 			return nil
 		}
 
-		scheduleID := int64(tree.MustBeDInt(datums[0]))
+		scheduleID = int64(tree.MustBeDInt(datums[0]))
 		if err := jobs.NotifyJobTermination(
 			ctx, env, *b.job.ID(), jobStatus, b.job.Details(), scheduleID, exec.InternalExecutor, txn); err != nil {
 			log.Warningf(ctx,
@@ -631,6 +634,41 @@ func (b *backupResumer) maybeNotifyScheduledJobCompletion(
 	}); err != nil {
 		log.Errorf(ctx, "maybeNotifySchedule error: %v", err)
 	}
+
+	// Once a scheduled backup succeeds, the backups of the schedule which are
+	// older than its retention can be deleted. Deleting them may take a while,
+	// so it is done asynchronously rather than delaying the completion of the
+	// job.
+	if scheduleID != 0 && jobStatus == jobs.StatusSucceeded {
+		startGCScheduledBackups(ctx, exec, env, scheduleID)
+	}
+}
+
+// startGCScheduledBackups starts an async task deleting the expired backups of
+// the schedule, see gcScheduledBackups.
+func startGCScheduledBackups(
+	ctx context.Context,
+	exec *sql.ExecutorConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	scheduleID int64,
+) {
+	stopper := exec.DistSQLSrv.Stopper
+	// The task outlives the job, so it doesn't use the context of the job, which
+	// is canceled once the job completes.
+	taskCtx := logtags.WithTags(context.Background(), logtags.FromContext(ctx))
+	if err := stopper.RunAsyncTask(taskCtx, "backup-schedule-gc", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		sj, err := jobs.LoadScheduledJob(ctx, env, scheduleID, exec.InternalExecutor, nil /* txn */)
+		if err == nil {
+			err = gcScheduledBackups(ctx, exec, env, sj)
+		}
+		if err != nil {
+			log.Warningf(ctx, "failed to delete expired backups of schedule %d: %v", scheduleID, err)
+		}
+	}); err != nil {
+		log.Warningf(ctx, "failed to start deleting expired backups of schedule %d: %v", scheduleID, err)
+	}
 }
 
 // OnFailOrCancel is part of the jobs.Resumer interface.
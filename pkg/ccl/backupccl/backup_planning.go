@@ -59,6 +59,8 @@ const (
 	backupOptEncPassphrase   = "encryption_passphrase"
 	backupOptEncKMS          = "kms"
 	backupOptWithPrivileges  = "privileges"
+	backupOptCheckFiles      = "check_files"
+	backupOptVerifyChecksums = "verify_checksums"
 	localityURLParam         = "COCKROACH_LOCALITY"
 	defaultLocalityValue     = "default"
 )
@@ -1060,6 +1062,12 @@ func backupPlanHook(
 			StatisticsFilenames: statsFiles,
 			DescriptorCoverage:  backupStmt.Coverage(),
 		}
+		// Record the schedule which created the backup, so that the schedule only
+		// deletes its own backups once they expire.
+		if backupStmt.CreatedByInfo != nil &&
+			backupStmt.CreatedByInfo.Name == jobs.CreatedByScheduledJobs {
+			backupManifest.ScheduleID = backupStmt.CreatedByInfo.ID
+		}
 
 		// Sanity check: re-run the validation that RESTORE will do, but this time
 		// including this backup, to ensure that the this backup plus any previous
@@ -233,6 +233,12 @@ func runBackupProcessor(
 	// complete i.e all resumeSpans have been processed, before we report it to
 	// the coordinator. This is required to keep progress logging accurate.
 	spanIdxToProgressDetails := make(map[int]BackupManifest_Progress)
+
+	throttle := startProcessorThrottle(
+		ctx, clusterSettings, flowCtx.Cfg.BulkIOThrottle, flowCtx.Cfg.JobRegistry, spec.JobID,
+	)
+	defer throttle.close()
+
 	return ctxgroup.GroupWorkers(ctx, numSenders, func(ctx context.Context, _ int) error {
 		readTime := spec.BackupEndTime.GoTime()
 
@@ -311,11 +317,30 @@ func runBackupProcessor(
 				if req.ReturnSST {
 					header.TargetBytes = 1
 				}
+				// Similarly, if the bytes written by the backup are limited, each
+				// ExportRequest exports a single SST, and the bytes of an SST of the
+				// target size are acquired before it's read and written.
+				var estimatedBytes int64
+				if throttle.backupBytesLimited() {
+					header.TargetBytes = 1
+					estimatedBytes = targetFileSize
+				}
 
 				log.Infof(ctx, "sending ExportRequest for span %s (attempt %d, priority %s)",
 					span.span, span.attempts+1, header.UserPriority.String())
+				settleBytes, err := throttle.acquireBackupBytes(ctx, estimatedBytes)
+				if err != nil {
+					return err
+				}
+				release, err := throttle.acquireExportRequest(ctx)
+				if err != nil {
+					_ = settleBytes(ctx, 0)
+					return err
+				}
 				rawRes, pErr := kv.SendWrappedWith(ctx, flowCtx.Cfg.DB.NonTransactionalSender(), header, req)
+				release()
 				if pErr != nil {
+					_ = settleBytes(ctx, 0)
 					if _, ok := pErr.GetDetail().(*roachpb.WriteIntentError); ok {
 						span.lastTried = timeutil.Now()
 						span.attempts++
@@ -328,6 +353,14 @@ func runBackupProcessor(
 				}
 				res := rawRes.(*roachpb.ExportResponse)
 
+				var exportedBytes int64
+				for _, file := range res.Files {
+					exportedBytes += file.Exported.DataSize
+				}
+				if err := settleBytes(ctx, exportedBytes); err != nil {
+					return err
+				}
+
 				if backupKnobs, ok := flowCtx.TestingKnobs().BackupRestoreTestingKnobs.(*sql.BackupRestoreTestingKnobs); ok {
 					if backupKnobs.RunAfterExportingSpanEntry != nil {
 						backupKnobs.RunAfterExportingSpanEntry(ctx)
@@ -383,7 +416,7 @@ func runBackupProcessor(
 					spanIdxToProgressDetails[span.spanIdx] = progDetails
 				}
 
-				if req.ReturnSST && res.ResumeSpan != nil {
+				if res.ResumeSpan != nil {
 					if !res.ResumeSpan.Valid() {
 						return errors.Errorf("invalid resume span: %s", res.ResumeSpan)
 					}
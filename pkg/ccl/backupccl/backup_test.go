@@ -4674,6 +4674,130 @@ func TestRestoreInto(t *testing.T) {
 	sqlDB.CheckQueryResults(t, `SELECT * FROM "data 2".bank`, expected)
 }
 
+func TestRestoreNewDBName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE SCHEMA data.sc`)
+	sqlDB.Exec(t, `CREATE TYPE data.sc.greeting AS ENUM ('hello', 'howdy')`)
+	sqlDB.Exec(t, `CREATE TABLE data.sc.t (g data.sc.greeting)`)
+	sqlDB.Exec(t, `INSERT INTO data.sc.t VALUES ('howdy')`)
+	sqlDB.Exec(t, `CREATE VIEW data.v AS SELECT count(*) FROM data.public.bank`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+
+	sqlDB.ExpectErr(t, `database "data" already exists`, `RESTORE DATABASE data FROM $1`, LocalFoo)
+	sqlDB.ExpectErr(t, `"new_db_name" option can only be used when restoring a single database`,
+		`RESTORE TABLE data.bank FROM $1 WITH new_db_name = 'data_copy'`, LocalFoo)
+
+	// The database is restored beside the original one.
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1 WITH new_db_name = 'data_copy'`, LocalFoo)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data_copy.bank`, sqlDB.QueryStr(t, `SELECT * FROM data.bank`))
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data_copy.sc.t`, [][]string{{"howdy"}})
+	// The database qualifier of the view query is rewritten to the new name.
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE true`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data_copy.v`, [][]string{{"10"}})
+
+	sqlDB.ExpectErr(t, `database "data_copy" already exists`,
+		`RESTORE DATABASE data FROM $1 WITH new_db_name = 'data_copy'`, LocalFoo)
+}
+
+func TestRestoreSchemaOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE SCHEMA data.sc`)
+	sqlDB.Exec(t, `CREATE TYPE data.sc.greeting AS ENUM ('hello', 'howdy')`)
+	sqlDB.Exec(t, `CREATE TABLE data.sc.t (g data.sc.greeting)`)
+	sqlDB.Exec(t, `INSERT INTO data.sc.t VALUES ('howdy')`)
+	sqlDB.Exec(t, `CREATE VIEW data.v AS SELECT count(*) FROM data.bank`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+
+	sqlDB.ExpectErr(t, `"schema_only" option cannot be used in a full cluster restore`,
+		`RESTORE FROM $1 WITH schema_only`, LocalFoo)
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1 WITH schema_only, new_db_name = 'data_schema'`, LocalFoo)
+
+	// All the descriptors are restored, but none of the data.
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data_schema.bank`, [][]string{{"0"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data_schema.sc.t`, [][]string{{"0"}})
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data_schema.v`, [][]string{{"0"}})
+	sqlDB.Exec(t, `INSERT INTO data_schema.sc.t VALUES ('hello')`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data_schema.sc.t`, [][]string{{"hello"}})
+	sqlDB.CheckQueryResults(t, `SELECT column_name, data_type FROM [SHOW COLUMNS FROM data_schema.bank]`,
+		sqlDB.QueryStr(t, `SELECT column_name, data_type FROM [SHOW COLUMNS FROM data.bank]`))
+}
+
+func TestRestoreFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE INDEX balance_idx ON data.bank (balance)`)
+	sqlDB.Exec(t, `CREATE TABLE data.multi (a INT, b STRING, c INT, PRIMARY KEY (a, b DESC))`)
+	sqlDB.Exec(t, `INSERT INTO data.multi SELECT i % 3, i::STRING, i FROM generate_series(0, 29) AS g(i)`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+
+	for _, tc := range []struct {
+		table  string
+		filter string
+		where  string
+	}{
+		{table: "bank", filter: "id < 10", where: "id < 10"},
+		{table: "bank", filter: "id IN (1, 50, 99, 1000)", where: "id IN (1, 50, 99)"},
+		{table: "bank", filter: "30 < id AND id <= 40", where: "id > 30 AND id <= 40"},
+		{table: "bank", filter: "id BETWEEN 20 AND 29 AND id >= 25", where: "id >= 25 AND id <= 29"},
+		{table: "multi", filter: "a = 1", where: "a = 1"},
+		{table: "multi", filter: "a IN (0, 2) AND b > '2' AND b < '5'", where: "a IN (0, 2) AND b > '2' AND b < '5'"},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			sqlDB.Exec(t, fmt.Sprintf(`RESTORE data.%s FROM $1 WITH into_db = 'restored', filter = $2`, tc.table),
+				LocalFoo, tc.filter)
+			expected := sqlDB.QueryStr(t,
+				fmt.Sprintf(`SELECT * FROM data.%s WHERE %s ORDER BY 1, 2`, tc.table, tc.where))
+			require.NotEmpty(t, expected)
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT * FROM restored.%s ORDER BY 1, 2`, tc.table), expected)
+			sqlDB.Exec(t, fmt.Sprintf(`DROP TABLE restored.%s`, tc.table))
+		})
+	}
+
+	// The secondary indexes of the restored table are backfilled from the
+	// restored rows.
+	sqlDB.Exec(t, `RESTORE data.bank FROM $1 WITH into_db = 'restored', filter = 'id >= 90'`, LocalFoo)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM restored.bank@balance_idx`, [][]string{{"10"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM restored.bank@primary`, [][]string{{"10"}})
+	sqlDB.Exec(t, `DROP TABLE restored.bank`)
+
+	for _, tc := range []struct {
+		table  string
+		filter string
+		err    string
+	}{
+		{table: "bank", filter: "id != 25", err: `unsupported "filter" predicate`},
+		{table: "bank", filter: "balance > 0", err: `column "balance" is not part of the primary key`},
+		{table: "bank", filter: "id > 10 AND id < 5", err: `does not match any rows`},
+		{table: "multi", filter: "b = '1'", err: `"filter" option must constrain the first column of the primary key`},
+		{table: "multi", filter: "a < 1 AND b = '1'", err: `"filter" option can only constrain column "b"`},
+	} {
+		sqlDB.ExpectErr(t, tc.err,
+			fmt.Sprintf(`RESTORE data.%s FROM $1 WITH into_db = 'restored', filter = $2`, tc.table),
+			LocalFoo, tc.filter)
+	}
+	sqlDB.ExpectErr(t, `"filter" option can only be used when restoring a single table`,
+		`RESTORE DATABASE data FROM $1 WITH filter = 'id < 10'`, LocalFoo)
+}
+
 func TestRestoreDatabaseVersusTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
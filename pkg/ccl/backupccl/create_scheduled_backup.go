@@ -39,6 +39,7 @@ const (
 	optOnPreviousRunning       = "on_previous_running"
 	optIgnoreExistingBackups   = "ignore_existing_backups"
 	optUpdatesLastBackupMetric = "updates_cluster_last_backup_time_metric"
+	optRetention               = "retention"
 )
 
 var scheduledBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
@@ -47,6 +48,7 @@ var scheduledBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
 	optOnPreviousRunning:       sql.KVStringOptRequireValue,
 	optIgnoreExistingBackups:   sql.KVStringOptRequireNoValue,
 	optUpdatesLastBackupMetric: sql.KVStringOptRequireNoValue,
+	optRetention:               sql.KVStringOptRequireValue,
 }
 
 // scheduledBackupEval is a representation of tree.ScheduledBackup, prepared
@@ -130,6 +132,27 @@ func scheduleFirstRun(evalCtx *tree.EvalContext, opts map[string]string) (*time.
 	return nil, nil
 }
 
+// scheduleRetention returns the duration for which the backups created by the
+// schedule are kept, or 0 if they are kept forever.
+func scheduleRetention(opts map[string]string) (time.Duration, error) {
+	v, ok := opts[optRetention]
+	if !ok {
+		return 0, nil
+	}
+	retention, err := tree.ParseDInterval(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s %q", optRetention, v)
+	}
+	nanos, _, _, err := retention.Duration.Encode()
+	if err != nil {
+		return 0, err
+	}
+	if nanos <= 0 {
+		return 0, errors.Newf("%s must be positive, got %q", optRetention, v)
+	}
+	return time.Duration(nanos), nil
+}
+
 type scheduleRecurrence struct {
 	cron      string
 	frequency time.Duration
@@ -325,6 +348,17 @@ func doCreateBackupSchedules(
 		return err
 	}
 
+	retention, err := scheduleRetention(scheduleOptions)
+	if err != nil {
+		return err
+	}
+	// The schedule tells the backups it created apart from the other backups of
+	// the collection by their manifests, which it can't read if they're
+	// encrypted.
+	if retention != 0 && (eval.encryptionPassphrase != nil || eval.kmsURIs != nil) {
+		return errors.Newf("%s is not supported for encrypted backups", optRetention)
+	}
+
 	ex := p.ExecCfg().InternalExecutor
 
 	unpauseOnSuccessID := jobs.InvalidScheduleID
@@ -334,7 +368,7 @@ func doCreateBackupSchedules(
 		backupNode.AppendToLatest = true
 		inc, err := makeBackupSchedule(
 			env, p.User(), scheduleLabel,
-			incRecurrence, details, unpauseOnSuccessID, updateMetricOnSuccess, backupNode)
+			incRecurrence, details, unpauseOnSuccessID, updateMetricOnSuccess, retention, backupNode)
 
 		if err != nil {
 			return err
@@ -357,7 +391,7 @@ func doCreateBackupSchedules(
 	backupNode.AppendToLatest = false
 	full, err := makeBackupSchedule(
 		env, p.User(), scheduleLabel,
-		fullRecurrence, details, unpauseOnSuccessID, updateMetricOnSuccess, backupNode)
+		fullRecurrence, details, unpauseOnSuccessID, updateMetricOnSuccess, retention, backupNode)
 	if err != nil {
 		return err
 	}
@@ -377,7 +411,7 @@ func doCreateBackupSchedules(
 	if err := full.Create(ctx, ex, p.ExtendedEvalContext().Txn); err != nil {
 		return err
 	}
-	collectScheduledBackupTelemetry(incRecurrence, firstRun, fullRecurrencePicked, details)
+	collectScheduledBackupTelemetry(incRecurrence, firstRun, fullRecurrencePicked, retention, details)
 	return emitSchedule(full, backupNode, destinations, nil /* incrementalFrom */, kmsURIs,
 		resultsCh)
 }
@@ -432,6 +466,7 @@ func makeBackupSchedule(
 	details jobspb.ScheduleDetails,
 	unpauseOnSuccess int64,
 	updateLastMetricOnSuccess bool,
+	retention time.Duration,
 	backupNode *tree.Backup,
 ) (*jobs.ScheduledJob, error) {
 	sj := jobs.NewScheduledJob(env)
@@ -442,6 +477,7 @@ func makeBackupSchedule(
 	args := &ScheduledBackupExecutionArgs{
 		UnpauseOnSuccess:        unpauseOnSuccess,
 		UpdatesLastBackupMetric: updateLastMetricOnSuccess,
+		RetentionNanos:          retention.Nanoseconds(),
 	}
 	if backupNode.AppendToLatest {
 		args.BackupType = ScheduledBackupExecutionArgs_INCREMENTAL
@@ -625,6 +661,7 @@ func collectScheduledBackupTelemetry(
 	incRecurrence *scheduleRecurrence,
 	firstRun *time.Time,
 	fullRecurrencePicked bool,
+	retention time.Duration,
 	details jobspb.ScheduleDetails,
 ) {
 	telemetry.Count("scheduled-backup.create.success")
@@ -637,6 +674,9 @@ func collectScheduledBackupTelemetry(
 	if fullRecurrencePicked {
 		telemetry.Count("scheduled-backup.full-recurrence-picked")
 	}
+	if retention != 0 {
+		telemetry.Count("scheduled-backup.retention")
+	}
 	switch details.Wait {
 	case jobspb.ScheduleDetails_WAIT:
 		telemetry.Count("scheduled-backup.wait-policy.wait")
@@ -18,6 +18,8 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +31,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -57,6 +61,7 @@ type testHelper struct {
 	cfg              *scheduledjobs.JobExecutionConfig
 	sqlDB            *sqlutils.SQLRunner
 	executeSchedules func() error
+	backupKnobs      *sql.BackupRestoreTestingKnobs
 }
 
 // newTestHelper creates and initializes appropriate state for a test,
@@ -65,8 +70,9 @@ func newTestHelper(t *testing.T) (*testHelper, func()) {
 	dir, dirCleanupFn := testutils.TempDir(t)
 
 	th := &testHelper{
-		env:   jobstest.NewJobSchedulerTestEnv(jobstest.UseSystemTables, timeutil.Now()),
-		iodir: dir,
+		env:         jobstest.NewJobSchedulerTestEnv(jobstest.UseSystemTables, timeutil.Now()),
+		iodir:       dir,
+		backupKnobs: &sql.BackupRestoreTestingKnobs{},
 	}
 
 	knobs := &jobs.TestingKnobs{
@@ -89,6 +95,7 @@ func newTestHelper(t *testing.T) (*testHelper, func()) {
 		ExternalIODir: dir,
 		Knobs: base.TestingKnobs{
 			JobsTestingKnobs: knobs,
+			DistSQL:          &execinfra.TestingKnobs{BackupRestoreTestingKnobs: th.backupKnobs},
 		},
 	}
 	s, db, _ := serverutils.StartServer(t, args)
@@ -374,6 +381,23 @@ func TestSerializesScheduledBackupExecutionArgs(t *testing.T) {
 			query:  `CREATE SCHEDULE FOR BACKUP INTO 'foo' WITH encryption_passphrase=$1 RECURRING '@hourly'`,
 			errMsg: "failed to evaluate backup encryption_passphrase",
 		},
+		{
+			name:   "invalid-retention",
+			query:  `CREATE SCHEDULE FOR BACKUP INTO 'foo' RECURRING '@hourly' WITH SCHEDULE OPTIONS retention = 'forever'`,
+			errMsg: `invalid retention "forever"`,
+		},
+		{
+			name:   "negative-retention",
+			query:  `CREATE SCHEDULE FOR BACKUP INTO 'foo' RECURRING '@hourly' WITH SCHEDULE OPTIONS retention = '-1 day'`,
+			errMsg: `retention must be positive`,
+		},
+		{
+			name: "encrypted-retention",
+			user: enterpriseUser,
+			query: `CREATE SCHEDULE FOR BACKUP INTO 'foo' WITH encryption_passphrase='secret'
+RECURRING '@hourly' WITH SCHEDULE OPTIONS retention = '1 day'`,
+			errMsg: `retention is not supported for encrypted backups`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -581,6 +605,180 @@ INSERT INTO t1 values (-1), (10), (-100);
 	}
 }
 
+func TestScheduledBackupRetention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer utilccl.TestingEnableEnterprise()()
+
+	th, cleanup := newTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `
+CREATE DATABASE db;
+CREATE TABLE db.t (a INT);
+INSERT INTO db.t VALUES (1), (2), (3);
+`)
+
+	// As in TestScheduleBackup, override AsOf backup clause to be the current
+	// time, since the schedule time is manipulated via th.env.
+	th.cfg.TestingKnobs.(*jobs.TestingKnobs).OverrideAsOfClause = func(clause *tree.AsOfClause) {
+		expr, err := tree.MakeDTimestampTZ(th.cfg.DB.Clock().PhysicalTime(), time.Microsecond)
+		require.NoError(t, err)
+		clause.Expr = expr
+	}
+
+	ctx := context.Background()
+	const collection = "nodelocal://0/retention"
+	store, err := th.server.ExecutorConfig().(sql.ExecutorConfig).DistSQLSrv.ExternalStorageFromURI(
+		ctx, collection, security.RootUserName())
+	require.NoError(t, err)
+	defer store.Close()
+	listFiles := func(pattern string) []string {
+		files, err := store.ListFiles(ctx, pattern)
+		require.NoError(t, err)
+		return files
+	}
+	listChains := func() []string {
+		var chains []string
+		for _, file := range listFiles("/*/*/*/" + backupManifestName) {
+			chains = append(chains, strings.TrimSuffix(file, "/"+backupManifestName))
+		}
+		return chains
+	}
+	// The names of the directories of the backups have a 10ms resolution, so
+	// leave some time between the backups and the cutoffs in between them.
+	cutoffBetweenBackups := func() time.Time {
+		time.Sleep(20 * time.Millisecond)
+		cutoff := timeutil.Now()
+		time.Sleep(20 * time.Millisecond)
+		return cutoff
+	}
+
+	// Build two chains in the collection: a full backup with an incremental
+	// backup appended to it, and a full backup, which LATEST points to.
+	th.sqlDB.Exec(t, `BACKUP DATABASE db INTO $1`, collection)
+	beforeInc := cutoffBetweenBackups()
+	th.sqlDB.Exec(t, `BACKUP DATABASE db INTO LATEST IN $1`, collection)
+	beforeFull := cutoffBetweenBackups()
+	th.sqlDB.Exec(t, `BACKUP DATABASE db INTO $1`, collection)
+	chains := listChains()
+	require.Len(t, chains, 2)
+	require.Len(t, listFiles(chains[0]+"/"+incBackupSubdirGlob+backupManifestName), 1)
+
+	// The first chain is not deleted while its incremental backup is within the
+	// retention window.
+	deleted, err := gcBackupCollection(ctx, store, nil /* localityStores */, 0 /* scheduleID */, beforeInc)
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	require.Equal(t, chains, listChains())
+
+	deleted, err = gcBackupCollection(ctx, store, nil /* localityStores */, 0 /* scheduleID */, beforeFull)
+	require.NoError(t, err)
+	require.Equal(t, chains[:1], deleted)
+	require.Equal(t, chains[1:], listChains())
+	require.Empty(t, listFiles(chains[0]+"/*"))
+
+	// The chain LATEST points to is never deleted.
+	deleted, err = gcBackupCollection(ctx, store, nil /* localityStores */, 0 /* scheduleID */, timeutil.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	require.Equal(t, chains[1:], listChains())
+	require.Equal(t, [][]string{{"1"}}, th.sqlDB.QueryStr(t,
+		`SELECT count(*) FROM [SHOW BACKUP $1] WHERE object_name = 't'`, collection+"/"+chains[1]))
+
+	// A schedule with a retention deletes its own expired backups each time one
+	// of its full backups succeeds, except for its most recent one. The backups
+	// it did not create are left alone.
+	schedules, err := th.createBackupSchedule(t, `
+CREATE SCHEDULE FOR BACKUP DATABASE db INTO $1 RECURRING '@hourly' FULL BACKUP ALWAYS
+WITH SCHEDULE OPTIONS ignore_existing_backups, retention = '1 day'`, collection)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	full := schedules[0]
+	args := &ScheduledBackupExecutionArgs{}
+	require.NoError(t, pbtypes.UnmarshalAny(full.ExecutionArgs().Args, args))
+	require.EqualValues(t, 24*time.Hour, args.RetentionNanos)
+
+	countJobs := func() (numJobs int) {
+		th.sqlDB.QueryRow(t, "SELECT count(*) FROM "+th.env.SystemJobsTableName()+
+			" WHERE created_by_type=$1 AND created_by_id=$2",
+			jobs.CreatedByScheduledJobs, full.ScheduleID(),
+		).Scan(&numJobs)
+		return numJobs
+	}
+	// runSchedule runs the schedule the given time after its next run, and
+	// waits for the backup job it starts to reach the given status. The
+	// function, if any, is called once the job has been created.
+	runSchedule := func(after time.Duration, status jobs.Status, started func()) {
+		loaded, err := jobs.LoadScheduledJob(
+			ctx, th.env, full.ScheduleID(), th.cfg.InternalExecutor, nil)
+		require.NoError(t, err)
+		th.env.SetTime(loaded.NextRun().Add(after))
+		numJobs := countJobs()
+		require.NoError(t, th.executeSchedules())
+		require.Equal(t, numJobs+1, countJobs())
+		if started != nil {
+			started()
+		}
+		testutils.SucceedsSoon(t, func() error {
+			th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+			var jobStatus string
+			th.sqlDB.QueryRow(t, "SELECT status FROM "+th.env.SystemJobsTableName()+
+				" WHERE created_by_type=$1 AND created_by_id=$2 ORDER BY created DESC LIMIT 1",
+				jobs.CreatedByScheduledJobs, full.ScheduleID(),
+			).Scan(&jobStatus)
+			if jobs.Status(jobStatus) != status {
+				return errors.Newf("scheduled backup is %s, expected %s", jobStatus, status)
+			}
+			return nil
+		})
+	}
+
+	// The first two backups of the schedule are within the retention window.
+	runSchedule(0, jobs.StatusSucceeded, nil)
+	chains = listChains()
+	require.Len(t, chains, 2)
+	manual, first := chains[0], chains[1]
+	runSchedule(0, jobs.StatusSucceeded, nil)
+	chains = listChains()
+	require.Len(t, chains, 3)
+	require.Equal(t, []string{manual, first}, chains[:2])
+	second := chains[2]
+
+	// A backup of the schedule which fails deletes nothing, even once the
+	// previous backups of the schedule have expired.
+	exporting := make(chan struct{})
+	var exportingOnce sync.Once
+	th.backupKnobs.RunAfterExportingSpanEntry = func(ctx context.Context) {
+		exportingOnce.Do(func() { close(exporting) })
+		<-ctx.Done()
+	}
+	runSchedule(48*time.Hour, jobs.StatusCanceled, func() {
+		th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+		<-exporting
+		th.sqlDB.Exec(t, "CANCEL JOBS SELECT id FROM "+th.env.SystemJobsTableName()+
+			" WHERE status=$1 AND created_by_type=$2 AND created_by_id=$3",
+			jobs.StatusRunning, jobs.CreatedByScheduledJobs, full.ScheduleID())
+	})
+	th.backupKnobs.RunAfterExportingSpanEntry = nil
+	require.Equal(t, []string{manual, first, second}, listChains())
+
+	// The next backup of the schedule which succeeds deletes the expired
+	// backups of the schedule.
+	runSchedule(48*time.Hour, jobs.StatusSucceeded, nil)
+	testutils.SucceedsSoon(t, func() error {
+		chains = listChains()
+		if len(chains) != 2 {
+			return errors.Newf("expected the expired backups to be deleted, found %v", chains)
+		}
+		return nil
+	})
+	require.Equal(t, manual, chains[0])
+	latest, err := ioutil.ReadFile(path.Join(th.iodir, "retention", latestFileName))
+	require.NoError(t, err)
+	require.Equal(t, strings.TrimPrefix(string(latest), "/"), chains[1])
+}
+
 func TestCreateBackupScheduleRequiresAdminRole(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -41,6 +41,10 @@ type restoreDataProcessor struct {
 
 	alloc rowenc.DatumAlloc
 	kr    *storageccl.KeyRewriter
+
+	// throttle limits the rate at which the processor ingests data. It is
+	// created when the processor starts.
+	throttle *processorThrottle
 }
 
 var _ execinfra.Processor = &restoreDataProcessor{}
@@ -48,6 +52,10 @@ var _ execinfra.RowSource = &restoreDataProcessor{}
 
 const restoreDataProcName = "restoreDataProcessor"
 
+// restoreThrottleChunkSize is the number of bytes the processor ingests
+// between waits on its throttle.
+const restoreThrottleChunkSize = 1 << 20 // 1 MiB
+
 func newRestoreDataProcessor(
 	flowCtx *execinfra.FlowCtx,
 	processorID int32,
@@ -72,6 +80,10 @@ func newRestoreDataProcessor(
 	if err := rd.Init(rd, post, restoreDataOutputTypes, flowCtx, processorID, output, nil, /* memMonitor */
 		execinfra.ProcStateOpts{
 			InputsToDrain: []execinfra.RowSource{input},
+			TrailingMetaCallback: func(context.Context) []execinfrapb.ProducerMetadata {
+				rd.close()
+				return nil
+			},
 		}); err != nil {
 		return nil, err
 	}
@@ -81,7 +93,11 @@ func newRestoreDataProcessor(
 // Start is part of the RowSource interface.
 func (rd *restoreDataProcessor) Start(ctx context.Context) context.Context {
 	rd.input.Start(ctx)
-	return rd.StartInternal(ctx, restoreDataProcName)
+	ctx = rd.StartInternal(ctx, restoreDataProcName)
+	rd.throttle = startProcessorThrottle(
+		ctx, rd.flowCtx.Cfg.Settings, rd.flowCtx.Cfg.BulkIOThrottle, rd.flowCtx.Cfg.JobRegistry, rd.spec.JobID,
+	)
+	return ctx
 }
 
 // Next is part of the RowSource interface.
@@ -154,7 +170,13 @@ func (rd *restoreDataProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.Produce
 
 // ConsumerClosed is part of the RowSource interface.
 func (rd *restoreDataProcessor) ConsumerClosed() {
-	rd.InternalClose()
+	rd.close()
+}
+
+func (rd *restoreDataProcessor) close() {
+	if rd.InternalClose() && rd.throttle != nil {
+		rd.throttle.close()
+	}
 }
 
 func init() {
@@ -205,6 +227,9 @@ func (rd *restoreDataProcessor) processRestoreSpanEntry(
 	iter := storage.MakeMultiIterator(iters)
 	defer iter.Close()
 	var keyScratch, valueScratch []byte
+	// unthrottledBytes is the size of the KVs added to the batcher since the
+	// processor last waited on its throttle.
+	var unthrottledBytes int64
 
 	for iter.SeekGE(startKeyMVCC); ; {
 		ok, err := iter.Valid()
@@ -262,6 +287,18 @@ func (rd *restoreDataProcessor) processRestoreSpanEntry(
 		if err := batcher.AddMVCCKey(ctx, key, value.RawBytes); err != nil {
 			return summary, errors.Wrapf(err, "adding to batch: %s -> %s", key, value.PrettyPrint())
 		}
+		// Waiting on the throttle for every KV would be too expensive, so the
+		// processor waits for chunks of KVs instead.
+		unthrottledBytes += int64(len(key.Key) + len(value.RawBytes))
+		if unthrottledBytes >= restoreThrottleChunkSize {
+			if err := rd.throttle.waitRestoreBytes(ctx, unthrottledBytes); err != nil {
+				return summary, err
+			}
+			unthrottledBytes = 0
+		}
+	}
+	if err := rd.throttle.waitRestoreBytes(ctx, unthrottledBytes); err != nil {
+		return summary, err
 	}
 	// Flush out the last batch.
 	if err := batcher.Flush(ctx); err != nil {
@@ -0,0 +1,328 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// pkColumnConstraint is the set of values of a primary key column that are
+// allowed by a RESTORE filter: either a set of values, or a range of values.
+type pkColumnConstraint struct {
+	// values, if hasValues is set, are the values the column is equal to.
+	values    tree.Datums
+	hasValues bool
+
+	// lower and upper, if set, bound the values of the column.
+	lower, upper                   tree.Datum
+	lowerInclusive, upperInclusive bool
+}
+
+func (c *pkColumnConstraint) addValues(evalCtx *tree.EvalContext, values tree.Datums) {
+	if !c.hasValues {
+		c.values, c.hasValues = values, true
+		return
+	}
+	var intersection tree.Datums
+	for _, v := range values {
+		for _, existing := range c.values {
+			if v.Compare(evalCtx, existing) == 0 {
+				intersection = append(intersection, v)
+				break
+			}
+		}
+	}
+	c.values = intersection
+}
+
+func (c *pkColumnConstraint) addLower(evalCtx *tree.EvalContext, d tree.Datum, inclusive bool) {
+	if c.lower != nil {
+		if cmp := d.Compare(evalCtx, c.lower); cmp < 0 || (cmp == 0 && inclusive) {
+			return
+		}
+	}
+	c.lower, c.lowerInclusive = d, inclusive
+}
+
+func (c *pkColumnConstraint) addUpper(evalCtx *tree.EvalContext, d tree.Datum, inclusive bool) {
+	if c.upper != nil {
+		if cmp := d.Compare(evalCtx, c.upper); cmp > 0 || (cmp == 0 && inclusive) {
+			return
+		}
+	}
+	c.upper, c.upperInclusive = d, inclusive
+}
+
+// inBounds returns whether d is within the bounds of the constraint.
+func (c *pkColumnConstraint) inBounds(evalCtx *tree.EvalContext, d tree.Datum) bool {
+	if c.lower != nil {
+		if cmp := d.Compare(evalCtx, c.lower); cmp < 0 || (cmp == 0 && !c.lowerInclusive) {
+			return false
+		}
+	}
+	if c.upper != nil {
+		if cmp := d.Compare(evalCtx, c.upper); cmp > 0 || (cmp == 0 && !c.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// restoreFilterSpans returns the spans of the primary index of table which
+// contain exactly the rows satisfying filter. The filter must be a conjunction
+// of comparisons of primary key columns to constants, constraining a prefix of
+// the primary key with equalities (or IN lists) and, optionally, the following
+// column with a range.
+func restoreFilterSpans(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	codec keys.SQLCodec,
+	table catalog.TableDescriptor,
+	filter string,
+) ([]roachpb.Span, error) {
+	if table.IsInterleaved() {
+		return nil, errors.Errorf("%q option cannot be used with interleaved table %q",
+			restoreOptFilter, table.GetName())
+	}
+	expr, err := parser.ParseExpr(filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %q option", restoreOptFilter)
+	}
+
+	index := table.GetPrimaryIndex()
+	pkOrdinals := make(map[descpb.ColumnID]int, index.NumColumns())
+	for i := 0; i < index.NumColumns(); i++ {
+		pkOrdinals[index.GetColumnID(i)] = i
+	}
+	constraints := make([]*pkColumnConstraint, index.NumColumns())
+	semaCtx := tree.MakeSemaContext()
+
+	for _, conjunct := range splitConjuncts(expr, nil) {
+		unsupported := errors.Errorf(
+			"unsupported %q predicate %q: expected a comparison of a primary key column of %q to a constant",
+			restoreOptFilter, tree.AsString(conjunct), table.GetName())
+
+		var op tree.ComparisonOperator
+		var between bool
+		var left tree.Expr
+		var right []tree.Expr
+		switch t := conjunct.(type) {
+		case *tree.ComparisonExpr:
+			op, left, right = t.Operator, t.Left, []tree.Expr{t.Right}
+			if _, ok := left.(*tree.UnresolvedName); !ok {
+				// Support comparisons with the column on the right hand side.
+				switch op {
+				case tree.LT:
+					op = tree.GT
+				case tree.LE:
+					op = tree.GE
+				case tree.GT:
+					op = tree.LT
+				case tree.GE:
+					op = tree.LE
+				}
+				left, right = t.Right, []tree.Expr{t.Left}
+			}
+			if op == tree.In {
+				tuple, ok := t.Right.(*tree.Tuple)
+				if !ok {
+					return nil, unsupported
+				}
+				right = tuple.Exprs
+			}
+		case *tree.RangeCond:
+			if t.Not || t.Symmetric {
+				return nil, unsupported
+			}
+			between, left, right = true, t.Left, []tree.Expr{t.From, t.To}
+		default:
+			return nil, unsupported
+		}
+
+		name, ok := left.(*tree.UnresolvedName)
+		if !ok || name.Star || name.NumParts != 1 {
+			return nil, unsupported
+		}
+		col, err := table.FindColumnWithName(tree.Name(name.Parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		ordinal, ok := pkOrdinals[col.GetID()]
+		if !ok {
+			return nil, errors.Wrapf(unsupported, "column %q is not part of the primary key", col.GetName())
+		}
+		if col.GetType().UserDefined() {
+			return nil, errors.Wrapf(unsupported, "column %q has a user-defined type", col.GetName())
+		}
+
+		values := make(tree.Datums, len(right))
+		for i, e := range right {
+			typed, err := tree.TypeCheckAndRequire(ctx, e, &semaCtx, col.GetType(), restoreOptFilter)
+			if err != nil {
+				return nil, err
+			}
+			if values[i], err = typed.Eval(evalCtx); err != nil {
+				return nil, err
+			}
+			if values[i] == tree.DNull {
+				return nil, errors.Wrapf(unsupported, "cannot compare to NULL")
+			}
+		}
+
+		if constraints[ordinal] == nil {
+			constraints[ordinal] = &pkColumnConstraint{}
+		}
+		c := constraints[ordinal]
+		switch {
+		case between:
+			c.addLower(evalCtx, values[0], true /* inclusive */)
+			c.addUpper(evalCtx, values[1], true /* inclusive */)
+		case op == tree.EQ || op == tree.In:
+			c.addValues(evalCtx, values)
+		case op == tree.LT || op == tree.LE:
+			c.addUpper(evalCtx, values[0], op == tree.LE)
+		case op == tree.GT || op == tree.GE:
+			c.addLower(evalCtx, values[0], op == tree.GE)
+		default:
+			return nil, unsupported
+		}
+	}
+
+	// Build the spans from the prefixes of the primary key that are constrained
+	// to values, extended by the range the next column is constrained to.
+	prefixes := [][]byte{rowenc.MakeIndexKeyPrefix(codec, table, index.GetID())}
+	var spans []roachpb.Span
+	constrained := 0
+	for i := 0; i < index.NumColumns() && constraints[i] != nil; i++ {
+		c := constraints[i]
+		constrained++
+		dir, err := index.GetColumnDirection(i).ToEncodingDirection()
+		if err != nil {
+			return nil, err
+		}
+		if c.hasValues {
+			var next [][]byte
+			for _, prefix := range prefixes {
+				for _, v := range c.values {
+					if !c.inBounds(evalCtx, v) {
+						continue
+					}
+					key, err := rowenc.EncodeTableKey(append([]byte(nil), prefix...), v, dir)
+					if err != nil {
+						return nil, err
+					}
+					next = append(next, key)
+				}
+			}
+			prefixes = next
+			continue
+		}
+
+		// In a descending column, the lower bound of the values is the upper
+		// bound of the keys and vice versa.
+		keyLower, keyLowerInclusive := c.lower, c.lowerInclusive
+		keyUpper, keyUpperInclusive := c.upper, c.upperInclusive
+		if dir == encoding.Descending {
+			keyLower, keyLowerInclusive, keyUpper, keyUpperInclusive =
+				keyUpper, keyUpperInclusive, keyLower, keyLowerInclusive
+		}
+		for _, prefix := range prefixes {
+			span := roachpb.Span{Key: prefix, EndKey: roachpb.Key(prefix).PrefixEnd()}
+			if keyLower != nil {
+				key, err := rowenc.EncodeTableKey(append([]byte(nil), prefix...), keyLower, dir)
+				if err != nil {
+					return nil, err
+				}
+				span.Key = key
+				if !keyLowerInclusive {
+					span.Key = span.Key.PrefixEnd()
+				}
+			}
+			if keyUpper != nil {
+				key, err := rowenc.EncodeTableKey(append([]byte(nil), prefix...), keyUpper, dir)
+				if err != nil {
+					return nil, err
+				}
+				span.EndKey = key
+				if keyUpperInclusive {
+					span.EndKey = span.EndKey.PrefixEnd()
+				}
+			}
+			if span.Key.Compare(span.EndKey) < 0 {
+				spans = append(spans, span)
+			}
+		}
+		prefixes = nil
+		break
+	}
+	for _, prefix := range prefixes {
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: roachpb.Key(prefix).PrefixEnd()})
+	}
+
+	if constrained == 0 {
+		return nil, errors.Errorf("%q option must constrain the first column of the primary key of %q",
+			restoreOptFilter, table.GetName())
+	}
+	for i := constrained; i < len(constraints); i++ {
+		if constraints[i] != nil {
+			return nil, errors.Errorf(
+				"%q option can only constrain column %q of the primary key of %q if all the columns before it are constrained to values",
+				restoreOptFilter, index.GetColumnName(i), table.GetName())
+		}
+	}
+	if len(spans) == 0 {
+		return nil, errors.Errorf("%q option %q does not match any rows", restoreOptFilter, filter)
+	}
+	spans, _ = roachpb.MergeSpans(spans)
+	return spans, nil
+}
+
+// splitConjuncts appends the conjuncts of expr to conjuncts.
+func splitConjuncts(expr tree.Expr, conjuncts []tree.Expr) []tree.Expr {
+	switch t := expr.(type) {
+	case *tree.AndExpr:
+		return splitConjuncts(t.Right, splitConjuncts(t.Left, conjuncts))
+	case *tree.ParenExpr:
+		return splitConjuncts(t.Expr, conjuncts)
+	}
+	return append(conjuncts, expr)
+}
+
+// makeSecondaryIndexesMutations turns the secondary indexes of a table whose
+// restore is filtered into mutations adding them, so that they are backfilled
+// from the restored rows once the table is published instead of being
+// restored from the backup, which would restore the entries of rows that were
+// filtered out.
+func makeSecondaryIndexesMutations(table *tabledesc.Mutable) {
+	if len(table.Indexes) == 0 {
+		return
+	}
+	mutationID := table.NextMutationID
+	table.NextMutationID++
+	for i := range table.Indexes {
+		table.Mutations = append(table.Mutations, descpb.DescriptorMutation{
+			Descriptor_: &descpb.DescriptorMutation_Index{Index: &table.Indexes[i]},
+			Direction:   descpb.DescriptorMutation_ADD,
+			State:       descpb.DescriptorMutation_DELETE_ONLY,
+			MutationID:  mutationID,
+		})
+	}
+	table.Indexes = nil
+}
@@ -630,6 +630,7 @@ func restore(
 		encryption,
 		rekeys,
 		endTime,
+		*job.ID(),
 		progCh,
 	); err != nil {
 		return emptyRowCount, err
@@ -899,12 +900,18 @@ func createImportingDescriptors(
 		switch desc := desc.(type) {
 		case catalog.TableDescriptor:
 			mut := tabledesc.NewCreatedMutable(*desc.TableDesc())
+			if len(details.FilterSpans) > 0 {
+				makeSecondaryIndexesMutations(mut)
+			}
 			tables = append(tables, mut)
 			mutableTables = append(mutableTables, mut)
 			oldTableIDs = append(oldTableIDs, mut.GetID())
 		case catalog.DatabaseDescriptor:
 			if _, ok := details.DescriptorRewrites[desc.GetID()]; ok {
 				mut := dbdesc.NewCreatedMutable(*desc.DatabaseDesc())
+				if details.NewDBName != "" {
+					mut.SetName(details.NewDBName)
+				}
 				databases = append(databases, mut)
 				mutableDatabases = append(mutableDatabases, mut)
 			}
@@ -926,6 +933,11 @@ func createImportingDescriptors(
 	// We get the spans of the restoring tables _as they appear in the backup_,
 	// that is, in the 'old' keyspace, before we reassign the table IDs.
 	spans = spansForAllRestoreTableIndexes(p.ExecCfg().Codec, tables, nil)
+	if len(details.FilterSpans) > 0 {
+		// The restore is restricted to the rows the filter matches, and the
+		// secondary indexes are backfilled from them after the restore.
+		spans = details.FilterSpans
+	}
 
 	log.Eventf(ctx, "starting restore for %d tables", len(mutableTables))
 
@@ -941,7 +953,7 @@ func createImportingDescriptors(
 	// Assign new IDs and privileges to the tables, and update all references to
 	// use the new IDs.
 	if err := RewriteTableDescs(
-		mutableTables, details.DescriptorRewrites, details.OverrideDB,
+		mutableTables, details.DescriptorRewrites, viewOverrideDB(details.OverrideDB, details.NewDBName),
 	); err != nil {
 		return nil, nil, nil, err
 	}
@@ -1179,11 +1191,15 @@ func (r *restoreResumer) Resume(ctx context.Context, execCtx interface{}) error
 	}
 	latestStats := remapRelevantStatistics(backupStats, details.DescriptorRewrites)
 
-	if len(details.TableDescs) == 0 && len(details.Tenants) == 0 && len(details.TypeDescs) == 0 {
-		// We have no tables to restore (we are restoring an empty DB).
-		// Since we have already created any new databases that we needed,
-		// we can return without importing any data.
-		log.Warning(ctx, "nothing to restore")
+	if details.SchemaOnly || (len(details.TableDescs) == 0 && len(details.Tenants) == 0 && len(details.TypeDescs) == 0) {
+		// We have no data to restore (we are restoring an empty DB, or only the
+		// descriptors of the backup). Since we have already created any new
+		// descriptors that we needed, we can return without importing any data.
+		if details.SchemaOnly {
+			log.Info(ctx, "restoring only descriptors, skipping data")
+		} else {
+			log.Warning(ctx, "nothing to restore")
+		}
 		// The database was created in the offline state and needs to be made
 		// public.
 		// TODO (lucy): Ideally we'd just create the database in the public state in
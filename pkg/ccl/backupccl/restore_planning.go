@@ -64,6 +64,9 @@ const (
 	restoreOptSkipMissingSequences      = "skip_missing_sequences"
 	restoreOptSkipMissingSequenceOwners = "skip_missing_sequence_owners"
 	restoreOptSkipMissingViews          = "skip_missing_views"
+	restoreOptNewDBName                 = "new_db_name"
+	restoreOptSchemaOnly                = "schema_only"
+	restoreOptFilter                    = "filter"
 
 	// The temporary database system tables will be restored into for full
 	// cluster backups.
@@ -976,6 +979,17 @@ func maybeRewriteSchemaID(
 	return rw.ID
 }
 
+// viewOverrideDB returns the database name that the database qualifiers in the
+// queries of restored views must be rewritten to, if any. A view is only
+// restored along with the objects it depends on, so when the single database
+// being restored is renamed, every qualifier refers to the renamed database.
+func viewOverrideDB(intoDB, newDBName string) string {
+	if newDBName != "" {
+		return newDBName
+	}
+	return intoDB
+}
+
 // RewriteTableDescs mutates tables to match the ID and privilege specified
 // in descriptorRewrites, as well as adjusting cross-table references to use the
 // new IDs. overrideDB can be specified to set database names in views.
@@ -1199,7 +1213,7 @@ func getUserDescriptorNames(
 }
 
 func resolveOptionsForRestoreJobDescription(
-	opts tree.RestoreOptions, intoDB string, kmsURIs []string,
+	opts tree.RestoreOptions, intoDB string, newDBName string, filter string, kmsURIs []string,
 ) (tree.RestoreOptions, error) {
 	if opts.IsDefault() {
 		return opts, nil
@@ -1211,6 +1225,7 @@ func resolveOptionsForRestoreJobDescription(
 		SkipMissingSequenceOwners: opts.SkipMissingSequenceOwners,
 		SkipMissingViews:          opts.SkipMissingViews,
 		Detached:                  opts.Detached,
+		SchemaOnly:                opts.SchemaOnly,
 	}
 
 	if opts.EncryptionPassphrase != nil {
@@ -1221,6 +1236,14 @@ func resolveOptionsForRestoreJobDescription(
 		newOpts.IntoDB = tree.NewDString(intoDB)
 	}
 
+	if opts.NewDBName != nil {
+		newOpts.NewDBName = tree.NewDString(newDBName)
+	}
+
+	if opts.Filter != nil {
+		newOpts.Filter = tree.NewDString(filter)
+	}
+
 	for _, uri := range kmsURIs {
 		redactedURI, err := cloudimpl.RedactKMSURI(uri)
 		if err != nil {
@@ -1238,6 +1261,8 @@ func restoreJobDescription(
 	from [][]string,
 	opts tree.RestoreOptions,
 	intoDB string,
+	newDBName string,
+	filter string,
 	kmsURIs []string,
 ) (string, error) {
 	r := &tree.Restore{
@@ -1249,7 +1274,7 @@ func restoreJobDescription(
 
 	var options tree.RestoreOptions
 	var err error
-	if options, err = resolveOptionsForRestoreJobDescription(opts, intoDB, kmsURIs); err != nil {
+	if options, err = resolveOptionsForRestoreJobDescription(opts, intoDB, newDBName, filter, kmsURIs); err != nil {
 		return "", err
 	}
 	r.Options = options
@@ -1325,6 +1350,40 @@ func restorePlanHook(
 		}
 	}
 
+	var newDBNameFn func() (string, error)
+	if restoreStmt.Options.NewDBName != nil {
+		if restoreStmt.DescriptorCoverage == tree.AllDescriptors || len(restoreStmt.Targets.Databases) != 1 {
+			return nil, nil, nil, false, errors.Errorf("%q option can only be used when restoring a single database",
+				restoreOptNewDBName)
+		}
+		newDBNameFn, err = p.TypeAsString(ctx, restoreStmt.Options.NewDBName, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	if restoreStmt.Options.SchemaOnly && restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+		return nil, nil, nil, false, errors.Errorf("%q option cannot be used in a full cluster restore",
+			restoreOptSchemaOnly)
+	}
+
+	var filterFn func() (string, error)
+	if restoreStmt.Options.Filter != nil {
+		if restoreStmt.DescriptorCoverage == tree.AllDescriptors || len(restoreStmt.Targets.Databases) != 0 ||
+			len(restoreStmt.Targets.Tables) != 1 {
+			return nil, nil, nil, false, errors.Errorf("%q option can only be used when restoring a single table",
+				restoreOptFilter)
+		}
+		if restoreStmt.Options.SchemaOnly {
+			return nil, nil, nil, false, errors.Errorf("%q option cannot be used with the %q option",
+				restoreOptFilter, restoreOptSchemaOnly)
+		}
+		filterFn, err = p.TypeAsString(ctx, restoreStmt.Options.Filter, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
 	subdirFn := func() (string, error) { return "", nil }
 	if restoreStmt.Subdir != nil {
 		subdirFn, err = p.TypeAsString(ctx, restoreStmt.Subdir, "RESTORE")
@@ -1405,7 +1464,28 @@ func restorePlanHook(
 			}
 		}
 
-		return doRestorePlan(ctx, restoreStmt, p, from, passphrase, kms, intoDB, endTime, resultsCh)
+		var newDBName string
+		if newDBNameFn != nil {
+			newDBName, err = newDBNameFn()
+			if err != nil {
+				return err
+			}
+			if newDBName == "" {
+				return errors.Errorf("%q option must not be empty", restoreOptNewDBName)
+			}
+		}
+
+		var filter string
+		if filterFn != nil {
+			filter, err = filterFn()
+			if err != nil {
+				return err
+			}
+		}
+
+		return doRestorePlan(
+			ctx, restoreStmt, p, from, passphrase, kms, intoDB, newDBName, filter, endTime, resultsCh,
+		)
 	}
 
 	if restoreStmt.Options.Detached {
@@ -1479,6 +1559,8 @@ func doRestorePlan(
 	passphrase string,
 	kms []string,
 	intoDB string,
+	newDBName string,
+	filter string,
 	endTime hlc.Timestamp,
 	resultsCh chan<- tree.Datums,
 ) error {
@@ -1605,11 +1687,47 @@ func doRestorePlan(
 			typesByID[desc.ID] = desc
 		}
 	}
+
+	// Rename the database being restored, so that the new name is the one which
+	// is checked to not exist and which the database is restored under.
+	if newDBName != "" {
+		if len(restoreDBs) != 1 {
+			return errors.Errorf("%q option can only be used when restoring a single database",
+				restoreOptNewDBName)
+		}
+		db, ok := databasesByID[restoreDBs[0].GetID()]
+		if !ok {
+			return errors.AssertionFailedf("database %q not found in backup", restoreDBs[0].GetName())
+		}
+		db.SetName(newDBName)
+		restoreDBs = []catalog.DatabaseDescriptor{db}
+	}
+
 	filteredTablesByID, err := maybeFilterMissingViews(tablesByID,
 		restoreStmt.Options.SkipMissingViews)
 	if err != nil {
 		return err
 	}
+
+	// Compute the spans the filter restricts the restore to before the table
+	// descriptor is rewritten, as the spans are in the keyspace of the backup.
+	var filterSpans []roachpb.Span
+	if filter != "" {
+		if len(filteredTablesByID) != 1 {
+			return errors.Errorf("%q option can only be used when restoring a single table", restoreOptFilter)
+		}
+		for _, table := range filteredTablesByID {
+			if !table.IsPhysicalTable() || table.IsSequence() {
+				return errors.Errorf("%q option cannot be used when restoring %q, which is not a table",
+					restoreOptFilter, table.GetName())
+			}
+			if filterSpans, err = restoreFilterSpans(
+				ctx, p.EvalContext(), p.ExecCfg().Codec, table, filter,
+			); err != nil {
+				return err
+			}
+		}
+	}
 	descriptorRewrites, err := allocateDescriptorRewrites(
 		ctx,
 		p,
@@ -1625,7 +1743,9 @@ func doRestorePlan(
 	if err != nil {
 		return err
 	}
-	description, err := restoreJobDescription(p, restoreStmt, from, restoreStmt.Options, intoDB, kms)
+	description, err := restoreJobDescription(
+		p, restoreStmt, from, restoreStmt.Options, intoDB, newDBName, filter, kms,
+	)
 	if err != nil {
 		return err
 	}
@@ -1651,7 +1771,7 @@ func doRestorePlan(
 
 	// We attempt to rewrite ID's in the collected type and table descriptors
 	// to catch errors during this process here, rather than in the job itself.
-	if err := RewriteTableDescs(tables, descriptorRewrites, intoDB); err != nil {
+	if err := RewriteTableDescs(tables, descriptorRewrites, viewOverrideDB(intoDB, newDBName)); err != nil {
 		return err
 	}
 	if err := rewriteDatabaseDescs(databases, descriptorRewrites); err != nil {
@@ -1695,6 +1815,9 @@ func doRestorePlan(
 			OverrideDB:         intoDB,
 			DescriptorCoverage: restoreStmt.DescriptorCoverage,
 			Encryption:         encryption,
+			NewDBName:          newDBName,
+			SchemaOnly:         restoreStmt.Options.SchemaOnly,
+			FilterSpans:        filterSpans,
 		},
 		Progress: jobspb.RestoreProgress{},
 	}
@@ -45,6 +45,7 @@ func distRestore(
 	encryption *jobspb.BackupEncryptionOptions,
 	rekeys []roachpb.ImportRequest_TableRekey,
 	restoreTime hlc.Timestamp,
+	jobID int64,
 	progCh chan *execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
 ) error {
 	ctx = logtags.AddTag(ctx, "restore-distsql", nil)
@@ -91,6 +92,7 @@ func distRestore(
 		Encryption:  fileEncryption,
 		Rekeys:      rekeys,
 		PKIDs:       pkIDs,
+		JobID:       jobID,
 	}
 
 	if len(splitAndScatterSpecs) == 0 {
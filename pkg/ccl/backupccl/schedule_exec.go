@@ -10,6 +10,9 @@ package backupccl
 
 import (
 	"context"
+	"io/ioutil"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
@@ -21,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -52,7 +57,7 @@ func (e *scheduledBackupExecutor) ExecuteJob(
 	sj *jobs.ScheduledJob,
 	txn *kv.Txn,
 ) error {
-	if err := e.executeBackup(ctx, cfg, sj, txn); err != nil {
+	if err := e.executeBackup(ctx, cfg, env, sj, txn); err != nil {
 		e.metrics.NumFailed.Inc(1)
 		return err
 	}
@@ -61,7 +66,11 @@ func (e *scheduledBackupExecutor) ExecuteJob(
 }
 
 func (e *scheduledBackupExecutor) executeBackup(
-	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
 ) error {
 	backupStmt, err := extractBackupStatement(sj)
 	if err != nil {
@@ -101,7 +110,8 @@ func (e *scheduledBackupExecutor) executeBackup(
 	// Invoke backup plan hook.
 	hook, cleanup := cfg.PlanHookMaker("exec-backup", txn, sj.Owner())
 	defer cleanup()
-	backupFn, err := planBackup(ctx, hook.(sql.PlanHookState), backupStmt)
+	p := hook.(sql.PlanHookState)
+	backupFn, err := planBackup(ctx, p, backupStmt)
 	if err != nil {
 		return err
 	}
@@ -222,6 +232,204 @@ func (e *scheduledBackupExecutor) backupSucceeded(
 	return nil
 }
 
+// gcScheduledBackups deletes the backups created by the schedule which are
+// older than its retention, if it has one. Backups are deleted once a full
+// backup of the schedule succeeds, since a backup chain starts with a full
+// backup.
+func gcScheduledBackups(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+) error {
+	args := &ScheduledBackupExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return errors.Wrap(err, "un-marshaling args")
+	}
+	if args.RetentionNanos == 0 || args.BackupType != ScheduledBackupExecutionArgs_FULL {
+		return nil
+	}
+	scheduleID := sj.ScheduleID()
+
+	backupStmt, err := extractBackupStatement(sj)
+	if err != nil {
+		return err
+	}
+	to := make([]string, len(backupStmt.To))
+	for i, expr := range backupStmt.To {
+		raw, ok := expr.(*tree.StrVal)
+		if !ok {
+			return errors.Errorf("unexpected %T arg in backup schedule: %v", expr, expr)
+		}
+		to[i] = raw.RawString()
+	}
+	defaultURI, urisByLocalityKV, err := getURIsByLocalityKV(to, "")
+	if err != nil {
+		return err
+	}
+
+	makeCloudStorage := execCfg.DistSQLSrv.ExternalStorageFromURI
+	store, err := makeCloudStorage(ctx, defaultURI, sj.Owner())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	localityStores := make([]cloud.ExternalStorage, 0, len(urisByLocalityKV))
+	for _, uri := range urisByLocalityKV {
+		localityStore, err := makeCloudStorage(ctx, uri, sj.Owner())
+		if err != nil {
+			return err
+		}
+		defer localityStore.Close()
+		localityStores = append(localityStores, localityStore)
+	}
+
+	cutoff := env.Now().Add(-time.Duration(args.RetentionNanos))
+	deleted, err := gcBackupCollection(ctx, store, localityStores, scheduleID, cutoff)
+	for _, chain := range deleted {
+		log.Infof(ctx, "backup schedule %d deleted backup %s older than %s",
+			scheduleID, chain, time.Duration(args.RetentionNanos))
+	}
+	return err
+}
+
+// gcBackupCollection deletes the backup chains, i.e. full backups and the
+// incremental backups appended to them, of the collection in store and of its
+// locality-specific collections in localityStores, whose full backup was
+// created by the schedule scheduleID and whose most recent backup ended before
+// cutoff. A chain is only deleted once all its backups are older than cutoff,
+// so that the backups within the retention window can always be restored. The
+// chain LATEST points to and the most recent chain of the schedule are never
+// deleted, so that a schedule whose backups keep failing doesn't delete all
+// its backups, nor is any chain whose end time cannot be determined from the
+// names chosen by BACKUP INTO. Returns the deleted chains.
+func gcBackupCollection(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	localityStores []cloud.ExternalStorage,
+	scheduleID int64,
+	cutoff time.Time,
+) ([]string, error) {
+	r, err := store.ReadFile(ctx, latestFileName)
+	if err != nil {
+		if errors.Is(err, cloudimpl.ErrFileDoesNotExist) {
+			// No backup has completed yet.
+			return nil, nil
+		}
+		return nil, err
+	}
+	latestBytes, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	latest := strings.TrimPrefix(string(latestBytes), "/")
+
+	fulls, err := store.ListFiles(ctx, "/*/*/*/"+backupManifestName)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing backups")
+	}
+	var chains []string
+	for _, full := range fulls {
+		chain := strings.TrimSuffix(strings.TrimPrefix(full, "/"), "/"+backupManifestName)
+		manifest, err := readBackupManifest(ctx, store, chain+"/"+backupManifestName, nil /* encryption */)
+		if err != nil {
+			// The chain wasn't created by the schedule, whose backups aren't
+			// encrypted, e.g. it's an encrypted backup created by a user.
+			log.VEventf(ctx, 2, "skipping backup %s whose manifest can't be read: %v", chain, err)
+			continue
+		}
+		if manifest.ScheduleID == scheduleID {
+			chains = append(chains, chain)
+		}
+	}
+	// The names of the directories of the chains are derived from the end times
+	// of their full backups, so the most recent chain sorts last.
+	sort.Strings(chains)
+	if len(chains) > 0 {
+		chains = chains[:len(chains)-1]
+	}
+
+	var deleted []string
+	for _, chain := range chains {
+		if chain == latest {
+			continue
+		}
+		chainEnd, ok, err := backupChainEndTime(ctx, store, chain)
+		if err != nil {
+			return deleted, err
+		}
+		if !ok || !chainEnd.Before(cutoff) {
+			continue
+		}
+
+		// Delete the files of the chain from the locality-specific collections
+		// first, so that the chain remains listed in the default collection until
+		// it is entirely deleted.
+		for _, localityStore := range localityStores {
+			if err := deleteBackupChain(ctx, localityStore, chain); err != nil {
+				return deleted, err
+			}
+		}
+		if err := deleteBackupChain(ctx, store, chain); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, chain)
+	}
+	return deleted, nil
+}
+
+// backupChainEndTime returns the end time of the most recent backup of the
+// backup chain in the directory chain of store, as determined from the names of
+// the directories of its backups. Returns false if the name of one of them was
+// not chosen by BACKUP INTO.
+func backupChainEndTime(
+	ctx context.Context, store cloud.ExternalStorage, chain string,
+) (time.Time, bool, error) {
+	chainEnd, err := time.Parse(dateBasedIntoFolderName, "/"+chain)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	incs, err := store.ListFiles(ctx, chain+"/"+incBackupSubdirGlob+backupManifestName)
+	if err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "listing incremental backups of %s", chain)
+	}
+	for _, inc := range incs {
+		inc = strings.TrimPrefix(strings.TrimPrefix(inc, "/"), chain)
+		incEnd, err := time.Parse(dateBasedIncFolderName, strings.TrimSuffix(inc, "/"+backupManifestName))
+		if err != nil {
+			return time.Time{}, false, nil
+		}
+		if incEnd.After(chainEnd) {
+			chainEnd = incEnd
+		}
+	}
+	return chainEnd, true, nil
+}
+
+// deleteBackupChain deletes the files of the backup chain in the directory
+// chain of store. The files of the incremental backups are deleted before the
+// directories containing them, for storage which has directories, and the
+// manifest of the full backup is deleted last, so that a chain which is only
+// partially deleted is still found by the next garbage collection.
+func deleteBackupChain(ctx context.Context, store cloud.ExternalStorage, chain string) error {
+	for _, pattern := range []string{"/*/*/*", "/*/*", "/*"} {
+		files, err := store.ListFiles(ctx, chain+pattern)
+		if err != nil {
+			return errors.Wrapf(err, "listing files of %s", chain)
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, chain+"/"+backupManifestName) {
+				continue
+			}
+			if err := store.Delete(ctx, file); err != nil {
+				return errors.Wrapf(err, "deleting %s", file)
+			}
+		}
+	}
+	return store.Delete(ctx, chain+"/"+backupManifestName)
+}
+
 // Metrics implements ScheduledJobExecutor interface
 func (e *scheduledBackupExecutor) Metrics() metric.Struct {
 	return &e.metrics
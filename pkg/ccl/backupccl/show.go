@@ -9,7 +9,10 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
 	"net/url"
 	"path"
 	"strings"
@@ -31,9 +34,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -91,9 +96,11 @@ func showBackupPlanHook(
 	}
 
 	expected := map[string]sql.KVStringOptValidate{
-		backupOptEncPassphrase:  sql.KVStringOptRequireValue,
-		backupOptEncKMS:         sql.KVStringOptRequireValue,
-		backupOptWithPrivileges: sql.KVStringOptRequireNoValue,
+		backupOptEncPassphrase:   sql.KVStringOptRequireValue,
+		backupOptEncKMS:          sql.KVStringOptRequireValue,
+		backupOptWithPrivileges:  sql.KVStringOptRequireNoValue,
+		backupOptCheckFiles:      sql.KVStringOptRequireNoValue,
+		backupOptVerifyChecksums: sql.KVStringOptRequireNoValue,
 	}
 	optsFn, err := p.TypeAsStringOpts(ctx, backup.Options, expected)
 	if err != nil {
@@ -104,12 +111,21 @@ func showBackupPlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	_, checkFiles := opts[backupOptCheckFiles]
+	_, verifyChecksums := opts[backupOptVerifyChecksums]
+	if verifyChecksums && backup.Details != tree.BackupValidateDetails {
+		return nil, nil, nil, false, errors.Newf(
+			"the %s option is only supported by VALIDATE BACKUP", backupOptVerifyChecksums)
+	}
+
 	var shower backupShower
 	switch backup.Details {
 	case tree.BackupRangeDetails:
 		shower = backupShowerRanges
 	case tree.BackupFileDetails:
 		shower = backupShowerFiles
+	case tree.BackupValidateDetails:
+		shower = backupShowerValidate
 	default:
 		shower = backupShowerDefault(ctx, p, backup.ShouldIncludeSchemas, opts)
 	}
@@ -190,6 +206,9 @@ func showBackupPlanHook(
 		if err != nil {
 			return err
 		}
+		// manifestDirs are the directories of the store the files of each
+		// manifest are in.
+		manifestDirs := make([]string, len(incPaths)+1)
 
 		for i := range incPaths {
 			m, err := readBackupManifest(ctx, store, incPaths[i], encryption)
@@ -199,6 +218,7 @@ func showBackupPlanHook(
 			// Blank the stats to prevent memory blowup.
 			m.DeprecatedStatistics = nil
 			manifests[i+1] = m
+			manifestDirs[i+1] = path.Dir(incPaths[i])
 		}
 
 		// If we are restoring a backup with old-style foreign keys, skip over the
@@ -209,9 +229,33 @@ func showBackupPlanHook(
 			return err
 		}
 
-		datums, err := shower.fn(manifests)
-		if err != nil {
-			return err
+		var datums []tree.Datums
+		validate := backup.Details == tree.BackupValidateDetails
+		if validate || checkFiles {
+			var encryptionKey []byte
+			if verifyChecksums && encryption != nil {
+				encryptionKey, err = getEncryptionKey(ctx, encryption, p.ExecCfg().Settings,
+					store.ExternalIOConf())
+				if err != nil {
+					return err
+				}
+			}
+			checked, err := checkBackupFiles(
+				ctx, store, manifests, manifestDirs, encryptionKey, verifyChecksums)
+			if err != nil {
+				return err
+			}
+			if validate {
+				datums = checkedBackupFileRows(checked)
+			} else if err := checkedBackupFilesError(checked); err != nil {
+				return err
+			}
+		}
+		if !validate {
+			datums, err = shower.fn(manifests)
+			if err != nil {
+				return err
+			}
 		}
 		for _, row := range datums {
 			select {
@@ -504,6 +548,201 @@ var backupShowerFiles = backupShower{
 	},
 }
 
+// The statuses of a file checked by VALIDATE BACKUP or SHOW BACKUP WITH
+// check_files.
+const (
+	backupFileOK      = "ok"
+	backupFileMissing = "missing"
+	backupFileEmpty   = "empty"
+	backupFileCorrupt = "corrupt"
+)
+
+// backupShowerValidate only provides the header of VALIDATE BACKUP, whose rows
+// are produced by checking the files of the backup in its store.
+var backupShowerValidate = backupShower{
+	header: colinfo.ResultColumns{
+		{Name: "path", Typ: types.String},
+		{Name: "size_bytes", Typ: types.Int},
+		{Name: "status", Typ: types.String},
+		{Name: "detail", Typ: types.String},
+	},
+}
+
+// checkedBackupFile is the result of checking a file referenced by a backup
+// manifest.
+type checkedBackupFile struct {
+	path string
+	// sizeBytes is the size of the file in the store, or -1 if it is unknown.
+	sizeBytes int64
+	status    string
+	detail    string
+}
+
+// checkBackupFiles checks that every file referenced by the manifests exists
+// in the store and is not empty. If verifyChecksums is set, the files are also
+// read back, decrypted with encryptionKey if it is set, and their checksums
+// and contents are compared to the ones recorded in the manifests.
+//
+// The files of manifests[i] are in the manifestDirs[i] directory of the store.
+// The files of locality-aware backups are stored in the locations of their
+// localities, which aren't recorded in the manifests, so they can't be checked.
+func checkBackupFiles(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	manifests []BackupManifest,
+	manifestDirs []string,
+	encryptionKey []byte,
+	verifyChecksums bool,
+) ([]checkedBackupFile, error) {
+	var checked []checkedBackupFile
+	for i := range manifests {
+		for _, file := range manifests[i].Files {
+			if file.LocalityKV != "" {
+				return nil, unimplemented.Newf("validate locality-aware backup",
+					"checking the files of a locality-aware backup is not supported: %s is stored in the location of locality %s",
+					path.Join(manifestDirs[i], file.Path), file.LocalityKV)
+			}
+			res, err := checkBackupFile(
+				ctx, store, path.Join(manifestDirs[i], file.Path), file, encryptionKey, verifyChecksums)
+			if err != nil {
+				return nil, err
+			}
+			checked = append(checked, res)
+		}
+	}
+	return checked, nil
+}
+
+// checkBackupFile checks a single file of a backup. Problems with the file are
+// reported in the returned result, while the returned error is only set if
+// the file could not be checked.
+func checkBackupFile(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	filePath string,
+	file BackupManifest_File,
+	encryptionKey []byte,
+	verifyChecksums bool,
+) (checkedBackupFile, error) {
+	res := checkedBackupFile{path: filePath, sizeBytes: -1}
+	r, err := store.ReadFile(ctx, filePath)
+	if err != nil {
+		if errors.Is(err, cloudimpl.ErrFileDoesNotExist) {
+			res.status = backupFileMissing
+			return res, nil
+		}
+		return res, errors.Wrapf(err, "reading %s", filePath)
+	}
+	defer r.Close()
+	if res.sizeBytes, err = store.Size(ctx, filePath); err != nil {
+		return res, errors.Wrapf(err, "reading the size of %s", filePath)
+	}
+	if res.sizeBytes == 0 {
+		res.status = backupFileEmpty
+		return res, nil
+	}
+
+	res.status = backupFileOK
+	if !verifyChecksums {
+		return res, nil
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return res, errors.Wrapf(err, "reading %s", filePath)
+	}
+	if int64(len(data)) != res.sizeBytes {
+		res.status = backupFileCorrupt
+		res.detail = fmt.Sprintf("read %d bytes, expected %d", len(data), res.sizeBytes)
+		return res, nil
+	}
+	if detail := verifyBackupFileContents(data, file, encryptionKey); detail != "" {
+		res.status = backupFileCorrupt
+		res.detail = detail
+	}
+	return res, nil
+}
+
+// verifyBackupFileContents recomputes the checksum of the SST of a backup
+// file and iterates over its KVs, returning a description of the first
+// mismatch with the manifest, if any.
+func verifyBackupFileContents(
+	data []byte, file BackupManifest_File, encryptionKey []byte,
+) string {
+	if encryptionKey != nil {
+		var err error
+		if data, err = storageccl.DecryptFile(data, encryptionKey); err != nil {
+			return fmt.Sprintf("decrypting: %v", err)
+		}
+	}
+	// The checksum is omitted by backups which don't compute one.
+	if len(file.Sha512) > 0 {
+		checksum, err := storageccl.SHA512ChecksumData(data)
+		if err != nil {
+			return fmt.Sprintf("computing checksum: %v", err)
+		}
+		if !bytes.Equal(checksum, file.Sha512) {
+			return "checksum mismatch"
+		}
+	}
+
+	iter, err := storage.NewMemSSTIterator(data, true /* verify */)
+	if err != nil {
+		return fmt.Sprintf("opening SST: %v", err)
+	}
+	defer iter.Close()
+	var dataSize int64
+	for iter.SeekGE(storage.MVCCKey{Key: keys.MinKey}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return fmt.Sprintf("reading SST: %v", err)
+		} else if !ok {
+			break
+		}
+		key := iter.UnsafeKey()
+		if !file.Span.ContainsKey(key.Key) {
+			return fmt.Sprintf("key %s is outside of the file span %s", key.Key, file.Span)
+		}
+		dataSize += int64(len(key.Key) + len(iter.UnsafeValue()))
+	}
+	if dataSize != file.EntryCounts.DataSize {
+		return fmt.Sprintf("contains %d bytes of KVs, expected %d", dataSize, file.EntryCounts.DataSize)
+	}
+	return ""
+}
+
+func checkedBackupFileRows(checked []checkedBackupFile) []tree.Datums {
+	rows := make([]tree.Datums, 0, len(checked))
+	for _, res := range checked {
+		size := tree.DNull
+		if res.sizeBytes >= 0 {
+			size = tree.NewDInt(tree.DInt(res.sizeBytes))
+		}
+		rows = append(rows, tree.Datums{
+			tree.NewDString(res.path),
+			size,
+			tree.NewDString(res.status),
+			nullIfEmpty(res.detail),
+		})
+	}
+	return rows
+}
+
+// checkedBackupFilesError returns an error listing the files of a backup that
+// are missing, empty or corrupt, if any.
+func checkedBackupFilesError(checked []checkedBackupFile) error {
+	var problems []string
+	for _, res := range checked {
+		switch res.status {
+		case backupFileMissing, backupFileEmpty, backupFileCorrupt:
+			problems = append(problems, fmt.Sprintf("%s (%s)", res.path, res.status))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return pgerror.Newf(pgcode.DataCorrupted, "backup is missing or has invalid files: %s",
+		strings.Join(problems, ", "))
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupsInCollectionPlanHook(
 	ctx context.Context, backup *tree.ShowBackup, p sql.PlanHookState,
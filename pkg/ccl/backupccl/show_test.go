@@ -12,7 +12,10 @@ import (
 	"context"
 	gosql "database/sql"
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -522,3 +525,64 @@ func TestShowBackupPrivileges(t *testing.T) {
 	_, err = testuser.Exec(`SHOW BACKUP $1`, full)
 	require.NoError(t, err)
 }
+
+func TestValidateBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 11
+	_, _, sqlDB, tempDir, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const collection = LocalFoo + "/validate"
+	sqlDB.Exec(t, `BACKUP data.bank INTO $1`, collection)
+	sqlDB.Exec(t, `INSERT INTO data.bank VALUES (1000, 1, 'inc')`)
+	sqlDB.Exec(t, `BACKUP data.bank INTO LATEST IN $1`, collection)
+	subdir := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[0][0]
+
+	// Every file of the full and incremental backups is reported, and they're
+	// all intact.
+	require.Equal(t, [][]string{{"2", "ok"}},
+		sqlDB.QueryStr(t, `SELECT count(*), status FROM [VALIDATE BACKUP $1 IN $2] GROUP BY status`,
+			subdir, collection))
+	require.Equal(t, [][]string{{"2", "ok"}},
+		sqlDB.QueryStr(t, `SELECT count(*), status FROM [VALIDATE BACKUP $1 IN $2 WITH verify_checksums]
+GROUP BY status`, subdir, collection))
+	sqlDB.Exec(t, `SHOW BACKUP $1 IN $2 WITH check_files`, subdir, collection)
+	sqlDB.ExpectErr(t, "only supported by VALIDATE BACKUP",
+		`SHOW BACKUP $1 IN $2 WITH verify_checksums`, subdir, collection)
+
+	backupDir := filepath.Join(tempDir, "foo", "validate", subdir)
+	var sstPath string
+	require.NoError(t, filepath.Walk(backupDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if sstPath == "" && strings.HasSuffix(path, ".sst") {
+			sstPath = path
+		}
+		return nil
+	}))
+	require.NotEmpty(t, sstPath)
+	relPath, err := filepath.Rel(backupDir, sstPath)
+	require.NoError(t, err)
+
+	// Flipping a bit in an SST is only noticed when its contents are verified.
+	data, err := ioutil.ReadFile(sstPath)
+	require.NoError(t, err)
+	data[len(data)/2] ^= 1
+	require.NoError(t, ioutil.WriteFile(sstPath, data, 0644 /* perm */))
+	require.Equal(t, [][]string{{"ok"}},
+		sqlDB.QueryStr(t, `SELECT status FROM [VALIDATE BACKUP $1 IN $2] WHERE path = $3`,
+			subdir, collection, relPath))
+	require.Equal(t, [][]string{{"corrupt"}},
+		sqlDB.QueryStr(t, `SELECT status FROM [VALIDATE BACKUP $1 IN $2 WITH verify_checksums] WHERE path = $3`,
+			subdir, collection, relPath))
+
+	require.NoError(t, os.Remove(sstPath))
+	require.Equal(t, [][]string{{"missing", "NULL"}},
+		sqlDB.QueryStr(t, `SELECT status, size_bytes FROM [VALIDATE BACKUP $1 IN $2] WHERE path = $3`,
+			subdir, collection, relPath))
+	sqlDB.ExpectErr(t, fmt.Sprintf(`backup is missing or has invalid files: %s \(missing\)`, relPath),
+		`SHOW BACKUP $1 IN $2 WITH check_files`, subdir, collection)
+}
@@ -0,0 +1,272 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/bulk"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var (
+	backupMaxBytesPerSecond = settings.RegisterByteSizeSetting(
+		"bulkio.backup.max_bytes_per_second",
+		"limits the rate at which each node writes backups to external storage (0 = unlimited)",
+		0,
+		settings.NonNegativeInt,
+	)
+	backupMaxConcurrentExportRequests = settings.RegisterIntSetting(
+		"bulkio.backup.max_concurrent_export_requests",
+		"limits the number of export requests each node sends concurrently for backups (0 = unlimited)",
+		0,
+		settings.NonNegativeInt,
+	)
+	restoreMaxBytesPerSecond = settings.RegisterByteSizeSetting(
+		"bulkio.restore.max_bytes_per_second",
+		"limits the rate at which each node ingests the data restored from backups (0 = unlimited)",
+		0,
+		settings.NonNegativeInt,
+	)
+	jobLimitsPollInterval = settings.RegisterDurationSetting(
+		"bulkio.backup_restore.job_limits_poll_interval",
+		"the interval at which backup and restore processors poll their job for changes to its limits",
+		10*time.Second,
+		settings.NonNegativeDuration,
+	)
+)
+
+// processorThrottle throttles the work of a backup or restore processor to
+// both the limits of its node and the limits of its job on its node. The
+// limits of the job are recorded in its progress and polled by the processor,
+// so that they can be adjusted while the job is running.
+type processorThrottle struct {
+	settings *cluster.Settings
+	jobID    int64
+	node     *bulk.IOThrottle
+	job      *bulk.JobIOThrottle
+
+	stopPolling func()
+}
+
+// startProcessorThrottle returns a throttle for a processor of the job running
+// on the node with the given IO throttle, which polls the job for its limits
+// until it is closed.
+func startProcessorThrottle(
+	ctx context.Context,
+	st *cluster.Settings,
+	node *bulk.IOThrottle,
+	registry *jobs.Registry,
+	jobID int64,
+) *processorThrottle {
+	if node == nil {
+		// Processors run with a partial server config, such as in tests, don't
+		// share the limiters of their node.
+		node = bulk.NewIOThrottle()
+	}
+	t := &processorThrottle{
+		settings:    st,
+		jobID:       jobID,
+		node:        node,
+		job:         node.AcquireJob(jobID),
+		stopPolling: func() {},
+	}
+	if registry == nil || jobID == 0 {
+		return t
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t.pollJobLimits(ctx, registry, jobID)
+	}()
+	t.stopPolling = func() {
+		cancel()
+		wg.Wait()
+	}
+	return t
+}
+
+func (t *processorThrottle) pollJobLimits(
+	ctx context.Context, registry *jobs.Registry, jobID int64,
+) {
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for {
+		job, err := registry.LoadJob(ctx, jobID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warningf(ctx, "failed to load the limits of job %d: %+v", jobID, err)
+		} else {
+			switch d := job.Progress().Details.(type) {
+			case *jobspb.Progress_Backup:
+				t.job.Bytes.SetLimit(d.Backup.MaxBytesPerSecond)
+				t.job.ExportRequests.SetLimit(d.Backup.MaxConcurrentExportRequests)
+			case *jobspb.Progress_Restore:
+				t.job.Bytes.SetLimit(d.Restore.MaxBytesPerSecond)
+			}
+		}
+
+		timer.Reset(jobLimitsPollInterval.Get(&t.settings.SV))
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Read = true
+		}
+	}
+}
+
+// acquireExportRequest blocks until an export request can be sent, and returns
+// a function to be called once the request is done.
+func (t *processorThrottle) acquireExportRequest(ctx context.Context) (release func(), _ error) {
+	t.node.ExportRequests.SetLimit(backupMaxConcurrentExportRequests.Get(&t.settings.SV))
+	releaseNode, err := t.node.ExportRequests.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releaseJob, err := t.job.ExportRequests.Acquire(ctx)
+	if err != nil {
+		releaseNode()
+		return nil, err
+	}
+	return func() {
+		releaseJob()
+		releaseNode()
+	}, nil
+}
+
+// backupBytesLimited returns whether the bytes written by a backup are limited,
+// either on the node or for the job.
+func (t *processorThrottle) backupBytesLimited() bool {
+	t.node.BackupBytes.SetLimit(backupMaxBytesPerSecond.Get(&t.settings.SV))
+	return t.node.BackupBytes.Limited() || t.job.Bytes.Limited()
+}
+
+// acquireBackupBytes blocks until n more bytes can be written to external
+// storage by a backup, before they're read. The returned function is to be
+// called with the number of bytes actually written once they're known, and
+// waits for any bytes written beyond n.
+func (t *processorThrottle) acquireBackupBytes(
+	ctx context.Context, n int64,
+) (settle func(ctx context.Context, written int64) error, _ error) {
+	t.node.BackupBytes.SetLimit(backupMaxBytesPerSecond.Get(&t.settings.SV))
+	settleNode, err := t.node.BackupBytes.Acquire(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	settleJob, err := t.job.Bytes.Acquire(ctx, n)
+	if err != nil {
+		_ = settleNode(ctx, 0)
+		return nil, err
+	}
+	return func(ctx context.Context, written int64) error {
+		if err := settleNode(ctx, written); err != nil {
+			return err
+		}
+		return settleJob(ctx, written)
+	}, nil
+}
+
+// waitRestoreBytes blocks until n more bytes can be ingested by a restore.
+func (t *processorThrottle) waitRestoreBytes(ctx context.Context, n int64) error {
+	t.node.RestoreBytes.SetLimit(restoreMaxBytesPerSecond.Get(&t.settings.SV))
+	if err := t.node.RestoreBytes.Wait(ctx, n); err != nil {
+		return err
+	}
+	return t.job.Bytes.Wait(ctx, n)
+}
+
+// close stops polling the job for its limits and releases the limiters of the
+// job.
+func (t *processorThrottle) close() {
+	t.stopPolling()
+	t.node.ReleaseJob(t.jobID)
+}
+
+// setJobIOLimits records the limits of a BACKUP or RESTORE job in its
+// progress, from which they are polled by the processors of the job.
+func setJobIOLimits(
+	evalCtx *tree.EvalContext,
+	txn *kv.Txn,
+	jobID, maxBytesPerSecond, maxConcurrentExportRequests int64,
+) error {
+	p, ok := evalCtx.Planner.(sql.PlanHookState)
+	if !ok {
+		return errors.AssertionFailedf("unexpected planner type %T", evalCtx.Planner)
+	}
+	ctx := evalCtx.Context
+	if err := p.RequireAdminRole(ctx, "limit the IO of a job"); err != nil {
+		return err
+	}
+	if maxBytesPerSecond < 0 || maxConcurrentExportRequests < 0 {
+		return pgerror.New(pgcode.InvalidParameterValue, "job IO limits must be non-negative")
+	}
+	return p.ExecCfg().JobRegistry.UpdateJobWithTxn(ctx, jobID, txn,
+		func(txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			if md.Status.Terminal() {
+				return errors.Newf("job %d is %s", jobID, md.Status)
+			}
+			switch d := md.Progress.Details.(type) {
+			case *jobspb.Progress_Backup:
+				d.Backup.MaxBytesPerSecond = maxBytesPerSecond
+				d.Backup.MaxConcurrentExportRequests = maxConcurrentExportRequests
+			case *jobspb.Progress_Restore:
+				if maxConcurrentExportRequests != 0 {
+					return errors.Newf("job %d is a restore job, which does not send export requests", jobID)
+				}
+				d.Restore.MaxBytesPerSecond = maxBytesPerSecond
+			default:
+				return errors.Newf("job %d is not a backup or restore job", jobID)
+			}
+			md.Progress.RunningStatus = string(jobIOLimitsStatus(maxBytesPerSecond, maxConcurrentExportRequests))
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
+}
+
+// jobIOLimitsStatus returns the running status of a job describing its limits.
+func jobIOLimitsStatus(maxBytesPerSecond, maxConcurrentExportRequests int64) jobs.RunningStatus {
+	var limits []string
+	if maxBytesPerSecond > 0 {
+		limits = append(limits, fmt.Sprintf("%s/s", humanizeutil.IBytes(maxBytesPerSecond)))
+	}
+	if maxConcurrentExportRequests > 0 {
+		limits = append(limits, fmt.Sprintf("%d concurrent export requests", maxConcurrentExportRequests))
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	return jobs.RunningStatus(fmt.Sprintf("throttled to %s per node", strings.Join(limits, " and ")))
+}
+
+func init() {
+	builtins.SetJobIOLimits = setJobIOLimits
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/bulk"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestoreIOLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// Block export and AddSSTable responses so that the jobs can be paused
+	// while they are running; see jobutils.RunJob.
+	var allowResponse chan struct{}
+	params := base.TestClusterArgs{}
+	params.ServerArgs.Knobs.Store = &kvserver.StoreTestingKnobs{
+		TestingResponseFilter: jobutils.BulkOpResponseFilter(&allowResponse),
+	}
+
+	const numAccounts = 1000
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetupWithParams(t, singleNode, numAccounts, InitManualReplication, params)
+	defer cleanupFn()
+
+	// The cluster-wide limits apply to all the jobs on a node.
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.max_bytes_per_second = '10MiB'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.max_concurrent_export_requests = 2`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.restore.max_bytes_per_second = '10MiB'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup_restore.job_limits_poll_interval = '10ms'`)
+
+	runningStatus := func(jobID int64) string {
+		var status string
+		sqlDB.QueryRow(t, `SELECT IFNULL(running_status, '') FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&status)
+		return status
+	}
+
+	backupID, err := jobutils.RunJob(t, sqlDB, &allowResponse, []string{"PAUSE"},
+		`BACKUP DATABASE data TO $1`, LocalFoo)
+	if !testutils.IsError(err, "job paused") {
+		t.Fatalf("expected 'job paused' error, but got %+v", err)
+	}
+	sqlDB.Exec(t, `SELECT crdb_internal.set_job_io_limits($1, 1 << 20, 1)`, backupID)
+	progress := jobutils.GetJobProgress(t, sqlDB, backupID).GetBackup()
+	require.Equal(t, int64(1<<20), progress.MaxBytesPerSecond)
+	require.Equal(t, int64(1), progress.MaxConcurrentExportRequests)
+	require.Equal(t, "throttled to 1.0 MiB/s and 1 concurrent export requests per node", runningStatus(backupID))
+	sqlDB.Exec(t, `RESUME JOB $1`, backupID)
+	jobutils.WaitForJob(t, sqlDB, backupID)
+	sqlDB.ExpectErr(t, "is succeeded", `SELECT crdb_internal.set_job_io_limits($1, 0)`, backupID)
+
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+	restoreID, err := jobutils.RunJob(t, sqlDB, &allowResponse, []string{"PAUSE"},
+		`RESTORE data.bank FROM $1 WITH into_db = 'restored'`, LocalFoo)
+	if !testutils.IsError(err, "job paused") {
+		t.Fatalf("expected 'job paused' error, but got %+v", err)
+	}
+	sqlDB.ExpectErr(t, "does not send export requests",
+		`SELECT crdb_internal.set_job_io_limits($1, 1 << 20, 1)`, restoreID)
+	sqlDB.Exec(t, `SELECT crdb_internal.set_job_io_limits($1, 1 << 20)`, restoreID)
+	require.Equal(t, int64(1<<20), jobutils.GetJobProgress(t, sqlDB, restoreID).GetRestore().MaxBytesPerSecond)
+	require.Equal(t, "throttled to 1.0 MiB/s per node", runningStatus(restoreID))
+
+	// Removing the limits of the job clears its running status.
+	sqlDB.Exec(t, `SELECT crdb_internal.set_job_io_limits($1, 0)`, restoreID)
+	require.Equal(t, "", runningStatus(restoreID))
+	sqlDB.Exec(t, `RESUME JOB $1`, restoreID)
+	jobutils.WaitForJob(t, sqlDB, restoreID)
+
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM restored.bank`, [][]string{{"1000"}})
+	sqlDB.ExpectErr(t, "must be non-negative", `SELECT crdb_internal.set_job_io_limits($1, -1)`, restoreID)
+}
+
+func TestProcessorThrottlesAreShared(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	node := bulk.NewIOThrottle()
+
+	// The processors of a job running on a node share the limiters of the job,
+	// while the processors of other jobs only share the limiters of the node.
+	t1 := startProcessorThrottle(ctx, st, node, nil /* registry */, 1 /* jobID */)
+	t2 := startProcessorThrottle(ctx, st, node, nil /* registry */, 1 /* jobID */)
+	t3 := startProcessorThrottle(ctx, st, node, nil /* registry */, 2 /* jobID */)
+	require.True(t, t1.node == node && t2.node == node && t3.node == node)
+	require.True(t, t1.job == t2.job)
+	require.False(t, t1.job == t3.job)
+
+	// A processor run without the throttle of its node shares nothing.
+	t4 := startProcessorThrottle(ctx, st, nil /* node */, nil /* registry */, 1 /* jobID */)
+	require.False(t, t4.node == node)
+	require.False(t, t4.job == t1.job)
+
+	for _, pt := range []*processorThrottle{t1, t2, t3, t4} {
+		pt.close()
+	}
+}
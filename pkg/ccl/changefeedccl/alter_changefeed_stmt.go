@@ -0,0 +1,487 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"net/url"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// alterChangefeedOptSink is the pseudo-option used by ALTER CHANGEFEED to
+// change the sink of a changefeed.
+const alterChangefeedOptSink = `sink`
+
+// alterChangefeedImmutableOpts are the options which can't be changed by ALTER
+// CHANGEFEED, because they only matter when the changefeed starts or because
+// they change what was already emitted.
+var alterChangefeedImmutableOpts = map[string]struct{}{
+	changefeedbase.OptCursor:          {},
+	changefeedbase.OptInitialScan:     {},
+	changefeedbase.OptNoInitialScan:   {},
+	changefeedbase.OptInitialScanOnly: {},
+	changefeedbase.OptExactlyOnce:     {},
+	changefeedbase.OptFullTableName:   {},
+	changefeedbase.OptTopicTemplate:   {},
+}
+
+func init() {
+	sql.AddPlanHook(alterChangefeedPlanHook)
+}
+
+// alterChangefeedPlanHook implements sql.PlanHookFn.
+func alterChangefeedPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	alterChangefeedStmt, ok := stmt.(*tree.AlterChangefeed)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureChangefeedEnabled,
+		"CHANGEFEED",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	typedJobID, err := tree.TypeCheckAndRequire(
+		ctx, alterChangefeedStmt.Jobs, p.SemaCtx(), types.Int, `ALTER CHANGEFEED`)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	optsValidate := make(map[string]sql.KVStringOptValidate, len(changefeedbase.ChangefeedOptionExpectValues)+1)
+	for k, v := range changefeedbase.ChangefeedOptionExpectValues {
+		optsValidate[k] = v
+	}
+	optsValidate[alterChangefeedOptSink] = sql.KVStringOptRequireValue
+
+	var setOptsFns []func() (map[string]string, error)
+	for _, cmd := range alterChangefeedStmt.Cmds {
+		if setCmd, ok := cmd.(*tree.AlterChangefeedSetOptions); ok {
+			optsFn, err := p.TypeAsStringOpts(ctx, setCmd.Options, optsValidate)
+			if err != nil {
+				return nil, nil, nil, false, err
+			}
+			setOptsFns = append(setOptsFns, optsFn)
+		}
+	}
+
+	header := colinfo.ResultColumns{
+		{Name: "job_id", Typ: types.Int},
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		ok, err := p.HasRoleOption(ctx, roleoption.CONTROLCHANGEFEED)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return pgerror.New(pgcode.InsufficientPrivilege, "permission denied to alter changefeed")
+		}
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "CHANGEFEED",
+		); err != nil {
+			return err
+		}
+
+		jobIDDatum, err := typedJobID.Eval(&p.ExtendedEvalContext().EvalContext)
+		if err != nil {
+			return err
+		}
+		if jobIDDatum == tree.DNull {
+			return errors.New(`ALTER CHANGEFEED requires a job ID`)
+		}
+		jobID := int64(tree.MustBeDInt(jobIDDatum))
+
+		var setOpts []map[string]string
+		for _, optsFn := range setOptsFns {
+			opts, err := optsFn()
+			if err != nil {
+				return err
+			}
+			setOpts = append(setOpts, opts)
+		}
+
+		job, err := p.ExecCfg().JobRegistry.LoadJobWithTxn(ctx, jobID, p.Txn())
+		if err != nil {
+			return err
+		}
+		if err := job.WithTxn(p.Txn()).Update(ctx, func(
+			txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+		) error {
+			details, ok := md.Payload.Details.(*jobspb.Payload_Changefeed)
+			if !ok {
+				return errors.Errorf(`job %d is not changefeed job`, jobID)
+			}
+			if md.Status != jobs.StatusPaused {
+				return errors.Errorf(`job %d is not paused`, jobID)
+			}
+			newDetails, descIDs, err := alterChangefeedDetails(
+				ctx, p, txn, jobID, *details.Changefeed, md.Progress,
+				alterChangefeedStmt.Cmds, setOpts,
+			)
+			if err != nil {
+				return err
+			}
+
+			description, err := alterChangefeedJobDescription(p, newDetails)
+			if err != nil {
+				return err
+			}
+			md.Payload.Details = jobspb.WrapPayloadDetails(newDetails)
+			md.Payload.DescriptorIDs = descIDs
+			md.Payload.Description = description
+			ju.UpdatePayload(md.Payload)
+			ju.UpdateProgress(md.Progress)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		telemetry.Count(`changefeed.alter`)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(jobID)),
+		}:
+			return nil
+		}
+	}
+	return fn, header, nil, false, nil
+}
+
+// alterChangefeedDetails applies the commands of an ALTER CHANGEFEED statement
+// to the details of a paused changefeed and returns the new details along with
+// the IDs of the descriptors it now watches. The progress is updated in place:
+// the spans of added tables are recorded without a resolved timestamp, which
+// makes the changefeed scan them when it's resumed, and the protected
+// timestamp record is replaced by one covering the new set of targets.
+func alterChangefeedDetails(
+	ctx context.Context,
+	p sql.PlanHookState,
+	txn *kv.Txn,
+	jobID int64,
+	details jobspb.ChangefeedDetails,
+	progress *jobspb.Progress,
+	cmds tree.AlterChangefeedCmds,
+	setOpts []map[string]string,
+) (jobspb.ChangefeedDetails, []descpb.ID, error) {
+	execCfg := p.ExecCfg()
+	statementTime := hlc.Timestamp{
+		WallTime: p.ExtendedEvalContext().GetStmtTimestamp().UnixNano(),
+	}
+
+	opts := make(map[string]string, len(details.Opts))
+	for k, v := range details.Opts {
+		opts[k] = v
+	}
+	sinkURI := details.SinkURI
+	targets := make(jobspb.ChangefeedTargets, len(details.Targets))
+	for id, target := range details.Targets {
+		targets[id] = target
+	}
+	added := make(jobspb.ChangefeedTargets)
+	var dropped []descpb.ID
+
+	resolveTables := func(targetList tree.TargetList) ([]catalog.TableDescriptor, error) {
+		if len(targetList.Databases) > 0 {
+			return nil, errors.Errorf(`CHANGEFEED cannot target %s`, tree.AsString(&targetList))
+		}
+		for _, t := range targetList.Tables {
+			pattern, err := t.NormalizeTablePattern()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := pattern.(*tree.TableName); !ok {
+				return nil, errors.Errorf(`CHANGEFEED cannot target %s`, tree.AsString(t))
+			}
+		}
+		descs, _, err := backupccl.ResolveTargetsToDescriptors(ctx, p, statementTime, &targetList)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve targets in the ALTER CHANGEFEED stmt")
+		}
+		var tables []catalog.TableDescriptor
+		for _, desc := range descs {
+			if table, isTable := desc.(catalog.TableDescriptor); isTable {
+				tables = append(tables, table)
+			}
+		}
+		return tables, nil
+	}
+
+	setOptsIdx := 0
+	for _, cmd := range cmds {
+		switch cmd := cmd.(type) {
+		case *tree.AlterChangefeedAddTarget:
+			tables, err := resolveTables(cmd.Targets)
+			if err != nil {
+				return jobspb.ChangefeedDetails{}, nil, err
+			}
+			for _, table := range tables {
+				if _, ok := targets[table.GetID()]; ok {
+					return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+						`changefeed %d already watches table %s`, jobID, table.GetName())
+				}
+				if err := p.CheckPrivilege(ctx, table, privilege.SELECT); err != nil {
+					return jobspb.ChangefeedDetails{}, nil, err
+				}
+				_, qualified := opts[changefeedbase.OptFullTableName]
+				if _, ok := opts[changefeedbase.OptTopicTemplate]; ok {
+					qualified = true
+				}
+				name, err := getChangefeedTargetName(ctx, table, *execCfg, txn, qualified)
+				if err != nil {
+					return jobspb.ChangefeedDetails{}, nil, err
+				}
+				targets[table.GetID()] = jobspb.ChangefeedTarget{StatementTimeName: name}
+				added[table.GetID()] = targets[table.GetID()]
+			}
+		case *tree.AlterChangefeedDropTarget:
+			tables, err := resolveTables(cmd.Targets)
+			if err != nil {
+				return jobspb.ChangefeedDetails{}, nil, err
+			}
+			for _, table := range tables {
+				if _, ok := targets[table.GetID()]; !ok {
+					return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+						`changefeed %d does not watch table %s`, jobID, table.GetName())
+				}
+				delete(targets, table.GetID())
+				if _, ok := added[table.GetID()]; ok {
+					delete(added, table.GetID())
+				} else {
+					dropped = append(dropped, table.GetID())
+				}
+			}
+		case *tree.AlterChangefeedSetOptions:
+			for k, v := range setOpts[setOptsIdx] {
+				if _, ok := alterChangefeedImmutableOpts[k]; ok {
+					return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+						`cannot alter option %q`, k)
+				}
+				if k == alterChangefeedOptSink {
+					sinkURI = v
+					continue
+				}
+				opts[k] = v
+			}
+			setOptsIdx++
+		case *tree.AlterChangefeedUnsetOptions:
+			for _, name := range cmd.Options {
+				k := string(name)
+				if k == alterChangefeedOptSink {
+					return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+						`cannot unset option %q`, k)
+				}
+				if _, ok := changefeedbase.ChangefeedOptionExpectValues[k]; !ok {
+					return jobspb.ChangefeedDetails{}, nil, pgerror.Newf(pgcode.InvalidParameterValue,
+						"invalid option %q", k)
+				}
+				if _, ok := alterChangefeedImmutableOpts[k]; ok {
+					return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+						`cannot alter option %q`, k)
+				}
+				delete(opts, k)
+			}
+		default:
+			return jobspb.ChangefeedDetails{}, nil, errors.AssertionFailedf(
+				`unknown ALTER CHANGEFEED command %T`, cmd)
+		}
+	}
+	if len(targets) == 0 {
+		return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+			`cannot drop all targets of changefeed %d`, jobID)
+	}
+	if sinkURI == `` {
+		return jobspb.ChangefeedDetails{}, nil, errors.New(`ALTER CHANGEFEED requires a sink`)
+	}
+
+	// Validate the altered changefeed the same way CREATE CHANGEFEED does.
+	targetDescs := make([]catalog.Descriptor, 0, len(targets))
+	for id := range targets {
+		table, err := catalogkv.MustGetTableDescByID(ctx, txn, execCfg.Codec, id)
+		if err != nil {
+			return jobspb.ChangefeedDetails{}, nil, err
+		}
+		targetDescs = append(targetDescs, table)
+	}
+	sort.Slice(targetDescs, func(i, j int) bool { return targetDescs[i].GetID() < targetDescs[j].GetID() })
+	_, splitColumnFamilies := opts[changefeedbase.OptSplitColumnFamilies]
+	var descIDs []descpb.ID
+	for _, desc := range targetDescs {
+		descIDs = append(descIDs, desc.GetID())
+		if table, isTable := desc.(catalog.TableDescriptor); isTable {
+			if err := changefeedbase.ValidateTable(targets, table, splitColumnFamilies); err != nil {
+				return jobspb.ChangefeedDetails{}, nil, err
+			}
+		}
+	}
+	if err := validateFilterAndProjection(ctx, p.SemaCtx(), opts, targetDescs); err != nil {
+		return jobspb.ChangefeedDetails{}, nil, err
+	}
+	if err := validateTopicTemplate(opts, targetDescs); err != nil {
+		return jobspb.ChangefeedDetails{}, nil, err
+	}
+
+	newDetails := jobspb.ChangefeedDetails{
+		Targets:       targets,
+		Opts:          opts,
+		SinkURI:       sinkURI,
+		StatementTime: details.StatementTime,
+	}
+	parsedSink, err := url.Parse(sinkURI)
+	if err != nil {
+		return jobspb.ChangefeedDetails{}, nil, err
+	}
+	if newDetails, err = validateDetails(newDetails); err != nil {
+		return jobspb.ChangefeedDetails{}, nil, err
+	}
+	if _, err := getEncoder(newDetails.Opts, newDetails.Targets); err != nil {
+		return jobspb.ChangefeedDetails{}, nil, err
+	}
+	if isCloudStorageSink(parsedSink) {
+		newDetails.Opts[changefeedbase.OptKeyInValue] = ``
+	}
+
+	cfProgress := progress.GetChangefeed()
+	if cfProgress == nil {
+		cfProgress = &jobspb.ChangefeedProgress{}
+		progress.Details = jobspb.WrapProgressDetails(*cfProgress)
+		cfProgress = progress.GetChangefeed()
+	}
+
+	// Forget the span-level progress of dropped tables.
+	if len(dropped) > 0 {
+		resolvedSpans := cfProgress.ResolvedSpans[:0]
+		for _, rs := range cfProgress.ResolvedSpans {
+			var isDropped bool
+			for _, id := range dropped {
+				tablePrefix := execCfg.Codec.TablePrefix(uint32(id))
+				tableSpan := roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}
+				if tableSpan.Contains(rs.Span) {
+					isDropped = true
+					break
+				}
+			}
+			if !isDropped {
+				resolvedSpans = append(resolvedSpans, rs)
+			}
+		}
+		cfProgress.ResolvedSpans = resolvedSpans
+	}
+
+	if len(added) > 0 {
+		// The added tables are scanned at the high-water of the changefeed, and
+		// then follow the existing tables from there on.
+		highWater := progress.GetHighWater()
+		if highWater == nil || highWater.IsEmpty() {
+			return jobspb.ChangefeedDetails{}, nil, errors.Errorf(
+				`cannot add targets to changefeed %d before it has checkpointed a resolved timestamp`, jobID)
+		}
+		addedSpans, err := fetchSpansForTargets(ctx, execCfg.DB, execCfg.Codec, added, *highWater)
+		if err != nil {
+			return jobspb.ChangefeedDetails{}, nil, errors.Wrapf(err,
+				`fetching spans of the added targets at the high-water %s`, highWater)
+		}
+		for _, sp := range addedSpans {
+			cfProgress.ResolvedSpans = append(cfProgress.ResolvedSpans, jobspb.ResolvedSpan{Span: sp})
+		}
+
+		// Protect the data of every target at the high-water, so the added tables
+		// can still be scanned when the changefeed is resumed.
+		pts := execCfg.ProtectedTimestampProvider
+		if cfProgress.ProtectedTimestampRecord != uuid.Nil {
+			if err := pts.Release(ctx, txn, cfProgress.ProtectedTimestampRecord); err != nil &&
+				!errors.Is(err, protectedts.ErrNotExists) {
+				return jobspb.ChangefeedDetails{}, nil, err
+			}
+		}
+		if err := createProtectedTimestampRecord(ctx, execCfg.Codec, pts, txn, jobID,
+			newDetails.Targets, *highWater, cfProgress); err != nil {
+			return jobspb.ChangefeedDetails{}, nil, err
+		}
+	}
+
+	telemetry.CountBucketed(`changefeed.alter.num_tables`, int64(len(targets)))
+	return newDetails, descIDs, nil
+}
+
+// changefeedTargetTableName returns the table name of a changefeed target from
+// the name recorded in its details.
+func changefeedTargetTableName(
+	statementTimeName string, opts map[string]string,
+) (*tree.TableName, error) {
+	_, qualified := opts[changefeedbase.OptFullTableName]
+	if _, ok := opts[changefeedbase.OptTopicTemplate]; ok {
+		qualified = true
+	}
+	if qualified {
+		return parser.ParseQualifiedTableName(statementTimeName)
+	}
+	tn := tree.MakeUnqualifiedTableName(tree.Name(statementTimeName))
+	return &tn, nil
+}
+
+// alterChangefeedJobDescription returns the description of an altered
+// changefeed job, which is the CREATE CHANGEFEED statement that would create
+// it.
+func alterChangefeedJobDescription(
+	p sql.PlanHookState, details jobspb.ChangefeedDetails,
+) (string, error) {
+	var names []string
+	for _, target := range details.Targets {
+		names = append(names, target.StatementTimeName)
+	}
+	sort.Strings(names)
+	c := &tree.CreateChangefeed{}
+	for _, name := range names {
+		tn, err := changefeedTargetTableName(name, details.Opts)
+		if err != nil {
+			return "", err
+		}
+		c.Targets.Tables = append(c.Targets.Tables, tn)
+	}
+	return changefeedJobDescription(p, c, details.SinkURI, details.Opts)
+}
@@ -234,17 +234,18 @@ func createBenchmarkChangefeed(
 
 	sf := span.MakeFrontier(spans...)
 	serverCfg := s.DistSQLServer().(*distsql.ServerImpl).ServerConfig
-	eventConsumer := newKVEventToRowConsumer(ctx, &serverCfg, sf, initialHighWater,
-		sink, encoder, details, TestingKnobs{})
+	eventConsumer, err := newKVEventToRowConsumer(ctx, &serverCfg, nil /* evalCtx */, sf,
+		initialHighWater, sink, encoder, details, TestingKnobs{})
+	if err != nil {
+		return nil, nil, err
+	}
 	tickFn := func(ctx context.Context) (*jobspb.ResolvedSpan, error) {
 		event, err := buf.Get(ctx)
 		if err != nil {
 			return nil, err
 		}
-		if event.Type() == kvfeed.KVEvent {
-			if err := eventConsumer.ConsumeEvent(ctx, event); err != nil {
-				return nil, err
-			}
+		if err := eventConsumer.ConsumeEvent(ctx, event); err != nil {
+			return nil, err
 		}
 		return event.Resolved(), nil
 	}
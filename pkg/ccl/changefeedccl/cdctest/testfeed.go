@@ -13,9 +13,13 @@ import (
 	"bytes"
 	"context"
 	gosql "database/sql"
+	"encoding/base64"
 	gojson "encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -28,12 +32,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgx"
@@ -82,6 +88,14 @@ type TestFeed interface {
 	Close() error
 }
 
+// EnterpriseTestFeed is a TestFeed backed by a changefeed job.
+type EnterpriseTestFeed interface {
+	TestFeed
+	// WaitForStatus blocks until the changefeed job has a status for which the
+	// given predicate returns true.
+	WaitForStatus(func(s jobs.Status) bool) error
+}
+
 type sinklessFeedFactory struct {
 	s    serverutils.TestServerInterface
 	sink url.URL
@@ -291,6 +305,22 @@ func (f *jobFeed) Resume() error {
 	return err
 }
 
+// WaitForStatus implements the EnterpriseTestFeed interface.
+func (f *jobFeed) WaitForStatus(statusPred func(s jobs.Status) bool) error {
+	return testutils.SucceedsSoonError(func() error {
+		var status string
+		if err := f.db.QueryRow(
+			`SELECT status FROM system.jobs WHERE id = $1`, f.JobID,
+		).Scan(&status); err != nil {
+			return err
+		}
+		if !statusPred(jobs.Status(status)) {
+			return errors.Newf(`unexpected job status %s`, status)
+		}
+		return nil
+	})
+}
+
 func (f *jobFeed) Details() (*jobspb.ChangefeedDetails, error) {
 	var payloadBytes []byte
 	if err := f.db.QueryRow(
@@ -715,3 +745,158 @@ func (c *cloudFeed) Close() error {
 	}
 	return c.db.Close()
 }
+
+type webhookFeedFactory struct {
+	s       serverutils.TestServerInterface
+	db      *gosql.DB
+	flushCh chan struct{}
+}
+
+// MakeWebhookFeedFactory returns a TestFeedFactory implementation using the
+// webhook sink, which emits to a local TLS test server.
+func MakeWebhookFeedFactory(
+	s serverutils.TestServerInterface, db *gosql.DB, flushCh chan struct{},
+) TestFeedFactory {
+	return &webhookFeedFactory{s: s, db: db, flushCh: flushCh}
+}
+
+// Feed implements the TestFeedFactory interface
+func (f *webhookFeedFactory) Feed(create string, args ...interface{}) (TestFeed, error) {
+	parsed, err := parser.ParseOne(create)
+	if err != nil {
+		return nil, err
+	}
+	createStmt := parsed.AST.(*tree.CreateChangefeed)
+	if createStmt.SinkURI != nil {
+		return nil, errors.Errorf(`unexpected sink provided: "INTO %s"`, tree.AsString(createStmt.SinkURI))
+	}
+
+	c := &webhookFeed{
+		jobFeed: jobFeed{
+			db:      f.db,
+			flushCh: f.flushCh,
+		},
+		seen: make(map[string]struct{}),
+	}
+	c.srv = httptest.NewTLSServer(http.HandlerFunc(c.handle))
+	sinkURI, err := url.Parse(c.srv.URL)
+	if err != nil {
+		c.srv.Close()
+		return nil, err
+	}
+	// The sink trusts the self-signed certificate of the test server.
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: c.srv.Certificate().Raw})
+	sinkURI.Scheme = `webhook-https`
+	sinkURI.RawQuery = url.Values{`ca_cert`: {base64.StdEncoding.EncodeToString(certPEM)}}.Encode()
+	createStmt.SinkURI = tree.NewStrVal(sinkURI.String())
+
+	if err := f.db.QueryRow(createStmt.String(), args...).Scan(&c.JobID); err != nil {
+		c.srv.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Server implements the TestFeedFactory interface.
+func (f *webhookFeedFactory) Server() serverutils.TestServerInterface {
+	return f.s
+}
+
+type webhookFeed struct {
+	jobFeed
+	srv *httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		messages []*TestFeedMessage
+	}
+
+	seen map[string]struct{}
+}
+
+const webhookFeedPartition = ``
+
+// handle decodes the requests of the webhook sink, which either hold a batch
+// of rows or a resolved timestamp.
+func (c *webhookFeed) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var batch struct {
+		Payload []struct {
+			Topic string            `json:"topic"`
+			Key   gojson.RawMessage `json:"key"`
+			Value gojson.RawMessage `json:"value"`
+		} `json:"payload"`
+	}
+	if err := gojson.Unmarshal(body, &batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if batch.Payload == nil {
+		c.mu.messages = append(c.mu.messages, &TestFeedMessage{
+			Partition: webhookFeedPartition,
+			Resolved:  body,
+		})
+		return
+	}
+	for _, row := range batch.Payload {
+		m := &TestFeedMessage{
+			Topic:     row.Topic,
+			Partition: webhookFeedPartition,
+			Key:       []byte(row.Key),
+		}
+		if string(row.Value) != `null` {
+			m.Value = []byte(row.Value)
+		}
+		c.mu.messages = append(c.mu.messages, m)
+	}
+}
+
+// Partitions implements the TestFeed interface.
+func (c *webhookFeed) Partitions() []string {
+	return []string{webhookFeedPartition}
+}
+
+// Next implements the TestFeed interface.
+func (c *webhookFeed) Next() (*TestFeedMessage, error) {
+	for {
+		c.mu.Lock()
+		var m *TestFeedMessage
+		if len(c.mu.messages) > 0 {
+			m = c.mu.messages[0]
+			c.mu.messages = c.mu.messages[1:]
+		}
+		c.mu.Unlock()
+
+		if m != nil {
+			if m.Resolved != nil {
+				return m, nil
+			}
+			// Rows may be sent again when the changefeed restarts.
+			seenKey := m.Topic + m.Partition + string(m.Key) + string(m.Value)
+			if _, ok := c.seen[seenKey]; ok {
+				continue
+			}
+			c.seen[seenKey] = struct{}{}
+			return m, nil
+		}
+
+		if err := c.fetchJobError(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close implements the TestFeed interface.
+func (c *webhookFeed) Close() error {
+	if _, err := c.db.Exec(`CANCEL JOB $1`, c.JobID); err != nil {
+		log.Infof(context.Background(), `could not cancel feed %d: %v`, c.JobID, err)
+	}
+	c.srv.Close()
+	return c.db.Close()
+}
@@ -11,6 +11,7 @@ package changefeedccl
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -124,16 +125,41 @@ func distChangefeedFlow(
 		}
 	}
 
+	// Spans of targets added by ALTER CHANGEFEED are recorded in the progress
+	// without a timestamp until they've been scanned.
+	var unscannedSpans []roachpb.Span
+	if cfProgress := progress.GetChangefeed(); cfProgress != nil {
+		for _, rs := range cfProgress.ResolvedSpans {
+			if rs.Timestamp.IsEmpty() {
+				unscannedSpans = append(unscannedSpans, rs.Span)
+			}
+		}
+	}
+
+	// Cloud storage sinks in exactly-once mode name their data files with an
+	// ID unique to this flow, so that its manifests never commit the files of
+	// an interrupted flow.
+	var sessionID string
+	if _, ok := details.Opts[changefeedbase.OptExactlyOnce]; ok {
+		sessionID = generateChangefeedSessionID()
+	}
+
 	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
 	for i, sp := range spanPartitions {
 		// TODO(dan): Merge these watches with the span-level resolved
-		// timestamps from the job progress.
+		// timestamps from the job progress, beyond the unscanned spans.
 		watches := make([]execinfrapb.ChangeAggregatorSpec_Watch, len(sp.Spans))
 		for watchIdx, nodeSpan := range sp.Spans {
 			watches[watchIdx] = execinfrapb.ChangeAggregatorSpec_Watch{
 				Span:            nodeSpan,
 				InitialResolved: initialHighWater,
 			}
+			for _, unscanned := range unscannedSpans {
+				if unscanned.Contains(nodeSpan) {
+					watches[watchIdx].InitialResolved = hlc.Timestamp{}
+					break
+				}
+			}
 		}
 
 		corePlacement[i].NodeID = sp.Node
@@ -141,6 +167,7 @@ func distChangefeedFlow(
 			Watches:   watches,
 			Feed:      details,
 			UserProto: execCtx.User().EncodeProto(),
+			SessionID: sessionID,
 		}
 	}
 	// NB: This SpanFrontier processor depends on the set of tracked spans being
@@ -152,6 +179,7 @@ func distChangefeedFlow(
 		Feed:         details,
 		JobID:        jobID,
 		UserProto:    execCtx.User().EncodeProto(),
+		SessionID:    sessionID,
 	}
 
 	p := planCtx.NewPhysicalPlan()
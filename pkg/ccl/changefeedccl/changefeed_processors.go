@@ -18,10 +18,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvfeed"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
@@ -205,6 +207,11 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 
 	spans := ca.setupSpansAndFrontier()
 	timestampOracle := &changeAggregatorLowerBoundOracle{sf: ca.spanFrontier, initialInclusiveLowerBound: ca.spec.Feed.StatementTime}
+	if initialHighWater, _, initialScanSpans := getKVFeedInitialParameters(ca.spec); len(initialScanSpans) > 0 {
+		// Rows of the targets added by ALTER CHANGEFEED are scanned right after
+		// the high-water of the other targets, which were all emitted already.
+		timestampOracle.initialInclusiveLowerBound = initialHighWater.Next()
+	}
 
 	var err error
 	ca.sink, err = getSink(
@@ -224,6 +231,9 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	if b, ok := ca.sink.(*bufferSink); ok {
 		ca.changedRowBuf = &b.buf
 	}
+	if s, ok := ca.sink.(*cloudStorageSink); ok && s.exactlyOnce {
+		s.startExactlyOnceSession(ca.spec.SessionID)
+	}
 
 	// The job registry has a set of metrics used to monitor the various jobs it
 	// runs. They're all stored as the `metric.Struct` interface because of
@@ -236,16 +246,28 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		ca.knobs = *cfKnobs
 	}
 
-	// It seems like we should also be able to use `ca.ProcessorBase.MemMonitor`
-	// for the poller, but there is a race between the flow's MemoryMonitor
-	// getting Stopped and `changeAggregator.Close`, which causes panics. Not sure
-	// what to do about this yet.
-	kvFeedMemMonCapacity := kvfeed.MemBufferDefaultCapacity
+	// The kvfeed draws from the budget shared by all the changefeeds on the
+	// node, up to a per-changefeed limit.
+	//
+	// Server configs without a changefeed monitor (some tests, for example) fall
+	// back to the flow's monitor, with a standalone budget of the per-changefeed
+	// limit. It's not used as the pool itself because there is a race between
+	// the flow's MemoryMonitor getting Stopped and `changeAggregator.Close`,
+	// which causes panics.
+	kvFeedMemLimit := changefeedbase.PerChangefeedMemLimit.Get(&ca.flowCtx.Cfg.Settings.SV)
 	if ca.knobs.MemBufferCapacity != 0 {
-		kvFeedMemMonCapacity = ca.knobs.MemBufferCapacity
+		kvFeedMemLimit = ca.knobs.MemBufferCapacity
+	}
+	var kvFeedMemMon *mon.BytesMonitor
+	if pool := ca.flowCtx.Cfg.ChangefeedMonitor; pool != nil {
+		kvFeedMemMon = mon.NewMonitorInheritWithLimit("kvFeed", kvFeedMemLimit, pool)
+		kvFeedMemMon.SetMetrics(ca.metrics.MemCurBytes, ca.metrics.MemMaxBytesHist)
+		kvFeedMemMon.Start(ctx, pool, mon.BoundAccount{})
+	} else {
+		kvFeedMemMon = mon.NewMonitorInheritWithLimit("kvFeed", math.MaxInt64, ca.ProcessorBase.MemMonitor)
+		kvFeedMemMon.SetMetrics(ca.metrics.MemCurBytes, ca.metrics.MemMaxBytesHist)
+		kvFeedMemMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(kvFeedMemLimit))
 	}
-	kvFeedMemMon := mon.NewMonitorInheritWithLimit("kvFeed", math.MaxInt64, ca.ProcessorBase.MemMonitor)
-	kvFeedMemMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(kvFeedMemMonCapacity))
 	ca.kvFeedMemMon = kvFeedMemMon
 
 	buf := kvfeed.MakeChanBuffer()
@@ -256,8 +278,14 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	cfg := ca.flowCtx.Cfg
 
 	ca.eventProducer = &bufEventProducer{buf}
-	ca.eventConsumer = newKVEventToRowConsumer(ctx, cfg, ca.spanFrontier, kvfeedCfg.InitialHighWater,
-		ca.sink, ca.encoder, ca.spec.Feed, ca.knobs)
+	ca.eventConsumer, err = newKVEventToRowConsumer(ctx, cfg, ca.flowCtx.NewEvalCtx(), ca.spanFrontier,
+		kvfeedCfg.InitialHighWater, ca.sink, ca.encoder, ca.spec.Feed, ca.knobs)
+	if err != nil {
+		// Early abort in the case that the filter can't be parsed.
+		ca.MoveToDraining(err)
+		ca.cancel()
+		return ctx
+	}
 	ca.startKVFeed(ctx, kvfeedCfg)
 
 	return ctx
@@ -298,7 +326,9 @@ func makeKVFeedCfg(
 		spec.Feed.Opts[changefeedbase.OptSchemaChangeEvents])
 	schemaChangePolicy := changefeedbase.SchemaChangePolicy(
 		spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
-	initialHighWater, needsInitialScan := getKVFeedInitialParameters(spec)
+	initialHighWater, needsInitialScan, initialScanSpans := getKVFeedInitialParameters(spec)
+	_, initialScanOnly := spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
+	_, splitColumnFamilies := spec.Feed.Opts[changefeedbase.OptSplitColumnFamilies]
 	kvfeedCfg := kvfeed.Config{
 		Sink:               buf,
 		Settings:           cfg.Settings,
@@ -312,10 +342,14 @@ func makeKVFeedCfg(
 		Metrics:            &metrics.KVFeedMetrics,
 		MM:                 mm,
 		InitialHighWater:   initialHighWater,
+		InitialScanSpans:   initialScanSpans,
 		WithDiff:           withDiff,
 		NeedsInitialScan:   needsInitialScan,
+		InitialScanOnly:    initialScanOnly,
 		SchemaChangeEvents: schemaChangeEvents,
 		SchemaChangePolicy: schemaChangePolicy,
+
+		SplitColumnFamilies: splitColumnFamilies,
 	}
 	return kvfeedCfg
 }
@@ -326,13 +360,17 @@ func makeKVFeedCfg(
 // higher layers mark each watch with the checkpointed resolved timestamp if no
 // initial scan is needed.
 //
-// TODO(ajwerner): Utilize this partial checkpointing, especially in the face of
-// of logical backfills of a single table while progress is made on others or
-// get rid of it. See https://github.com/cockroachdb/cockroach/issues/43896.
+// If only some of the watches are missing a resolved timestamp, which happens
+// after ALTER CHANGEFEED adds targets, the feed starts at the high-water of the
+// others and only the spans of the unresolved watches are scanned.
 func getKVFeedInitialParameters(
 	spec execinfrapb.ChangeAggregatorSpec,
-) (initialHighWater hlc.Timestamp, needsInitialScan bool) {
+) (initialHighWater hlc.Timestamp, needsInitialScan bool, initialScanSpans []roachpb.Span) {
 	for _, watch := range spec.Watches {
+		if watch.InitialResolved.IsEmpty() {
+			initialScanSpans = append(initialScanSpans, watch.Span)
+			continue
+		}
 		if initialHighWater.IsEmpty() || watch.InitialResolved.Less(initialHighWater) {
 			initialHighWater = watch.InitialResolved
 		}
@@ -341,10 +379,10 @@ func getKVFeedInitialParameters(
 	// checkpointed a resolved timestamp or we have a cursor but we want an
 	// initial scan. The higher levels will coordinate that we only have empty
 	// watches when we need an initial scan.
-	if needsInitialScan = initialHighWater.IsEmpty(); needsInitialScan {
-		initialHighWater = spec.Feed.StatementTime
+	if initialHighWater.IsEmpty() {
+		return spec.Feed.StatementTime, true, nil
 	}
-	return initialHighWater, needsInitialScan
+	return initialHighWater, len(initialScanSpans) > 0, initialScanSpans
 }
 
 // setupSpans is called on start to extract the spans for this changefeed as a
@@ -438,10 +476,8 @@ func (ca *changeAggregator) tick() error {
 	processingNanos := timeutil.Since(event.BufferGetTimestamp()).Nanoseconds()
 	ca.metrics.ProcessingNanos.Inc(processingNanos)
 
-	if event.Type() == kvfeed.KVEvent {
-		if err := ca.eventConsumer.ConsumeEvent(ca.Ctx, event); err != nil {
-			return err
-		}
+	if err := ca.eventConsumer.ConsumeEvent(ca.Ctx, event); err != nil {
+		return err
 	}
 
 	return ca.maybeFlush(event.Resolved())
@@ -516,7 +552,9 @@ func (p *bufEventProducer) GetEvent(ctx context.Context) (kvfeed.Event, error) {
 }
 
 type kvEventConsumer interface {
-	// ConsumeEvent responsible for consuming kv event.
+	// ConsumeEvent responsible for consuming kv event. Resolved events are
+	// handed to it too, before the resolved span is forwarded, so that it can
+	// emit anything it was holding back at or below the resolved timestamp.
 	ConsumeEvent(ctx context.Context, event kvfeed.Event) error
 }
 
@@ -530,6 +568,47 @@ type kvEventToRowConsumer struct {
 	rfCache   *rowFetcherCache
 	details   jobspb.ChangefeedDetails
 	kvFetcher row.SpanKVFetcher
+	// topicNamer, if non-nil, computes the topic, partition key and, if column
+	// families are split, family name of each row, which are handed to the sink
+	// in a routedTable.
+	topicNamer *topicNamer
+	// filter, if non-nil, drops row changes which don't match the `filter`
+	// option before they are encoded.
+	filter *rowFilter
+
+	// A tombstone on a column family other than family 0 is left behind both
+	// by deleting the row and by setting all of the family's columns to NULL.
+	// Only a family 0 tombstone at the same timestamp tells them apart, and it
+	// may come before or after the other family's. So changes to other
+	// families which look like the row was deleted (or, with the diff option,
+	// didn't exist before) are held back in pendingFamilyChanges until a
+	// resolved timestamp covers them, and settled with the family 0 changes
+	// recorded in familyZeroChanges. pendingFamilyKeys counts the pending
+	// changes of each key, so that later changes of the key are held back
+	// behind them.
+	familyZeroChanges    map[familyChangeKey]familyZeroChange
+	pendingFamilyChanges []pendingFamilyChange
+	pendingFamilyKeys    map[string]int
+}
+
+// familyChangeKey identifies the change to a row at a timestamp.
+type familyChangeKey struct {
+	row string
+	ts  hlc.Timestamp
+}
+
+// familyZeroChange is what a change to the family 0 KV of a row says about
+// the row's existence.
+type familyZeroChange struct {
+	deleted, prevDeleted bool
+}
+
+// pendingFamilyChange is a change to a column family other than family 0
+// which is held back until it's known whether the row was deleted.
+type pendingFamilyChange struct {
+	key    roachpb.Key
+	rowKey string
+	row    encodeRow
 }
 
 var _ kvEventConsumer = &kvEventToRowConsumer{}
@@ -537,17 +616,18 @@ var _ kvEventConsumer = &kvEventToRowConsumer{}
 func newKVEventToRowConsumer(
 	ctx context.Context,
 	cfg *execinfra.ServerConfig,
+	evalCtx *tree.EvalContext,
 	frontier *span.Frontier,
 	cursor hlc.Timestamp,
 	sink Sink,
 	encoder Encoder,
 	details jobspb.ChangefeedDetails,
 	knobs TestingKnobs,
-) kvEventConsumer {
+) (kvEventConsumer, error) {
 	rfCache := newRowFetcherCache(ctx, cfg.Codec, cfg.Settings,
 		cfg.LeaseManager.(*lease.Manager), cfg.HydratedTables, cfg.DB)
 
-	return &kvEventToRowConsumer{
+	c := &kvEventToRowConsumer{
 		frontier: frontier,
 		encoder:  encoder,
 		sink:     sink,
@@ -556,12 +636,23 @@ func newKVEventToRowConsumer(
 		details:  details,
 		knobs:    knobs,
 	}
+	if filter, ok := details.Opts[changefeedbase.OptFilter]; ok {
+		var err error
+		if c.filter, err = newRowFilter(filter, evalCtx); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if c.topicNamer, err = makeTopicNamer(details.Opts, details.Targets); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // ConsumeEvent implements kvEventConsumer interface
 func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, event kvfeed.Event) error {
-	if event.Type() != kvfeed.KVEvent {
-		return errors.AssertionFailedf("expected kv event, got %v", event.Type())
+	if event.Type() == kvfeed.ResolvedEvent {
+		return c.settleFamilyChanges(ctx, event.Resolved())
 	}
 
 	r, err := c.eventToRow(ctx, event)
@@ -581,6 +672,94 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, event kvfeed.Ev
 			cloudStorageFormatTime(c.frontier.Frontier()))
 		return nil
 	}
+	// Backfills only scan live KVs, so they never leave a family's existence
+	// in doubt.
+	if len(r.tableDesc.GetFamilies()) > 1 && event.BackfillTimestamp().IsEmpty() {
+		if held, err := c.maybeHoldFamilyChange(event.KV().Key, r); err != nil || held {
+			return err
+		}
+	}
+	return c.emitRow(ctx, r)
+}
+
+// maybeHoldFamilyChange records the change to a family 0 KV, and holds back
+// a change to another family if it can't yet be told whether it deleted the
+// row. It returns whether the change was held back.
+func (c *kvEventToRowConsumer) maybeHoldFamilyChange(key roachpb.Key, r encodeRow) (bool, error) {
+	n, err := keys.GetRowPrefixLength(key)
+	if err != nil {
+		return false, err
+	}
+	rowKey := string(key[:n])
+	if r.familyID == 0 {
+		if c.familyZeroChanges == nil {
+			c.familyZeroChanges = make(map[familyChangeKey]familyZeroChange)
+		}
+		c.familyZeroChanges[familyChangeKey{row: rowKey, ts: r.updated}] = familyZeroChange{
+			deleted: r.deleted, prevDeleted: r.prevDeleted,
+		}
+		return false, nil
+	}
+	if !r.deleted && !r.prevDeleted && c.pendingFamilyKeys[string(key)] == 0 {
+		return false, nil
+	}
+	if c.pendingFamilyKeys == nil {
+		c.pendingFamilyKeys = make(map[string]int)
+	}
+	c.pendingFamilyKeys[string(key)]++
+	c.pendingFamilyChanges = append(c.pendingFamilyChanges, pendingFamilyChange{
+		key: key, rowKey: rowKey, row: r,
+	})
+	return true, nil
+}
+
+// settleFamilyChanges emits the held back changes covered by the resolved
+// span. Every change to the span at or below the resolved timestamp has been
+// consumed by now, so if there's no change to the row's family 0 KV at the
+// same timestamp, the row existed both before and after the change.
+func (c *kvEventToRowConsumer) settleFamilyChanges(
+	ctx context.Context, resolved *jobspb.ResolvedSpan,
+) error {
+	if len(c.pendingFamilyChanges) == 0 && len(c.familyZeroChanges) == 0 {
+		return nil
+	}
+	covered := func(key roachpb.Key, ts hlc.Timestamp) bool {
+		return ts.LessEq(resolved.Timestamp) && resolved.Span.ContainsKey(key)
+	}
+	pending := c.pendingFamilyChanges
+	c.pendingFamilyChanges = pending[:0]
+	for _, p := range pending {
+		if !covered(p.key, p.row.updated) {
+			c.pendingFamilyChanges = append(c.pendingFamilyChanges, p)
+			continue
+		}
+		if c.pendingFamilyKeys[string(p.key)]--; c.pendingFamilyKeys[string(p.key)] == 0 {
+			delete(c.pendingFamilyKeys, string(p.key))
+		}
+		z, ok := c.familyZeroChanges[familyChangeKey{row: p.rowKey, ts: p.row.updated}]
+		p.row.deleted = p.row.deleted && ok && z.deleted
+		p.row.prevDeleted = p.row.prevDeleted && ok && z.prevDeleted
+		if err := c.emitRow(ctx, p.row); err != nil {
+			return err
+		}
+	}
+	for k := range c.familyZeroChanges {
+		if covered(roachpb.Key(k.row), k.ts) {
+			delete(c.familyZeroChanges, k)
+		}
+	}
+	return nil
+}
+
+// emitRow filters, encodes and emits a row change to the sink.
+func (c *kvEventToRowConsumer) emitRow(ctx context.Context, r encodeRow) error {
+	if c.filter != nil {
+		if matches, err := c.filter.matchesEvent(ctx, r); err != nil {
+			return err
+		} else if !matches {
+			return nil
+		}
+	}
 	var keyCopy, valueCopy []byte
 	encodedKey, err := c.encoder.EncodeKey(ctx, r)
 	if err != nil {
@@ -598,8 +777,28 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, event kvfeed.Ev
 			return err
 		}
 	}
+	var table catalog.TableDescriptor = r.tableDesc
+	if c.topicNamer != nil {
+		topic, partitionKey, err := c.topicNamer.route(r)
+		if err != nil {
+			return err
+		}
+		var partitionKeyCopy []byte
+		if partitionKey != nil {
+			c.scratch, partitionKeyCopy = c.scratch.Copy(partitionKey, 0 /* extraCap */)
+		}
+		routed := &routedTable{TableDescriptor: r.tableDesc, topic: topic, partitionKey: partitionKeyCopy}
+		if c.topicNamer.splitFamilies {
+			family, err := r.tableDesc.FindFamilyByID(r.familyID)
+			if err != nil {
+				return err
+			}
+			routed.family = family.Name
+		}
+		table = routed
+	}
 	if err := c.sink.EmitRow(
-		ctx, r.tableDesc, keyCopy, valueCopy, r.updated,
+		ctx, table, keyCopy, valueCopy, r.updated,
 	); err != nil {
 		return err
 	}
@@ -639,8 +838,8 @@ func (c *kvEventToRowConsumer) eventToRow(
 		return r, err
 	}
 
-	// Get new value.
-	// TODO(dan): Handle tables with multiple column families.
+	// Get new value. Each KV holds one column family of the row, so the
+	// columns of any other family are decoded as NULL.
 	// Reuse kvs to save allocations.
 	c.kvFetcher.KVs = c.kvFetcher.KVs[:0]
 	c.kvFetcher.KVs = append(c.kvFetcher.KVs, event.KV())
@@ -656,8 +855,13 @@ func (c *kvEventToRowConsumer) eventToRow(
 		return r, errors.AssertionFailedf("unexpected empty datums")
 	}
 	r.datums = append(rowenc.EncDatumRow(nil), r.datums...)
-	r.deleted = rf.RowIsDeleted()
 	r.updated = schemaTimestamp
+	if r.familyID, err = familyIDForKey(event.KV().Key); err != nil {
+		return r, err
+	}
+	// For families other than family 0, this is settled in
+	// settleFamilyChanges.
+	r.deleted = rf.RowIsDeleted()
 
 	// Assert that we don't get a second row from the row.Fetcher. We
 	// fed it a single KV, so that would be surprising.
@@ -690,7 +894,6 @@ func (c *kvEventToRowConsumer) eventToRow(
 		}
 
 		prevKV := roachpb.KeyValue{Key: event.KV().Key, Value: event.PrevValue()}
-		// TODO(dan): Handle tables with multiple column families.
 		// Reuse kvs to save allocations.
 		c.kvFetcher.KVs = c.kvFetcher.KVs[:0]
 		c.kvFetcher.KVs = append(c.kvFetcher.KVs, prevKV)
@@ -752,6 +955,13 @@ type changeFrontier struct {
 	freqEmitResolved time.Duration
 	// lastEmitResolved is the last time a resolved timestamp was emitted.
 	lastEmitResolved time.Time
+	// exactlyOnce is set by the `exactly_once` option, in which case resolved
+	// timestamps are only emitted, as manifests, at multiples of
+	// freqEmitResolved. See maybeEmitManifest.
+	exactlyOnce bool
+	// lastManifest is the timestamp of the last manifest emitted, or of the
+	// job high-water the changefeed resumed from.
+	lastManifest hlc.Timestamp
 	// lastSlowSpanLog is the last time a slow span from `sf` was logged.
 	lastSlowSpanLog time.Time
 
@@ -770,6 +980,10 @@ type changeFrontier struct {
 	// which schema change events lead to a schemaChangeBoundary is controlled
 	// by the KV feed based on OptSchemaChangeEvents and OptSchemaChangePolicy.
 	schemaChangeBoundary hlc.Timestamp
+	// finalResolvedEmitted is set once a changefeed with the initial_scan_only
+	// option has emitted its final resolved timestamp, after which the
+	// changeFrontier drains.
+	finalResolvedEmitted bool
 
 	// jobProgressedFn, if non-nil, is called to checkpoint the changefeed's
 	// progress in the corresponding system job entry.
@@ -842,6 +1056,7 @@ func newChangeFrontierProcessor(
 	} else {
 		cf.freqEmitResolved = emitNoResolved
 	}
+	_, cf.exactlyOnce = cf.spec.Feed.Opts[changefeedbase.OptExactlyOnce]
 
 	var err error
 	if cf.encoder, err = getEncoder(spec.Feed.Opts, spec.Feed.Targets); err != nil {
@@ -876,6 +1091,7 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 	if b, ok := cf.sink.(*bufferSink); ok {
 		cf.resolvedBuf = &b.buf
 	}
+	csSink, _ := cf.sink.(*cloudStorageSink)
 
 	// The job registry has a set of metrics used to monitor the various jobs it
 	// runs. They're all stored as the `metric.Struct` interface because of
@@ -896,6 +1112,22 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 		p := job.Progress()
 		if ts := p.GetHighWater(); ts != nil {
 			cf.highWaterAtStart.Forward(*ts)
+			cf.lastManifest = *ts
+		}
+	}
+
+	if cf.exactlyOnce && csSink != nil {
+		// Rows at the statement time are emitted by the initial scan, so they're
+		// only committed if the changefeed hasn't checkpointed yet.
+		committed := cf.lastManifest
+		if committed.IsEmpty() {
+			committed = cf.spec.Feed.StatementTime.Prev()
+		}
+		if err := csSink.startExactlyOnceManifests(
+			ctx, cf.spec.SessionID, committed, cf.flowCtx.Cfg.DB.Clock().Now(),
+		); err != nil {
+			cf.MoveToDraining(MarkRetryableError(err))
+			return ctx
 		}
 	}
 
@@ -965,7 +1197,14 @@ func (cf *changeFrontier) shouldFailOnSchemaChange() bool {
 // install protected timestamps when encountering scan boundaries.
 func (cf *changeFrontier) shouldProtectBoundaries() bool {
 	policy := changefeedbase.SchemaChangePolicy(cf.spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
-	return policy == changefeedbase.OptSchemaChangePolicyBackfill
+	return policy == changefeedbase.OptSchemaChangePolicyBackfill && !cf.isInitialScanOnly()
+}
+
+// isInitialScanOnly checks the job's spec to determine whether it should stop
+// once the initial scan is done, which the kvfeeds signal with a scan boundary.
+func (cf *changeFrontier) isInitialScanOnly() bool {
+	_, initialScanOnly := cf.spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
+	return initialScanOnly
 }
 
 // Next is part of the RowSource interface.
@@ -985,6 +1224,21 @@ func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 			break
 		}
 
+		if cf.schemaChangeBoundaryReached() && cf.isInitialScanOnly() {
+			if cf.finalResolvedEmitted {
+				cf.MoveToDraining(nil /* err */)
+				break
+			}
+			// Emit the final resolved timestamp and come back around to drain
+			// once it has been returned, in case it's buffered.
+			if err := cf.emitFinalResolved(); err != nil {
+				cf.MoveToDraining(err)
+				break
+			}
+			cf.finalResolvedEmitted = true
+			continue
+		}
+
 		row, meta := cf.input.Next()
 		if meta != nil {
 			if meta.Err != nil {
@@ -1069,6 +1323,9 @@ func (cf *changeFrontier) handleFrontierChanged(isBehind bool) error {
 		cf.metrics.mu.resolved[cf.metricsID] = newResolved
 	}
 	cf.metrics.mu.Unlock()
+	if cf.exactlyOnce {
+		return cf.maybeEmitManifest(newResolved, isBehind)
+	}
 	if err := cf.checkpointResolvedTimestamp(newResolved, isBehind); err != nil {
 		return err
 	}
@@ -1078,6 +1335,35 @@ func (cf *changeFrontier) handleFrontierChanged(isBehind bool) error {
 	return nil
 }
 
+// maybeEmitManifest takes the place of checkpointing and emitting each new
+// resolved timestamp for exactly_once changefeeds. Whenever the frontier
+// passes a multiple of the resolved interval, the sink writes a manifest
+// committing the data files written up to it, and only then is the job
+// checkpointed at that timestamp. This way a restarted changefeed never
+// re-emits rows into files committed by an earlier manifest: they are either
+// at or before the new high-water, or they are ignored by the next manifest,
+// which only commits files written by the new session.
+func (cf *changeFrontier) maybeEmitManifest(newResolved hlc.Timestamp, isBehind bool) error {
+	resolved := manifestTimestamp(newResolved, cf.freqEmitResolved)
+	if cf.schemaChangeBoundaryReached() && cf.shouldFailOnSchemaChange() {
+		// No rows are emitted past a boundary the changefeed stops at, so every
+		// file written so far can be committed.
+		resolved = newResolved
+	}
+	if cf.lastManifest.Less(resolved) {
+		if err := emitResolvedTimestamp(cf.Ctx, cf.encoder, cf.sink, resolved); err != nil {
+			return err
+		}
+		cf.lastManifest = resolved
+		cf.lastEmitResolved = resolved.GoTime()
+	} else if !cf.schemaChangeBoundaryReached() || cf.lastManifest.IsEmpty() {
+		return nil
+	}
+	// Schema change boundaries are checkpointed even without a new manifest,
+	// so that their protected timestamp gets created.
+	return cf.checkpointResolvedTimestamp(cf.lastManifest, isBehind)
+}
+
 // checkpointResolvedTimestamp checkpoints a changefeed-level resolved timestamp
 // to the jobs record. It additionally manages the protected timestamp state
 // which is stored in the job progress details. It is only called if the new
@@ -1099,6 +1385,23 @@ func (cf *changeFrontier) checkpointResolvedTimestamp(
 		if err := cf.manageProtectedTimestamps(ctx, progress, txn, resolved, isBehind); err != nil {
 			return hlc.Timestamp{}, err
 		}
+		// Spans of targets added by ALTER CHANGEFEED stay in the progress until
+		// the frontier has resolved them, that is until they've been scanned.
+		// Checkpoints, such as those of schema change boundaries, can happen
+		// before then.
+		resolvedSpans := progress.ResolvedSpans[:0]
+		for _, rs := range progress.ResolvedSpans {
+			scanned := true
+			cf.sf.Entries(func(sp roachpb.Span, ts hlc.Timestamp) {
+				if ts.IsEmpty() && sp.Overlaps(rs.Span) {
+					scanned = false
+				}
+			})
+			if !scanned {
+				resolvedSpans = append(resolvedSpans, rs)
+			}
+		}
+		progress.ResolvedSpans = resolvedSpans
 		return resolved, nil
 	})
 }
@@ -1176,6 +1479,20 @@ func (cf *changeFrontier) maybeProtectTimestamp(
 	return createProtectedTimestampRecord(ctx, cf.flowCtx.Codec(), pts, txn, jobID, targets, resolved, progress)
 }
 
+// emitFinalResolved emits the resolved timestamp at which a changefeed with
+// the initial_scan_only option finished its scan, unless it was already
+// emitted due to the resolved option, and flushes the sink.
+func (cf *changeFrontier) emitFinalResolved() error {
+	resolved := cf.sf.Frontier()
+	if cf.freqEmitResolved == emitNoResolved || !cf.lastEmitResolved.Equal(resolved.GoTime()) {
+		if err := emitResolvedTimestamp(cf.Ctx, cf.encoder, cf.sink, resolved); err != nil {
+			return err
+		}
+		cf.lastEmitResolved = resolved.GoTime()
+	}
+	return cf.sink.Flush(cf.Ctx)
+}
+
 func (cf *changeFrontier) maybeEmitResolved(newResolved hlc.Timestamp) error {
 	if cf.freqEmitResolved == emitNoResolved {
 		return nil
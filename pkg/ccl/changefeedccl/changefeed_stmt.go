@@ -197,9 +197,15 @@ func changefeedPlanHook(
 			}
 		}
 		targets := make(jobspb.ChangefeedTargets, len(targetDescs))
+		_, splitColumnFamilies := opts[changefeedbase.OptSplitColumnFamilies]
 		for _, desc := range targetDescs {
 			if table, isTable := desc.(catalog.TableDescriptor); isTable {
+				// Topic templates can refer to the database and schema of each
+				// table, so they're recorded along with the table name.
 				_, qualified := opts[changefeedbase.OptFullTableName]
+				if _, ok := opts[changefeedbase.OptTopicTemplate]; ok {
+					qualified = true
+				}
 				name, err := getChangefeedTargetName(ctx, table, *p.ExecCfg(), p.Txn(), qualified)
 				if err != nil {
 					return err
@@ -207,11 +213,17 @@ func changefeedPlanHook(
 				targets[table.GetID()] = jobspb.ChangefeedTarget{
 					StatementTimeName: name,
 				}
-				if err := changefeedbase.ValidateTable(targets, table); err != nil {
+				if err := changefeedbase.ValidateTable(targets, table, splitColumnFamilies); err != nil {
 					return err
 				}
 			}
 		}
+		if err := validateFilterAndProjection(ctx, p.SemaCtx(), opts, targetDescs); err != nil {
+			return err
+		}
+		if err := validateTopicTemplate(opts, targetDescs); err != nil {
+			return err
+		}
 
 		details := jobspb.ChangefeedDetails{
 			Targets:       targets,
@@ -404,7 +416,9 @@ func changefeedPlanHook(
 func changefeedJobDescription(
 	p sql.PlanHookState, changefeed *tree.CreateChangefeed, sinkURI string, opts map[string]string,
 ) (string, error) {
-	cleanedSinkURI, err := cloudimpl.SanitizeExternalStorageURI(sinkURI, []string{changefeedbase.SinkParamSASLPassword})
+	cleanedSinkURI, err := cloudimpl.SanitizeExternalStorageURI(sinkURI, []string{
+		changefeedbase.SinkParamSASLPassword, changefeedbase.SinkParamWebhookAuthHeader,
+	})
 	if err != nil {
 		return "", err
 	}
@@ -442,6 +456,15 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 			}
 		}
 	}
+	if _, ok := details.Opts[changefeedbase.OptExactlyOnce]; ok {
+		// Data files are committed by manifests at multiples of the resolved
+		// timestamp interval.
+		if d, err := time.ParseDuration(details.Opts[changefeedbase.OptResolvedTimestamps]); err != nil || d <= 0 {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`%s requires the %s option with a positive interval`,
+				changefeedbase.OptExactlyOnce, changefeedbase.OptResolvedTimestamps)
+		}
+	}
 	{
 		const opt = changefeedbase.OptSchemaChangeEvents
 		switch v := changefeedbase.SchemaChangeEventClass(details.Opts[opt]); v {
@@ -476,6 +499,12 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 				`cannot specify both %s and %s`, changefeedbase.OptInitialScan,
 				changefeedbase.OptNoInitialScan)
 		}
+		_, initialScanOnly := details.Opts[changefeedbase.OptInitialScanOnly]
+		if initialScanOnly && noInitialScan {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`cannot specify both %s and %s`, changefeedbase.OptInitialScanOnly,
+				changefeedbase.OptNoInitialScan)
+		}
 	}
 	{
 		const opt = changefeedbase.OptEnvelope
@@ -496,7 +525,7 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		switch v := changefeedbase.FormatType(details.Opts[opt]); v {
 		case ``, changefeedbase.OptFormatJSON:
 			details.Opts[opt] = string(changefeedbase.OptFormatJSON)
-		case changefeedbase.OptFormatAvro:
+		case changefeedbase.OptFormatAvro, changefeedbase.OptFormatParquet:
 			// No-op.
 		default:
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -565,6 +594,14 @@ func (b *changefeedResumer) Resume(ctx context.Context, execCtx interface{}) err
 		startedCh := make(chan tree.Datums, 1)
 
 		if err = distChangefeedFlow(ctx, jobExec, jobID, details, progress, startedCh); err == nil {
+			// The flow only finishes without error for changefeeds with the
+			// initial_scan_only option, once their scan is done. The job is about
+			// to succeed, so nothing needs its data protected anymore.
+			if reloadedJob, err := execCfg.JobRegistry.LoadJob(ctx, jobID); err == nil {
+				progress = reloadedJob.Progress()
+			}
+			b.maybeCleanUpProtectedTimestamp(ctx, execCfg.DB, execCfg.ProtectedTimestampProvider,
+				progress.GetChangefeed().ProtectedTimestampRecord)
 			return nil
 		}
 		if !IsRetryableError(err) {
@@ -103,6 +103,7 @@ func TestChangefeedBasics(t *testing.T) {
 	t.Run(`sinkless`, sinklessTest(testFn))
 	t.Run(`enterprise`, enterpriseTest(testFn))
 	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+	t.Run(`webhook`, webhookTest(testFn))
 
 	// NB running TestChangefeedBasics, which includes a DELETE, with
 	// cloudStorageTest is a regression test for #36994.
@@ -150,6 +151,64 @@ func TestChangefeedDiff(t *testing.T) {
 		})
 	}
 
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+	t.Run(`webhook`, webhookTest(testFn))
+}
+
+func TestChangefeedFilterAndProjection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'zero', 0), (1, 'one', 10)`)
+
+		create := `CREATE CHANGEFEED FOR foo`
+		if strings.Contains(t.Name(), `sinkless`) {
+			create = `EXPERIMENTAL CHANGEFEED FOR foo`
+		}
+		sqlDB.ExpectErr(t, `filter and projection require exactly one target table`,
+			create+`, bar WITH filter = 'a > 0'`)
+		sqlDB.ExpectErr(t, `column "nope" does not exist`,
+			create+` WITH filter = 'nope > 0'`)
+		sqlDB.ExpectErr(t, `expected CHANGEFEED filter expression to have type bool`,
+			create+` WITH filter = 'c + 1'`)
+		sqlDB.ExpectErr(t, `volatile functions are not allowed in CHANGEFEED filter`,
+			create+` WITH filter = 'random() > 0.5'`)
+		sqlDB.ExpectErr(t, `column "nope" does not exist`,
+			create+` WITH projection = 'b, nope'`)
+		sqlDB.ExpectErr(t, `projection must be a list of column names: c \+ 1`,
+			create+` WITH projection = 'c + 1'`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH filter = 'foo.c >= 10', projection = 'B', diff`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"b": "one"}, "before": null}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'two', 20), (3, 'three', 3)`)
+		assertPayloads(t, foo, []string{
+			`foo: [2]->{"after": {"b": "two"}, "before": null}`,
+		})
+
+		// Rows leaving the filtered set are emitted too, since their previous
+		// value matches.
+		sqlDB.Exec(t, `UPDATE foo SET c = 1 WHERE a = 2`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 2 WHERE a = 3`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a IN (0, 1)`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 30 WHERE a = 3`)
+		assertPayloads(t, foo, []string{
+			`foo: [2]->{"after": {"b": "two"}, "before": {"b": "two"}}`,
+			`foo: [1]->{"after": null, "before": {"b": "one"}}`,
+			`foo: [3]->{"after": {"b": "three"}, "before": {"b": "three"}}`,
+		})
+	}
+
 	t.Run(`sinkless`, sinklessTest(testFn))
 	t.Run(`enterprise`, enterpriseTest(testFn))
 	t.Run(`cloudstorage`, cloudStorageTest(testFn))
@@ -237,6 +296,7 @@ func TestChangefeedMultiTable(t *testing.T) {
 
 	t.Run(`sinkless`, sinklessTest(testFn))
 	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`webhook`, webhookTest(testFn))
 }
 
 func TestChangefeedCursor(t *testing.T) {
@@ -388,6 +448,7 @@ func TestChangefeedResolvedFrequency(t *testing.T) {
 
 	t.Run(`sinkless`, sinklessTest(testFn))
 	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`webhook`, webhookTest(testFn))
 }
 
 // Test how Changefeeds react to schema changes that do not require a backfill
@@ -440,6 +501,42 @@ func TestChangefeedInitialScan(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedInitialScanOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1), (2)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan_only`)
+		defer closeFeed(t, foo)
+		// Changes after the statement time aren't emitted.
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3)`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1}}`,
+			`foo: [2]->{"after": {"a": 2}}`,
+		})
+		expectResolvedTimestamp(t, foo)
+
+		if foo, ok := foo.(cdctest.EnterpriseTestFeed); ok {
+			require.NoError(t, foo.WaitForStatus(func(s jobs.Status) bool {
+				return s == jobs.StatusSucceeded
+			}))
+		} else {
+			m, err := foo.Next()
+			require.NoError(t, err)
+			require.Nil(t, m, `expected the changefeed to end`)
+		}
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+	t.Run(`webhook`, webhookTest(testFn))
+}
+
 func TestChangefeedUserDefinedTypes(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
@@ -1076,9 +1173,9 @@ func TestChangefeedColumnFamily(t *testing.T) {
 		// Table with 2 column families.
 		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, FAMILY (a), FAMILY (b))`)
 		if strings.Contains(t.Name(), `enterprise`) {
-			sqlDB.ExpectErr(t, `exactly 1 column family`, `CREATE CHANGEFEED FOR foo`)
+			sqlDB.ExpectErr(t, `require the split_column_families option`, `CREATE CHANGEFEED FOR foo`)
 		} else {
-			sqlDB.ExpectErr(t, `exactly 1 column family`, `EXPERIMENTAL CHANGEFEED FOR foo`)
+			sqlDB.ExpectErr(t, `require the split_column_families option`, `EXPERIMENTAL CHANGEFEED FOR foo`)
 		}
 
 		// Table with a second column family added after the changefeed starts.
@@ -1091,8 +1188,8 @@ func TestChangefeedColumnFamily(t *testing.T) {
 		})
 		sqlDB.Exec(t, `ALTER TABLE bar ADD COLUMN b STRING CREATE FAMILY f_b`)
 		sqlDB.Exec(t, `INSERT INTO bar VALUES (1)`)
-		if _, err := bar.Next(); !testutils.IsError(err, `exactly 1 column family`) {
-			t.Errorf(`expected "exactly 1 column family" error got: %+v`, err)
+		if _, err := bar.Next(); !testutils.IsError(err, `require the split_column_families option`) {
+			t.Errorf(`expected "require the split_column_families option" error got: %+v`, err)
 		}
 	}
 
@@ -1100,6 +1197,71 @@ func TestChangefeedColumnFamily(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedSplitColumnFamilies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		// Changes which may delete the row are held back until they're resolved.
+		sqlDB.Exec(t, "SET CLUSTER SETTING kv.closed_timestamp.target_duration = '50ms'")
+		sqlDB.Exec(t, `CREATE TABLE foo (
+			a INT PRIMARY KEY, b STRING NOT NULL, c INT, FAMILY f_ab (a, b), FAMILY f_c (c)
+		)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'one', 10)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH split_column_families`)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo.f_ab: [1]->{"after": {"a": 1, "b": "one"}}`,
+			`foo.f_c: [1]->{"after": {"c": 10}}`,
+		})
+
+		// Only the changed family is emitted.
+		sqlDB.Exec(t, `UPDATE foo SET c = 11 WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo.f_c: [1]->{"after": {"c": 11}}`,
+		})
+
+		// Families added after the changefeed starts are emitted too.
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN d STRING CREATE FAMILY f_d`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'two', NULL, 'x')`)
+		assertPayloads(t, foo, []string{
+			`foo.f_ab: [2]->{"after": {"a": 2, "b": "two"}}`,
+			`foo.f_d: [2]->{"after": {"d": "x"}}`,
+		})
+
+		// Setting all the columns of a family to NULL removes its KV, but the row
+		// isn't deleted.
+		sqlDB.Exec(t, `UPDATE foo SET c = NULL WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo.f_c: [1]->{"after": {"c": null}}`,
+		})
+
+		// Deleting a row deletes each of its families.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo.f_ab: [1]->{"after": null}`,
+			`foo.f_c: [1]->{"after": null}`,
+		})
+
+		// With the diff option, a family which had no KV before the change only
+		// has no before value if the row didn't exist either.
+		diff := feed(t, f, `CREATE CHANGEFEED FOR foo WITH split_column_families, diff, no_initial_scan`)
+		defer closeFeed(t, diff)
+		sqlDB.Exec(t, `UPDATE foo SET c = 20 WHERE a = 2`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'three', 30, NULL)`)
+		assertPayloads(t, diff, []string{
+			`foo.f_c: [2]->{"after": {"c": 20}, "before": {"c": null}}`,
+			`foo.f_ab: [3]->{"after": {"a": 3, "b": "three"}, "before": null}`,
+			`foo.f_c: [3]->{"after": {"c": 30}, "before": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedStopOnSchemaChange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -2088,6 +2250,31 @@ func TestChangefeedErrors(t *testing.T) {
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, format='experimental_avro'`,
 		`kafka://nope`,
 	)
+	sqlDB.ExpectErr(
+		t, `split_column_families is not supported with format=experimental_avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH split_column_families, format='experimental_avro'`,
+		`kafka://nope`,
+	)
+
+	// Rows are only partially decoded when column families are split.
+	sqlDB.ExpectErr(
+		t, `filter is not supported with split_column_families`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH split_column_families, filter = 'a > 0'`,
+		`kafka://nope`,
+	)
+
+	// Manifests are only written by cloud storage sinks, at multiples of the
+	// resolved interval.
+	sqlDB.ExpectErr(
+		t, `exactly_once requires the resolved option with a positive interval`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH exactly_once, resolved`,
+		`experimental-nodelocal://0/bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `exactly_once is only supported by cloud storage sinks`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH exactly_once, resolved = '10s'`,
+		`kafka://nope`,
+	)
 
 	// The cloudStorageSink is particular about the options it will work with.
 	sqlDB.ExpectErr(
@@ -2130,6 +2317,10 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `cannot specify both initial_scan and no_initial_scan`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH no_initial_scan, initial_scan`, `kafka://nope`,
 	)
+	sqlDB.ExpectErr(
+		t, `cannot specify both initial_scan_only and no_initial_scan`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH initial_scan_only, no_initial_scan`, `kafka://nope`,
+	)
 }
 
 func TestChangefeedDescription(t *testing.T) {
@@ -2229,6 +2420,89 @@ func TestChangefeedPauseUnpause(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestAlterChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer jobs.TestingSetAdoptAndCancelIntervals(10*time.Millisecond, 10*time.Millisecond)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `CREATE TABLE baz (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a')`)
+		sqlDB.Exec(t, `INSERT INTO baz VALUES (1, 'a')`)
+
+		cf := feed(t, f, `CREATE CHANGEFEED FOR foo, baz WITH resolved`).(*cdctest.TableFeed)
+		defer closeFeed(t, cf)
+
+		assertPayloads(t, cf, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "a"}}`,
+			`baz: [1]->{"after": {"a": 1, "b": "a"}}`,
+		})
+		// Wait for the high-water mark on the job to be updated after the initial
+		// scan, so that bar can be added.
+		for {
+			m, err := cf.Next()
+			if err != nil {
+				t.Fatal(err)
+			} else if m.Key == nil {
+				break
+			}
+		}
+
+		sqlDB.ExpectErr(t, `is not paused`,
+			`ALTER CHANGEFEED $1 ADD bar`, cf.JobID)
+
+		sqlDB.Exec(t, `PAUSE JOB $1`, cf.JobID)
+		// PAUSE JOB only requests the job to be paused. Block until it's paused.
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t, `SELECT status FROM system.jobs WHERE id = $1`, cf.JobID).Scan(&status)
+			if jobs.Status(status) != jobs.StatusPaused {
+				return errors.New("could not pause job")
+			}
+			return nil
+		})
+
+		sqlDB.ExpectErr(t, `already watches table foo`,
+			`ALTER CHANGEFEED $1 ADD foo`, cf.JobID)
+		sqlDB.ExpectErr(t, `does not watch table bar`,
+			`ALTER CHANGEFEED $1 DROP bar`, cf.JobID)
+		sqlDB.ExpectErr(t, `cannot drop all targets`,
+			`ALTER CHANGEFEED $1 DROP foo, baz`, cf.JobID)
+		sqlDB.ExpectErr(t, `cannot alter option "cursor"`,
+			`ALTER CHANGEFEED $1 SET cursor = '1'`, cf.JobID)
+		sqlDB.ExpectErr(t, `cannot unset option "sink"`,
+			`ALTER CHANGEFEED $1 UNSET sink`, cf.JobID)
+
+		// Rows written to bar before it's added are emitted by its initial scan.
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (1, 'a'), (2, 'b')`)
+		sqlDB.Exec(t, `ALTER CHANGEFEED $1 ADD bar DROP baz SET diff`, cf.JobID)
+
+		var description string
+		sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, cf.JobID).Scan(&description)
+		require.Regexp(t, `CREATE CHANGEFEED FOR TABLE bar, foo INTO .* WITH diff`, description)
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'b')`)
+		sqlDB.Exec(t, `INSERT INTO baz VALUES (2, 'b')`)
+		sqlDB.Exec(t, `RESUME JOB $1`, cf.JobID)
+		assertPayloads(t, cf, []string{
+			`bar: [1]->{"after": {"a": 1, "b": "a"}, "before": null}`,
+			`bar: [2]->{"after": {"a": 2, "b": "b"}, "before": null}`,
+			`foo: [2]->{"after": {"a": 2, "b": "b"}, "before": null}`,
+		})
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (3, 'c')`)
+		assertPayloads(t, cf, []string{
+			`bar: [3]->{"after": {"a": 3, "b": "c"}, "before": null}`,
+		})
+	}
+
+	// Only the enterprise version uses jobs.
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedPauseUnpauseCursorAndInitialScan(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -2793,15 +3067,19 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 		// don't see how to do that without a refactor.
 		knobs.MemBufferCapacity = 20000
 		beforeEmitRowCh := make(chan struct{}, 1)
+		unblockEmitCh := make(chan struct{})
 		knobs.BeforeEmitRow = func(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			case <-beforeEmitRowCh:
+			case <-unblockEmitCh:
 			}
 			return nil
 		}
 		defer close(beforeEmitRowCh)
+		registry := f.Server().JobRegistry().(*jobs.Registry)
+		metrics := registry.MetricsStruct().Changefeed.(*Metrics)
 
 		sqlDB := sqlutils.MakeSQLRunner(db)
 		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
@@ -2816,11 +3094,25 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 			`foo: [0]->{"after": {"a": 0, "b": "small"}}`,
 		})
 
-		// Put enough data in to overflow the buffer and verify that at some point
-		// we get the "memory budget exceeded" error.
+		// Put in enough data to overflow the buffer many times over while the
+		// changefeed isn't emitting anything. The kvfeed blocks once the buffer is
+		// full instead of failing the changefeed.
 		sqlDB.Exec(t, `INSERT INTO foo SELECT i, 'foofoofoo' FROM generate_series(1, $1) AS g(i)`, 1000)
-		if _, err := foo.Next(); !testutils.IsError(err, `memory budget exceeded`) {
-			t.Fatalf(`expected "memory budget exceeded" error got: %v`, err)
+		testutils.SucceedsSoon(t, func() error {
+			if metrics.MemCurBytes.Value() == 0 {
+				return errors.New(`expected buffered events`)
+			}
+			return nil
+		})
+		require.LessOrEqual(t, metrics.MemCurBytes.Value(), knobs.MemBufferCapacity)
+
+		// Once the changefeed emits again, every row makes it through.
+		close(unblockEmitCh)
+		seen := make(map[string]struct{})
+		for len(seen) < 1000 {
+			for _, m := range readNextMessages(t, foo, 1, false /* stripTs */) {
+				seen[m] = struct{}{}
+			}
 		}
 	}
 
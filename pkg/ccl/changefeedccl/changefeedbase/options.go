@@ -41,6 +41,34 @@ const (
 	OptSchemaChangePolicy       = `schema_change_policy`
 	OptProtectDataFromGCOnPause = `protect_data_from_gc_on_pause`
 
+	// OptFilter is a SQL predicate over the columns of the watched table. Only
+	// row changes satisfying it are emitted. Deletions are emitted unless the
+	// previous value of the row is known (see OptDiff) and doesn't satisfy it.
+	OptFilter = `filter`
+	// OptProjection is a comma-separated list of the columns of the watched
+	// table to include in emitted values. The key always contains the full
+	// primary key.
+	OptProjection = `projection`
+	// OptTopicTemplate routes rows to kafka topics named by a template with
+	// `{database}`, `{schema}`, `{table}`, `{family}` and `{column:<name>}`
+	// placeholders. Resolved timestamps can't be emitted when the topic of a
+	// row depends on its family or column values.
+	OptTopicTemplate = `topic_template`
+	// OptPartitionKey is a comma-separated list of columns, each optionally
+	// qualified by a table name, whose values pick the kafka partition of a
+	// row instead of its primary key.
+	OptPartitionKey = `partition_key`
+	// OptSplitColumnFamilies allows tables with multiple column families to be
+	// watched. Each change to a family is emitted as its own message, whose
+	// value only contains the columns of that family and whose topic is
+	// qualified by the family name.
+	OptSplitColumnFamilies = `split_column_families`
+	// OptExactlyOnce makes cloud storage sinks commit the data files of each
+	// interval of the `resolved` option in a manifest file, so that consumers
+	// reading only the files listed in manifests see each row change exactly
+	// once, even across restarts of the changefeed.
+	OptExactlyOnce = `exactly_once`
+
 	// OptSchemaChangeEventClassColumnChange corresponds to all schema change
 	// events which add or remove any column.
 	OptSchemaChangeEventClassColumnChange SchemaChangeEventClass = `column_changes`
@@ -69,6 +97,10 @@ const (
 	// cursor is specified. This option is useful to create a changefeed which
 	// subscribes only to new messages.
 	OptNoInitialScan = `no_initial_scan`
+	// OptInitialScanOnly makes the changefeed stop once its initial scan is
+	// done. It emits a final resolved timestamp at the scan time and, if it has
+	// a job, marks the job succeeded.
+	OptInitialScanOnly = `initial_scan_only`
 
 	OptEnvelopeKeyOnly       EnvelopeType = `key_only`
 	OptEnvelopeRow           EnvelopeType = `row`
@@ -77,22 +109,29 @@ const (
 
 	OptFormatJSON FormatType = `json`
 	OptFormatAvro FormatType = `experimental_avro`
+	// OptFormatParquet writes one parquet file per flush of each table and
+	// schema version. It's only supported by cloud storage sinks.
+	OptFormatParquet FormatType = `parquet`
 
-	SinkParamCACert           = `ca_cert`
-	SinkParamClientCert       = `client_cert`
-	SinkParamClientKey        = `client_key`
-	SinkParamFileSize         = `file_size`
-	SinkParamSchemaTopic      = `schema_topic`
-	SinkParamTLSEnabled       = `tls_enabled`
-	SinkParamSkipTLSVerify    = `insecure_tls_skip_verify`
-	SinkParamTopicPrefix      = `topic_prefix`
-	SinkSchemeBuffer          = ``
-	SinkSchemeExperimentalSQL = `experimental-sql`
-	SinkSchemeKafka           = `kafka`
-	SinkParamSASLEnabled      = `sasl_enabled`
-	SinkParamSASLHandshake    = `sasl_handshake`
-	SinkParamSASLUser         = `sasl_user`
-	SinkParamSASLPassword     = `sasl_password`
+	SinkParamCACert               = `ca_cert`
+	SinkParamClientCert           = `client_cert`
+	SinkParamClientKey            = `client_key`
+	SinkParamFileSize             = `file_size`
+	SinkParamSchemaTopic          = `schema_topic`
+	SinkParamTLSEnabled           = `tls_enabled`
+	SinkParamSkipTLSVerify        = `insecure_tls_skip_verify`
+	SinkParamTopicPrefix          = `topic_prefix`
+	SinkSchemeBuffer              = ``
+	SinkSchemeExperimentalSQL     = `experimental-sql`
+	SinkSchemeKafka               = `kafka`
+	SinkSchemeWebhookHTTPS        = `webhook-https`
+	SinkParamSASLEnabled          = `sasl_enabled`
+	SinkParamSASLHandshake        = `sasl_handshake`
+	SinkParamSASLUser             = `sasl_user`
+	SinkParamSASLPassword         = `sasl_password`
+	SinkParamWebhookAuthHeader    = `webhook_auth_header`
+	SinkParamWebhookClientTimeout = `webhook_client_timeout`
+	SinkParamWebhookMaxBatchRows  = `webhook_max_batch_rows`
 )
 
 // ChangefeedOptionExpectValues is used to parse changefeed options using
@@ -113,5 +152,12 @@ var ChangefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	OptSchemaChangePolicy:       sql.KVStringOptRequireValue,
 	OptInitialScan:              sql.KVStringOptRequireNoValue,
 	OptNoInitialScan:            sql.KVStringOptRequireNoValue,
+	OptInitialScanOnly:          sql.KVStringOptRequireNoValue,
 	OptProtectDataFromGCOnPause: sql.KVStringOptRequireNoValue,
+	OptFilter:                   sql.KVStringOptRequireValue,
+	OptProjection:               sql.KVStringOptRequireValue,
+	OptTopicTemplate:            sql.KVStringOptRequireValue,
+	OptPartitionKey:             sql.KVStringOptRequireValue,
+	OptSplitColumnFamilies:      sql.KVStringOptRequireNoValue,
+	OptExactlyOnce:              sql.KVStringOptRequireNoValue,
 }
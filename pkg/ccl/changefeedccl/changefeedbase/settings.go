@@ -25,3 +25,13 @@ var TableDescriptorPollInterval = settings.RegisterDurationSetting(
 	1*time.Second,
 	settings.NonNegativeDuration,
 )
+
+// PerChangefeedMemLimit controls how much memory each changefeed may use to
+// buffer events. The memory is drawn from a budget shared by all changefeeds
+// on the node (see execinfra.SettingChangefeedMemBytes). Changefeeds which run out of memory stop consuming changes until
+// the buffered events are emitted.
+var PerChangefeedMemLimit = settings.RegisterByteSizeSetting(
+	"changefeed.memory.per_changefeed_limit",
+	"controls amount of data that can be buffered per changefeed",
+	1<<29, // 512MiB
+)
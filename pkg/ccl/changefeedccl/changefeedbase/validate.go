@@ -17,7 +17,11 @@ import (
 )
 
 // ValidateTable validates that a table descriptor can be watched by a CHANGEFEED.
-func ValidateTable(targets jobspb.ChangefeedTargets, tableDesc catalog.TableDescriptor) error {
+// Tables with more than one column family are only allowed if the changefeed
+// splits its messages by family (see OptSplitColumnFamilies).
+func ValidateTable(
+	targets jobspb.ChangefeedTargets, tableDesc catalog.TableDescriptor, splitColumnFamilies bool,
+) error {
 	t, ok := targets[tableDesc.GetID()]
 	if !ok {
 		return errors.Errorf(`unwatched table: %s`, tableDesc.GetName())
@@ -40,10 +44,10 @@ func ValidateTable(targets jobspb.ChangefeedTargets, tableDesc catalog.TableDesc
 	if tableDesc.IsSequence() {
 		return errors.Errorf(`CHANGEFEED cannot target sequences: %s`, tableDesc.GetName())
 	}
-	if len(tableDesc.GetFamilies()) != 1 {
+	if len(tableDesc.GetFamilies()) != 1 && !splitColumnFamilies {
 		return errors.Errorf(
-			`CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d`,
-			tableDesc.GetName(), len(tableDesc.GetFamilies()))
+			`CHANGEFEEDs on tables with more than 1 column family require the %s option: %s has %d`,
+			OptSplitColumnFamilies, tableDesc.GetName(), len(tableDesc.GetFamilies()))
 	}
 
 	if tableDesc.GetState() == descpb.DescriptorState_DROP {
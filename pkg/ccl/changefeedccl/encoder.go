@@ -54,6 +54,8 @@ type encodeRow struct {
 	// tableDesc is a TableDescriptor for the table containing `datums`.
 	// It's valid for interpreting the row at `updated`.
 	tableDesc catalog.TableDescriptor
+	// familyID is the column family of `tableDesc` which changed.
+	familyID descpb.FamilyID
 	// prevDatums is the old value of a changed table row. The field is set
 	// to nil if the before value for changes was not requested (OptDiff).
 	prevDatums rowenc.EncDatumRow
@@ -89,6 +91,8 @@ func getEncoder(opts map[string]string, targets jobspb.ChangefeedTargets) (Encod
 		return makeJSONEncoder(opts)
 	case changefeedbase.OptFormatAvro:
 		return newConfluentAvroEncoder(opts, targets)
+	case changefeedbase.OptFormatParquet:
+		return newParquetEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
 	}
@@ -100,6 +104,11 @@ func getEncoder(opts map[string]string, targets jobspb.ChangefeedTargets) (Encod
 // stored in a sub-object under the `__crdb__` key in the top-level JSON object.
 type jsonEncoder struct {
 	updatedField, beforeField, wrapped, keyOnly, keyInValue bool
+	// projection, if non-nil, is the set of column names to include in values.
+	projection map[string]struct{}
+	// splitFamilies is set if values only include the columns of the column
+	// family of each row.
+	splitFamilies bool
 
 	alloc rowenc.DatumAlloc
 	buf   bytes.Buffer
@@ -123,9 +132,48 @@ func makeJSONEncoder(opts map[string]string) (*jsonEncoder, error) {
 		return nil, errors.Errorf(`%s is only usable with %s=%s`,
 			changefeedbase.OptKeyInValue, changefeedbase.OptEnvelope, changefeedbase.OptEnvelopeWrapped)
 	}
+	_, e.splitFamilies = opts[changefeedbase.OptSplitColumnFamilies]
+	if projection, ok := opts[changefeedbase.OptProjection]; ok {
+		names, err := parseProjection(projection)
+		if err != nil {
+			return nil, err
+		}
+		e.projection = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			e.projection[name] = struct{}{}
+		}
+	}
 	return e, nil
 }
 
+// familyColumns returns the columns of the given column family of the table
+// if the encoder splits column families, and an empty set otherwise.
+func (e *jsonEncoder) familyColumns(
+	desc catalog.TableDescriptor, familyID descpb.FamilyID,
+) (catalog.TableColSet, error) {
+	if !e.splitFamilies {
+		return catalog.TableColSet{}, nil
+	}
+	family, err := desc.FindFamilyByID(familyID)
+	if err != nil {
+		return catalog.TableColSet{}, err
+	}
+	return catalog.MakeTableColSet(family.ColumnIDs...), nil
+}
+
+// included returns whether the column is included in encoded values, given
+// the columns returned by familyColumns for the row.
+func (e *jsonEncoder) included(col catalog.Column, familyCols catalog.TableColSet) bool {
+	if e.splitFamilies && !familyCols.Contains(col.GetID()) {
+		return false
+	}
+	if e.projection == nil {
+		return true
+	}
+	_, ok := e.projection[col.GetName()]
+	return ok
+}
+
 // EncodeKey implements the Encoder interface.
 func (e *jsonEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
 	jsonEntries, err := e.encodeKeyRaw(row)
@@ -173,9 +221,16 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 
 	var after map[string]interface{}
 	if !row.deleted {
+		familyCols, err := e.familyColumns(row.tableDesc, row.familyID)
+		if err != nil {
+			return nil, err
+		}
 		columns := row.tableDesc.PublicColumns()
 		after = make(map[string]interface{}, len(columns))
 		for i, col := range columns {
+			if !e.included(col, familyCols) {
+				continue
+			}
 			datum := row.datums[i]
 			if err := datum.EnsureDecoded(col.GetType(), &e.alloc); err != nil {
 				return nil, err
@@ -190,9 +245,16 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 
 	var before map[string]interface{}
 	if row.prevDatums != nil && !row.prevDeleted {
+		familyCols, err := e.familyColumns(row.prevTableDesc, row.familyID)
+		if err != nil {
+			return nil, err
+		}
 		columns := row.prevTableDesc.PublicColumns()
 		before = make(map[string]interface{}, len(columns))
 		for i, col := range columns {
+			if !e.included(col, familyCols) {
+				continue
+			}
 			datum := row.prevDatums[i]
 			if err := datum.EnsureDecoded(col.GetType(), &e.alloc); err != nil {
 				return nil, err
@@ -332,6 +394,12 @@ func newConfluentAvroEncoder(
 		return nil, errors.Errorf(`%s is not supported with %s=%s`,
 			changefeedbase.OptKeyInValue, changefeedbase.OptFormat, changefeedbase.OptFormatAvro)
 	}
+	for _, opt := range []string{changefeedbase.OptProjection, changefeedbase.OptSplitColumnFamilies} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf(`%s is not supported with %s=%s`,
+				opt, changefeedbase.OptFormat, changefeedbase.OptFormatAvro)
+		}
+	}
 
 	if len(e.registryURL) == 0 {
 		return nil, errors.Errorf(`WITH option %s is required for %s=%s`,
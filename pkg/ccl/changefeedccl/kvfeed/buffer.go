@@ -18,8 +18,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -195,13 +195,10 @@ func (b *chanBuffer) Get(ctx context.Context) (Event, error) {
 	}
 }
 
-// MemBufferDefaultCapacity is the default capacity for a memBuffer for a single
-// changefeed.
-//
-// TODO(dan): It would be better if all changefeeds shared a single capacity
-// that was given by the operater at startup, like we do for RocksDB and SQL.
-var MemBufferDefaultCapacity = envutil.EnvOrDefaultBytes(
-	"COCKROACH_CHANGEFEED_BUFFER_CAPACITY", 1<<30) // 1GB
+// memBufferRetryInterval is how often a memBuffer blocked on its memory budget
+// retries when none of its own entries are left to release. This happens when
+// the node-wide budget is in use by other changefeeds.
+const memBufferRetryInterval = 100 * time.Millisecond
 
 var memBufferColTypes = []*types.T{
 	types.Bytes, // KV.Key
@@ -214,8 +211,10 @@ var memBufferColTypes = []*types.T{
 }
 
 // memBuffer is an in-memory buffer for changed KV and Resolved timestamp
-// events. It's size is limited only by the BoundAccount passed to the
-// constructor. memBuffer is only for use with single-producer single-consumer.
+// events. It's size is limited by the BoundAccount passed to the constructor:
+// adding an entry blocks while the account is over budget, until the consumer
+// has made room by removing entries. memBuffer is only for use with
+// single-producer single-consumer.
 type memBuffer struct {
 	metrics *Metrics
 
@@ -226,6 +225,9 @@ type memBuffer struct {
 	// signalCh can be selected on to learn when an entry is written to
 	// mu.entries.
 	signalCh chan struct{}
+	// releasedCh can be selected on to learn when an entry is removed from
+	// mu.entries.
+	releasedCh chan struct{}
 
 	allocMu struct {
 		syncutil.Mutex
@@ -235,8 +237,9 @@ type memBuffer struct {
 
 func makeMemBuffer(acc mon.BoundAccount, metrics *Metrics) *memBuffer {
 	b := &memBuffer{
-		metrics:  metrics,
-		signalCh: make(chan struct{}, 1),
+		metrics:    metrics,
+		signalCh:   make(chan struct{}, 1),
+		releasedCh: make(chan struct{}, 1),
 	}
 	b.mu.entries.Init(acc, colinfo.ColTypeInfoFromColTypes(memBufferColTypes), 0 /* rowCapacity */)
 	return b
@@ -327,16 +330,53 @@ func (b *memBuffer) Get(ctx context.Context) (Event, error) {
 }
 
 func (b *memBuffer) addRow(ctx context.Context, row tree.Datums) error {
-	b.mu.Lock()
-	_, err := b.mu.entries.AddRow(ctx, row)
-	b.mu.Unlock()
+	var blockedSince time.Time
+	for {
+		b.mu.Lock()
+		_, err := b.mu.entries.AddRow(ctx, row)
+		b.mu.Unlock()
+		if err == nil {
+			break
+		}
+		if !sqlerrors.IsOutOfMemoryError(err) {
+			return err
+		}
+		// Either this changefeed or all the changefeeds on the node are over
+		// their memory budget. Rather than failing the changefeed, stop
+		// consuming changes until the budget frees up.
+		if blockedSince.IsZero() {
+			blockedSince = timeutil.Now()
+		}
+		if err := b.waitForRelease(ctx); err != nil {
+			return err
+		}
+	}
+	if !blockedSince.IsZero() {
+		b.metrics.BufferPushbackNanos.Inc(timeutil.Since(blockedSince).Nanoseconds())
+	}
 	b.metrics.BufferEntriesIn.Inc(1)
 	select {
 	case b.signalCh <- struct{}{}:
 	default:
 		// Already signaled, don't need to signal again.
 	}
-	return err
+	return nil
+}
+
+// waitForRelease blocks until an entry is removed from the buffer or
+// memBufferRetryInterval has passed, whichever comes first.
+func (b *memBuffer) waitForRelease(ctx context.Context) error {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(memBufferRetryInterval)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.releasedCh:
+	case <-timer.C:
+		timer.Read = true
+	}
+	return nil
 }
 
 func (b *memBuffer) getRow(ctx context.Context) (tree.Datums, error) {
@@ -346,10 +386,21 @@ func (b *memBuffer) getRow(ctx context.Context) (tree.Datums, error) {
 		if b.mu.entries.Len() > 0 {
 			row = b.mu.entries.At(0)
 			b.mu.entries.PopFirst(ctx)
+			if b.mu.entries.Len() == 0 {
+				// PopFirst only releases memory a chunk of rows at a time, so
+				// release the rest once the buffer is empty. Otherwise, a full
+				// buffer could be blocked forever on the unreleased part of a
+				// chunk.
+				b.mu.entries.Clear(ctx)
+			}
 		}
 		b.mu.Unlock()
 		if row != nil {
 			b.metrics.BufferEntriesOut.Inc(1)
+			select {
+			case b.releasedCh <- struct{}{}:
+			default:
+			}
 			return row, nil
 		}
 
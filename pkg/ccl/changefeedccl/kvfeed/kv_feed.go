@@ -51,12 +51,26 @@ type Config struct {
 	SchemaChangeEvents changefeedbase.SchemaChangeEventClass
 	SchemaChangePolicy changefeedbase.SchemaChangePolicy
 
+	// If true, tables with more than one column family may be watched, with
+	// changes to each family emitted as separate events.
+	SplitColumnFamilies bool
+
 	// If true, the feed will begin with a dump of data at exactly the
 	// InitialHighWater. This is a peculiar behavior. In general the
 	// InitialHighWater is a point in time at which all data is known to have
 	// been seen.
 	NeedsInitialScan bool
 
+	// InitialScanSpans, if non-empty, restricts the initial scan to these
+	// spans, which belong to targets added to the changefeed after it last
+	// checkpointed the InitialHighWater. Since events up to the InitialHighWater
+	// may already have been resolved, these spans are scanned right after it.
+	InitialScanSpans []roachpb.Span
+
+	// If true, the feed stops after the initial scan, if any, by resolving all
+	// of its spans at the InitialHighWater as a boundary.
+	InitialScanOnly bool
+
 	// InitialHighWater is the timestamp from which new events are guaranteed to
 	// be produced.
 	InitialHighWater hlc.Timestamp
@@ -94,8 +108,8 @@ func Run(ctx context.Context, cfg Config) error {
 	f := newKVFeed(
 		cfg.Sink, cfg.Spans,
 		cfg.SchemaChangeEvents, cfg.SchemaChangePolicy,
-		cfg.NeedsInitialScan, cfg.WithDiff,
-		cfg.InitialHighWater,
+		cfg.NeedsInitialScan, cfg.InitialScanOnly, cfg.WithDiff,
+		cfg.InitialHighWater, cfg.InitialScanSpans,
 		cfg.Codec,
 		sf, sc, pff, bf)
 	g.GoCtx(f.run)
@@ -108,10 +122,19 @@ func Run(ctx context.Context, cfg Config) error {
 		log.Infof(ctx, "stopping changefeed due to schema change at %v", scErr.ts)
 		<-ctx.Done()
 		err = nil
+	} else if errors.Is(err, errInitialScanDone) {
+		log.Infof(ctx, "stopping changefeed after initial scan")
+		<-ctx.Done()
+		err = nil
 	}
 	return err
 }
 
+// errInitialScanDone is a sentinel error to indicate to Run() that the feed
+// stopped because it only does an initial scan. Like
+// schemaChangeDetectedError, it's handled entirely in this package.
+var errInitialScanDone = errors.New("initial scan done")
+
 // schemaChangeDetectedError is a sentinel error to indicate to Run() that the
 // schema change is stopping due to a schema change. This is handy to trigger
 // the context group to stop; the error is handled entirely in this package.
@@ -132,7 +155,9 @@ type kvFeed struct {
 	spans               []roachpb.Span
 	withDiff            bool
 	withInitialBackfill bool
+	initialScanOnly     bool
 	initialHighWater    hlc.Timestamp
+	initialScanSpans    []roachpb.Span
 	sink                EventBufferWriter
 	codec               keys.SQLCodec
 
@@ -151,8 +176,9 @@ func newKVFeed(
 	spans []roachpb.Span,
 	schemaChangeEvents changefeedbase.SchemaChangeEventClass,
 	schemaChangePolicy changefeedbase.SchemaChangePolicy,
-	withInitialBackfill, withDiff bool,
+	withInitialBackfill, initialScanOnly, withDiff bool,
 	initialHighWater hlc.Timestamp,
+	initialScanSpans []roachpb.Span,
 	codec keys.SQLCodec,
 	tf schemaFeed,
 	sc kvScanner,
@@ -163,8 +189,10 @@ func newKVFeed(
 		sink:                sink,
 		spans:               spans,
 		withInitialBackfill: withInitialBackfill,
+		initialScanOnly:     initialScanOnly,
 		withDiff:            withDiff,
 		initialHighWater:    initialHighWater,
+		initialScanSpans:    initialScanSpans,
 		schemaChangeEvents:  schemaChangeEvents,
 		schemaChangePolicy:  schemaChangePolicy,
 		codec:               codec,
@@ -184,6 +212,17 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 		if err = f.scanIfShould(ctx, initialScan, highWater); err != nil {
 			return err
 		}
+		if f.initialScanOnly {
+			// Everything up to the high-water has been emitted, so resolve all
+			// of the spans there as a boundary to let the changeFrontier know
+			// that the changefeed is done.
+			for _, span := range f.spans {
+				if err := f.sink.AddResolved(ctx, span, highWater, true); err != nil {
+					return err
+				}
+			}
+			return errInitialScanDone
+		}
 		highWater, err = f.runUntilTableEvent(ctx, highWater)
 		if err != nil {
 			return err
@@ -219,7 +258,11 @@ func (f *kvFeed) scanIfShould(
 	// updates after that timestamp.
 	isInitialScan := initialScan && f.withInitialBackfill
 	var spansToBackfill []roachpb.Span
-	if isInitialScan {
+	if isInitialScan && len(f.initialScanSpans) > 0 {
+		// The other spans are already resolved at the high-water, so the rows of
+		// the added targets are emitted as of right after it.
+		spansToBackfill = f.initialScanSpans
+	} else if isInitialScan {
 		scanTime = highWater
 		spansToBackfill = f.spans
 	} else if len(events) > 0 {
@@ -418,5 +461,7 @@ func makeTablefeedConfig(cfg Config) schemafeed.Config {
 		LeaseManager:       cfg.LeaseMgr,
 		SchemaChangeEvents: cfg.SchemaChangeEvents,
 		InitialHighWater:   cfg.InitialHighWater,
+
+		SplitColumnFamilies: cfg.SplitColumnFamilies,
 	}
 }
@@ -76,10 +76,12 @@ func TestKVFeed(t *testing.T) {
 	type testCase struct {
 		name               string
 		needsInitialScan   bool
+		initialScanOnly    bool
 		withDiff           bool
 		schemaChangeEvents changefeedbase.SchemaChangeEventClass
 		schemaChangePolicy changefeedbase.SchemaChangePolicy
 		initialHighWater   hlc.Timestamp
+		initialScanSpans   []roachpb.Span
 		spans              []roachpb.Span
 		events             []roachpb.RangeFeedEvent
 
@@ -113,8 +115,8 @@ func TestKVFeed(t *testing.T) {
 		tf := newRawTableFeed(tc.descs, tc.initialHighWater)
 		f := newKVFeed(buf, tc.spans,
 			tc.schemaChangeEvents, tc.schemaChangePolicy,
-			tc.needsInitialScan, tc.withDiff,
-			tc.initialHighWater,
+			tc.needsInitialScan, tc.initialScanOnly, tc.withDiff,
+			tc.initialHighWater, tc.initialScanSpans,
 			keys.SystemSQLCodec,
 			&tf, sf, rangefeedFactory(ref.run), bufferFactory)
 		ctx, cancel := context.WithCancel(context.Background())
@@ -139,7 +141,7 @@ func TestKVFeed(t *testing.T) {
 			return nil
 		})
 		// Wait for the feed to fail rather than canceling it.
-		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop {
+		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop || tc.initialScanOnly {
 			testG.Go(func() error {
 				_ = g.Wait()
 				return nil
@@ -249,6 +251,48 @@ func TestKVFeed(t *testing.T) {
 			expEvents: 2,
 			expErrRE:  "schema change ...",
 		},
+		{
+			name:               "initial scan only",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialScanOnly:    true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+				tableSpan(43),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			// One boundary per span, and none of the rangefeed events.
+			expEvents: 2,
+			expErrRE:  "initial scan done",
+		},
+		{
+			name:               "initial scan of added spans",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialHighWater:   ts(2),
+			initialScanSpans: []roachpb.Span{
+				tableSpan(43),
+			},
+			spans: []roachpb.Span{
+				tableSpan(42),
+				tableSpan(43),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(43, "a", "b", ts(3)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2).Next(),
+			},
+			expEvents: 1,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			runTest(t, tc)
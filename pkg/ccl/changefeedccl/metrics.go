@@ -132,6 +132,18 @@ var (
 		Measurement: "Nanoseconds",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaChangefeedMemCurBytes = metric.Metadata{
+		Name:        "changefeed.mem.current",
+		Help:        "Current memory usage of events buffered by all feeds",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaChangefeedMemMaxBytes = metric.Metadata{
+		Name:        "changefeed.mem.max",
+		Help:        "Memory usage of events buffered per changefeed",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
 	metaChangefeedRunning = metric.Metadata{
 		Name:        "changefeed.running",
 		Help:        "Number of currently running changefeeds, including sinkless",
@@ -151,6 +163,10 @@ var (
 	}
 )
 
+// See pkg/sql/mem_metrics.go
+// log10int64times1000 = log10(math.MaxInt64) * 1000, rounded up somewhat
+const log10int64times1000 = 19 * 1000
+
 // Metrics are for production monitoring of changefeeds.
 type Metrics struct {
 	KVFeedMetrics   kvfeed.Metrics
@@ -165,6 +181,11 @@ type Metrics struct {
 	EmitNanos          *metric.Counter
 	FlushNanos         *metric.Counter
 
+	// MemCurBytes and MemMaxBytesHist track the memory used by the kvfeed of
+	// each changefeed to buffer events.
+	MemCurBytes     *metric.Gauge
+	MemMaxBytesHist *metric.Histogram
+
 	Running *metric.Gauge
 
 	mu struct {
@@ -192,7 +213,10 @@ func MakeMetrics(histogramWindow time.Duration) metric.Struct {
 		TableMetadataNanos: metric.NewCounter(metaChangefeedTableMetadataNanos),
 		EmitNanos:          metric.NewCounter(metaChangefeedEmitNanos),
 		FlushNanos:         metric.NewCounter(metaChangefeedFlushNanos),
-		Running:            metric.NewGauge(metaChangefeedRunning),
+		MemCurBytes:        metric.NewGauge(metaChangefeedMemCurBytes),
+		MemMaxBytesHist: metric.NewHistogram(
+			metaChangefeedMemMaxBytes, histogramWindow, log10int64times1000, 3),
+		Running: metric.NewGauge(metaChangefeedRunning),
 	}
 	m.mu.resolved = make(map[int]hlc.Timestamp)
 	m.mu.id = 1 // start the first id at 1 so we can detect initialization
@@ -0,0 +1,291 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+const (
+	// parquetEventTypeColName is the name of the column holding the kind of
+	// change (see parquetEventUpsert and parquetEventDelete) of each row.
+	parquetEventTypeColName = `__crdb__event_type`
+	// parquetUpdatedColName is the name of the column holding the updated
+	// timestamp of each row when the `updated` option is set.
+	parquetUpdatedColName = `__crdb__updated`
+
+	parquetEventUpsert = `upsert`
+	parquetEventDelete = `delete`
+
+	parquetCreator = `cockroachdb changefeed`
+)
+
+// parquetEncoder encodes changefeed entries for the cloud storage sink's
+// parquet format. Parquet files are columnar and typed, so unlike the other
+// encoders the value isn't a self-contained message: it's the row's datums
+// value-encoded in column order, prefixed with a byte marking deletions. The
+// sink decodes them using the table descriptor handed to EmitRow and writes
+// them into a file with a matching parquet schema.
+//
+// Keys and resolved timestamps are encoded as JSON.
+type parquetEncoder struct {
+	*jsonEncoder
+
+	alloc   rowenc.DatumAlloc
+	buf     []byte
+	scratch []byte
+}
+
+var _ Encoder = &parquetEncoder{}
+
+func newParquetEncoder(opts map[string]string) (*parquetEncoder, error) {
+	switch changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) {
+	case changefeedbase.OptEnvelopeWrapped:
+	default:
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope],
+			changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+	}
+	for _, opt := range []string{
+		changefeedbase.OptDiff, changefeedbase.OptProjection, changefeedbase.OptSplitColumnFamilies,
+	} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf(`%s is not supported with %s=%s`,
+				opt, changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+		}
+	}
+	jsonEncoder, err := makeJSONEncoder(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetEncoder{jsonEncoder: jsonEncoder}, nil
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *parquetEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	e.buf = e.buf[:0]
+	if row.deleted {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+	for i, col := range row.tableDesc.PublicColumns() {
+		datum := row.datums[i]
+		if err := datum.EnsureDecoded(col.GetType(), &e.alloc); err != nil {
+			return nil, err
+		}
+		var err error
+		e.buf, err = rowenc.EncodeTableValue(
+			e.buf, descpb.ColumnID(encoding.NoColumnID), datum.Datum, e.scratch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+// parquetFileWriter buffers the rows for one cloud storage sink file, all of
+// which are from the same version of one table.
+type parquetFileWriter struct {
+	fw      *goparquet.FileWriter
+	columns []catalog.Column
+	updated bool
+
+	alloc rowenc.DatumAlloc
+}
+
+func newParquetFileWriter(
+	w io.Writer, table catalog.TableDescriptor, updated bool, compression string,
+) (*parquetFileWriter, error) {
+	codec := parquet.CompressionCodec_UNCOMPRESSED
+	if compression == sinkCompressionGzip {
+		codec = parquet.CompressionCodec_GZIP
+	}
+	columns := table.PublicColumns()
+	fw := goparquet.NewFileWriter(w,
+		goparquet.WithCreator(parquetCreator),
+		goparquet.WithCompressionCodec(codec),
+	)
+	if err := fw.SetSchemaDefinition(parquetSchema(table.GetName(), columns, updated)); err != nil {
+		return nil, errors.Wrapf(err, `building parquet schema for table %s`, table.GetName())
+	}
+	return &parquetFileWriter{fw: fw, columns: columns, updated: updated}, nil
+}
+
+// parquetSchema returns the parquet schema of files containing the given
+// columns. Every column is optional, since deletions only carry the primary
+// key.
+func parquetSchema(
+	name string, columns []catalog.Column, updated bool,
+) *parquetschema.SchemaDefinition {
+	children := make([]*parquetschema.ColumnDefinition, 0, len(columns)+2)
+	for _, col := range columns {
+		elem := parquetSchemaElement(col.GetType())
+		elem.Name = col.GetName()
+		elem.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+		children = append(children, &parquetschema.ColumnDefinition{SchemaElement: elem})
+	}
+	metaCol := func(name string) *parquetschema.ColumnDefinition {
+		elem := parquetStringSchemaElement()
+		elem.Name = name
+		elem.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+		return &parquetschema.ColumnDefinition{SchemaElement: elem}
+	}
+	children = append(children, metaCol(parquetEventTypeColName))
+	if updated {
+		children = append(children, metaCol(parquetUpdatedColName))
+	}
+	numChildren := int32(len(children))
+	return parquetschema.SchemaDefinitionFromColumnDefinition(&parquetschema.ColumnDefinition{
+		Children: children,
+		SchemaElement: &parquet.SchemaElement{
+			Name:        name,
+			NumChildren: &numChildren,
+		},
+	})
+}
+
+// parquetSchemaElement returns the physical and logical parquet type used for
+// a column of the given type. Types without a close parquet equivalent are
+// written as strings, in the same format used by EXPORT.
+func parquetSchemaElement(typ *types.T) *parquet.SchemaElement {
+	switch typ.Family() {
+	case types.BoolFamily:
+		return &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BOOLEAN)}
+	case types.IntFamily:
+		logical := parquet.NewLogicalType()
+		logical.INTEGER = &parquet.IntType{BitWidth: 64, IsSigned: true}
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_INT64),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64),
+			LogicalType:   logical,
+		}
+	case types.FloatFamily:
+		return &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_DOUBLE)}
+	case types.BytesFamily:
+		return &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY)}
+	case types.TimestampFamily, types.TimestampTZFamily:
+		logical := parquet.NewLogicalType()
+		logical.TIMESTAMP = parquet.NewTimestampType()
+		logical.TIMESTAMP.IsAdjustedToUTC = typ.Family() == types.TimestampTZFamily
+		logical.TIMESTAMP.Unit = parquet.NewTimeUnit()
+		logical.TIMESTAMP.Unit.MICROS = parquet.NewMicroSeconds()
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_INT64),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS),
+			LogicalType:   logical,
+		}
+	case types.JsonFamily:
+		logical := parquet.NewLogicalType()
+		logical.JSON = parquet.NewJsonType()
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_JSON),
+			LogicalType:   logical,
+		}
+	default:
+		return parquetStringSchemaElement()
+	}
+}
+
+func parquetStringSchemaElement() *parquet.SchemaElement {
+	logical := parquet.NewLogicalType()
+	logical.STRING = parquet.NewStringType()
+	return &parquet.SchemaElement{
+		Type:          parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+		ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+		LogicalType:   logical,
+	}
+}
+
+// parquetValue converts a datum into the value the parquet writer expects
+// for a column of the type returned by parquetSchemaElement, or nil for NULL.
+func parquetValue(d tree.Datum) interface{} {
+	if d == tree.DNull {
+		return nil
+	}
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DBool:
+		return bool(*t)
+	case *tree.DInt:
+		return int64(*t)
+	case *tree.DFloat:
+		return float64(*t)
+	case *tree.DBytes:
+		return []byte(*t)
+	case *tree.DString:
+		return []byte(*t)
+	case *tree.DCollatedString:
+		return []byte(t.Contents)
+	case *tree.DTimestamp:
+		return timeutil.ToUnixMicros(t.Time)
+	case *tree.DTimestampTZ:
+		return timeutil.ToUnixMicros(t.Time)
+	default:
+		return []byte(tree.AsStringWithFlags(d, tree.FmtExport))
+	}
+}
+
+// addRow decodes a value produced by parquetEncoder and buffers it in the
+// file.
+func (w *parquetFileWriter) addRow(value []byte, updated hlc.Timestamp) error {
+	if len(value) == 0 {
+		return errors.AssertionFailedf(`missing parquet encoded value`)
+	}
+	event := parquetEventUpsert
+	if value[0] == 1 {
+		event = parquetEventDelete
+	}
+	b := value[1:]
+
+	row := make(map[string]interface{}, len(w.columns)+2)
+	for _, col := range w.columns {
+		var datum tree.Datum
+		var err error
+		if datum, b, err = rowenc.DecodeTableValue(&w.alloc, col.GetType(), b); err != nil {
+			return errors.Wrapf(err, `decoding column %s`, col.GetName())
+		}
+		if v := parquetValue(datum); v != nil {
+			row[col.GetName()] = v
+		}
+	}
+	if len(b) != 0 {
+		return errors.AssertionFailedf(`%d trailing bytes in parquet encoded value`, len(b))
+	}
+	row[parquetEventTypeColName] = []byte(event)
+	if w.updated {
+		row[parquetUpdatedColName] = []byte(updated.AsOfSystemTime())
+	}
+	return w.fw.AddData(row)
+}
+
+// size returns an estimate of the size of the file if it were closed now.
+func (w *parquetFileWriter) size() int64 {
+	return w.fw.CurrentFileSize() + w.fw.CurrentRowGroupSize()
+}
+
+// close writes any buffered rows and the parquet footer.
+func (w *parquetFileWriter) close() error {
+	return w.fw.Close()
+}
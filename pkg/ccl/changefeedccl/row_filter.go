@@ -0,0 +1,204 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// parseProjection parses the value of the `projection` option into a list of
+// column names.
+func parseProjection(projection string) ([]string, error) {
+	var names []string
+	for _, s := range strings.Split(projection, `,`) {
+		expr, err := parser.ParseExpr(strings.TrimSpace(s))
+		if err != nil {
+			return nil, errors.Wrapf(err, `parsing %s`, changefeedbase.OptProjection)
+		}
+		name, ok := expr.(*tree.UnresolvedName)
+		if !ok || name.NumParts != 1 || name.Star {
+			return nil, errors.Errorf(`%s must be a list of column names: %s`,
+				changefeedbase.OptProjection, tree.AsString(expr))
+		}
+		names = append(names, name.Parts[0])
+	}
+	return names, nil
+}
+
+// validateFilterAndProjection checks the `filter` and `projection` options, if
+// any, against the single table watched by the changefeed. On success, both
+// options are rewritten into a canonical form in opts.
+func validateFilterAndProjection(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	opts map[string]string,
+	targetDescs []catalog.Descriptor,
+) error {
+	filter, hasFilter := opts[changefeedbase.OptFilter]
+	projection, hasProjection := opts[changefeedbase.OptProjection]
+	if !hasFilter && !hasProjection {
+		return nil
+	}
+	var tables []catalog.TableDescriptor
+	for _, desc := range targetDescs {
+		if table, isTable := desc.(catalog.TableDescriptor); isTable {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) != 1 {
+		return errors.Errorf(`%s and %s require exactly one target table`,
+			changefeedbase.OptFilter, changefeedbase.OptProjection)
+	}
+	table := tables[0]
+
+	if hasFilter {
+		// Rows are decoded one column family at a time, so the columns the
+		// filter refers to may be missing.
+		if _, ok := opts[changefeedbase.OptSplitColumnFamilies]; ok {
+			return errors.Errorf(`%s is not supported with %s`,
+				changefeedbase.OptFilter, changefeedbase.OptSplitColumnFamilies)
+		}
+		expr, err := parser.ParseExpr(filter)
+		if err != nil {
+			return errors.Wrapf(err, `parsing %s`, changefeedbase.OptFilter)
+		}
+		tn := tree.MakeUnqualifiedTableName(tree.Name(table.GetName()))
+		// The filter is evaluated against every version of the row, long after
+		// the statement has finished, so only allow immutable expressions.
+		serialized, _, err := schemaexpr.DequalifyAndValidateExpr(
+			ctx, table, expr, types.Bool, `CHANGEFEED filter`, semaCtx, tree.VolatilityImmutable, &tn,
+		)
+		if err != nil {
+			return err
+		}
+		opts[changefeedbase.OptFilter] = serialized
+	}
+
+	if hasProjection {
+		names, err := parseProjection(projection)
+		if err != nil {
+			return err
+		}
+		var buf strings.Builder
+		for i, name := range names {
+			col, err := table.FindColumnWithName(tree.Name(name))
+			if err != nil {
+				return err
+			}
+			if !col.Public() {
+				return errors.Errorf(`column %q does not exist`, name)
+			}
+			if i > 0 {
+				buf.WriteString(`, `)
+			}
+			buf.WriteString(col.ColName().String())
+		}
+		opts[changefeedbase.OptProjection] = buf.String()
+	}
+	return nil
+}
+
+// rowFilter evaluates the `filter` option against decoded rows. The predicate
+// is resolved against each version of the table descriptor it sees, because
+// schema changes can add or reorder the columns in decoded rows.
+type rowFilter struct {
+	expr    tree.Expr
+	evalCtx *tree.EvalContext
+	cache   map[tableIDAndVersion]*resolvedRowFilter
+
+	alloc rowenc.DatumAlloc
+}
+
+// resolvedRowFilter is the filter predicate resolved against one version of a
+// table descriptor.
+type resolvedRowFilter struct {
+	expr  tree.TypedExpr
+	ivars schemaexpr.RowIndexedVarContainer
+}
+
+func newRowFilter(filter string, evalCtx *tree.EvalContext) (*rowFilter, error) {
+	expr, err := parser.ParseExpr(filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, `parsing %s`, changefeedbase.OptFilter)
+	}
+	return &rowFilter{
+		expr:    expr,
+		evalCtx: evalCtx,
+		cache:   make(map[tableIDAndVersion]*resolvedRowFilter),
+	}, nil
+}
+
+// matchesEvent returns whether the row change should be emitted. A change
+// matches if either the new value of the row or, when known, its previous
+// value satisfies the predicate, so that consumers also see rows leaving the
+// filtered set. Deletions only carry the primary key, so they always match
+// unless the previous value is known.
+func (f *rowFilter) matchesEvent(ctx context.Context, r encodeRow) (bool, error) {
+	if !r.deleted {
+		if matches, err := f.matches(ctx, r.tableDesc, r.datums); err != nil || matches {
+			return matches, err
+		}
+	}
+	if r.prevDatums != nil {
+		if r.prevDeleted {
+			return false, nil
+		}
+		return f.matches(ctx, r.prevTableDesc, r.prevDatums)
+	}
+	return r.deleted, nil
+}
+
+func (f *rowFilter) matches(
+	ctx context.Context, desc catalog.TableDescriptor, datums rowenc.EncDatumRow,
+) (bool, error) {
+	columns := desc.PublicColumns()
+	cacheKey := makeTableIDAndVersion(desc.GetID(), desc.GetVersion())
+	resolved, ok := f.cache[cacheKey]
+	if !ok {
+		cols := make([]descpb.ColumnDescriptor, len(columns))
+		for i, col := range columns {
+			cols[i] = *col.ColumnDesc()
+		}
+		semaCtx := tree.MakeSemaContext()
+		expr, err := schemaexpr.MakeFilterExpr(ctx, f.expr, cols, desc, f.evalCtx, &semaCtx)
+		if err != nil {
+			return false, errors.Wrapf(err, `resolving %s for table %s version %d`,
+				changefeedbase.OptFilter, desc.GetName(), desc.GetVersion())
+		}
+		resolved = &resolvedRowFilter{
+			expr: expr,
+			ivars: schemaexpr.RowIndexedVarContainer{
+				CurSourceRow: make(tree.Datums, len(columns)),
+				Cols:         cols,
+				Mapping:      catalog.ColumnIDToOrdinalMap(columns),
+			},
+		}
+		f.cache[cacheKey] = resolved
+	}
+
+	for i, col := range columns {
+		if err := datums[i].EnsureDecoded(col.GetType(), &f.alloc); err != nil {
+			return false, err
+		}
+		resolved.ivars.CurSourceRow[i] = datums[i].Datum
+	}
+	f.evalCtx.IVarContainer = &resolved.ivars
+	return schemaexpr.RunFilter(resolved.expr, f.evalCtx)
+}
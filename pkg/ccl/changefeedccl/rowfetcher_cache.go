@@ -138,6 +138,20 @@ func (c *rowFetcherCache) TableDescForKey(
 	return tableDesc, nil
 }
 
+// familyIDForKey returns the column family of the primary index KV with the
+// given key.
+func familyIDForKey(key roachpb.Key) (descpb.FamilyID, error) {
+	n, err := keys.GetRowPrefixLength(key)
+	if err != nil {
+		return 0, err
+	}
+	_, familyID, err := encoding.DecodeUvarintAscending(key[n:])
+	if err != nil {
+		return 0, err
+	}
+	return descpb.FamilyID(familyID), nil
+}
+
 func (c *rowFetcherCache) RowFetcherForTableDesc(
 	tableDesc catalog.TableDescriptor,
 ) (*row.Fetcher, error) {
@@ -185,6 +199,10 @@ func (c *rowFetcherCache) RowFetcherForTableDesc(
 	); err != nil {
 		return nil, err
 	}
+	// Changes to tables with multiple column families are decoded one family
+	// at a time, so the columns of every other family are missing, even if
+	// they're non-nullable.
+	rf.IgnoreUnexpectedNulls = true
 	// TODO(dan): Bound the size of the cache. Resolved notifications will let
 	// us evict anything for timestamps entirely before the notification. Then
 	// probably an LRU just in case?
@@ -61,6 +61,10 @@ type Config struct {
 	// SchemaFeed.
 	SchemaChangeEvents changefeedbase.SchemaChangeEventClass
 
+	// SplitColumnFamilies is set if the changefeed emits one message per column
+	// family, in which case tables may have more than one family.
+	SplitColumnFamilies bool
+
 	// InitialHighWater is the timestamp after which events should occur.
 	//
 	// NB: When clients want to create a changefeed which has a resolved timestamp
@@ -96,7 +100,10 @@ type SchemaFeed struct {
 	settings *cluster.Settings
 	targets  jobspb.ChangefeedTargets
 	leaseMgr *lease.Manager
-	mu       struct {
+
+	splitColumnFamilies bool
+
+	mu struct {
 		syncutil.Mutex
 
 		started bool
@@ -198,6 +205,8 @@ func New(cfg Config) *SchemaFeed {
 		settings: cfg.Settings,
 		targets:  cfg.Targets,
 		leaseMgr: cfg.LeaseManager,
+
+		splitColumnFamilies: cfg.SplitColumnFamilies,
 	}
 	m.mu.previousTableVersion = make(map[descpb.ID]catalog.TableDescriptor)
 	m.mu.highWater = cfg.InitialHighWater
@@ -488,7 +497,7 @@ func (tf *SchemaFeed) validateDescriptor(
 		// manager to acquire the freshest version of the type.
 		return tf.leaseMgr.AcquireFreshestFromStore(ctx, desc.ID)
 	case catalog.TableDescriptor:
-		if err := changefeedbase.ValidateTable(tf.targets, desc); err != nil {
+		if err := changefeedbase.ValidateTable(tf.targets, desc, tf.splitColumnFamilies); err != nil {
 			return err
 		}
 		log.Infof(ctx, "validate %v", formatDesc(desc))
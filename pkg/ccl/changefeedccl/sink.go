@@ -82,6 +82,23 @@ func getSink(
 	}
 	q := u.Query()
 
+	if changefeedbase.FormatType(opts[changefeedbase.OptFormat]) == changefeedbase.OptFormatParquet &&
+		!isCloudStorageSink(u) {
+		return nil, errors.Errorf(`%s=%s is only supported by cloud storage sinks`,
+			changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+	}
+	if _, ok := opts[changefeedbase.OptExactlyOnce]; ok && !isCloudStorageSink(u) {
+		return nil, errors.Errorf(`%s is only supported by cloud storage sinks`,
+			changefeedbase.OptExactlyOnce)
+	}
+	if u.Scheme != changefeedbase.SinkSchemeKafka {
+		for _, opt := range []string{changefeedbase.OptTopicTemplate, changefeedbase.OptPartitionKey} {
+			if _, ok := opts[opt]; ok {
+				return nil, errors.Errorf(`%s is only supported by kafka sinks`, opt)
+			}
+		}
+	}
+
 	// Use a function here to delay creation of the sink until after we've done
 	// all the parameter verification.
 	var makeSink func() (Sink, error)
@@ -92,6 +109,16 @@ func getSink(
 		var cfg kafkaSinkConfig
 		cfg.kafkaTopicPrefix = q.Get(changefeedbase.SinkParamTopicPrefix)
 		q.Del(changefeedbase.SinkParamTopicPrefix)
+		if cfg.topicNamer, err = makeTopicNamer(opts, targets); err != nil {
+			return nil, err
+		}
+		if _, ok := opts[changefeedbase.OptResolvedTimestamps]; ok &&
+			cfg.topicNamer != nil && !cfg.topicNamer.isStatic() {
+			// Resolved timestamps are emitted to every topic, which aren't known
+			// ahead of time in this case.
+			return nil, errors.Errorf(`%s is not supported when topics depend on column families or values`,
+				changefeedbase.OptResolvedTimestamps)
+		}
 		if schemaTopic := q.Get(changefeedbase.SinkParamSchemaTopic); schemaTopic != `` {
 			return nil, errors.Errorf(`%s is not yet supported`, changefeedbase.SinkParamSchemaTopic)
 		}
@@ -200,6 +227,65 @@ func getSink(
 				opts, timestampOracle, makeExternalStorageFromURI, user,
 			)
 		}
+	case isWebhookSink(u):
+		switch format := changefeedbase.FormatType(opts[changefeedbase.OptFormat]); format {
+		case ``, changefeedbase.OptFormatJSON:
+		default:
+			return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+				changefeedbase.OptFormat, format)
+		}
+		cfg := webhookSinkConfig{retryOpts: defaultWebhookRetryOptions()}
+		if tlsVerifyBool := q.Get(changefeedbase.SinkParamSkipTLSVerify); tlsVerifyBool != `` {
+			if cfg.tlsSkipVerify, err = strconv.ParseBool(tlsVerifyBool); err != nil {
+				return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamSkipTLSVerify, err)
+			}
+		}
+		q.Del(changefeedbase.SinkParamSkipTLSVerify)
+		if caCertHex := q.Get(changefeedbase.SinkParamCACert); caCertHex != `` {
+			if cfg.caCert, err = base64.StdEncoding.DecodeString(caCertHex); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamCACert, err)
+			}
+		}
+		q.Del(changefeedbase.SinkParamCACert)
+		if clientCertHex := q.Get(changefeedbase.SinkParamClientCert); clientCertHex != `` {
+			if cfg.clientCert, err = base64.StdEncoding.DecodeString(clientCertHex); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientCert, err)
+			}
+		}
+		q.Del(changefeedbase.SinkParamClientCert)
+		if clientKeyHex := q.Get(changefeedbase.SinkParamClientKey); clientKeyHex != `` {
+			if cfg.clientKey, err = base64.StdEncoding.DecodeString(clientKeyHex); err != nil {
+				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientKey, err)
+			}
+		}
+		q.Del(changefeedbase.SinkParamClientKey)
+		cfg.authHeader = q.Get(changefeedbase.SinkParamWebhookAuthHeader)
+		q.Del(changefeedbase.SinkParamWebhookAuthHeader)
+		if timeout := q.Get(changefeedbase.SinkParamWebhookClientTimeout); timeout != `` {
+			if cfg.clientTimeout, err = time.ParseDuration(timeout); err != nil {
+				return nil, errors.Errorf(`param %s must be a duration: %s`, changefeedbase.SinkParamWebhookClientTimeout, err)
+			}
+			if cfg.clientTimeout <= 0 {
+				return nil, errors.Errorf(`param %s must be positive`, changefeedbase.SinkParamWebhookClientTimeout)
+			}
+		}
+		q.Del(changefeedbase.SinkParamWebhookClientTimeout)
+		if batchRows := q.Get(changefeedbase.SinkParamWebhookMaxBatchRows); batchRows != `` {
+			if cfg.maxBatchRows, err = strconv.Atoi(batchRows); err != nil {
+				return nil, errors.Errorf(`param %s must be an integer: %s`, changefeedbase.SinkParamWebhookMaxBatchRows, err)
+			}
+			if cfg.maxBatchRows <= 0 {
+				return nil, errors.Errorf(`param %s must be positive`, changefeedbase.SinkParamWebhookMaxBatchRows)
+			}
+		}
+		q.Del(changefeedbase.SinkParamWebhookMaxBatchRows)
+		// Every remaining query parameter is validated below, so strip them all
+		// from the URL that is actually POSTed to.
+		dest := *u
+		dest.RawQuery = ``
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(cfg, &dest, targets)
+		}
 	case u.Scheme == changefeedbase.SinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
 		// expects.
@@ -306,6 +392,9 @@ type kafkaSinkConfig struct {
 	saslUser         string
 	saslPassword     string
 	targetNames      map[descpb.ID]string
+	// topicNamer, if non-nil, routes rows according to the topic_template and
+	// partition_key options.
+	topicNamer *topicNamer
 }
 
 // kafkaSink emits to Kafka asynchronously. It is not concurrency-safe; all
@@ -315,6 +404,9 @@ type kafkaSink struct {
 	client   sarama.Client
 	producer sarama.AsyncProducer
 	topics   map[string]struct{}
+	// dynamicTopics is set if topics aren't known until rows are emitted to
+	// them, in which case they're added to topics by EmitRow.
+	dynamicTopics bool
 
 	lastMetadataRefresh time.Time
 
@@ -334,9 +426,16 @@ type kafkaSink struct {
 func (s *kafkaSink) setTargets(targets jobspb.ChangefeedTargets) {
 	s.topics = make(map[string]struct{})
 	s.cfg.targetNames = make(map[descpb.ID]string)
+	s.dynamicTopics = s.cfg.topicNamer != nil && !s.cfg.topicNamer.isStatic()
 	for id, t := range targets {
 		s.cfg.targetNames[id] = t.StatementTimeName
-		s.topics[s.cfg.kafkaTopicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
+		switch {
+		case s.dynamicTopics:
+		case s.cfg.topicNamer != nil:
+			s.topics[s.cfg.kafkaTopicPrefix+s.cfg.topicNamer.staticTopic(id)] = struct{}{}
+		default:
+			s.topics[s.cfg.kafkaTopicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
+		}
 	}
 }
 
@@ -474,6 +573,13 @@ func (s *kafkaSink) EmitRow(
 	ctx context.Context, table catalog.TableDescriptor, key, value []byte, updated hlc.Timestamp,
 ) error {
 	topic := s.cfg.kafkaTopicPrefix + SQLNameToKafkaName(s.cfg.targetNames[table.GetID()])
+	var partitionKey []byte
+	if routed, ok := table.(*routedTable); ok {
+		topic, partitionKey = s.cfg.kafkaTopicPrefix+routed.topic, routed.partitionKey
+		if s.dynamicTopics {
+			s.topics[topic] = struct{}{}
+		}
+	}
 	if _, ok := s.topics[topic]; !ok {
 		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
 	}
@@ -483,6 +589,9 @@ func (s *kafkaSink) EmitRow(
 		Key:   sarama.ByteEncoder(key),
 		Value: sarama.ByteEncoder(value),
 	}
+	if partitionKey != nil {
+		msg.Metadata = kafkaPartitionKey(partitionKey)
+	}
 	return s.emitMessage(ctx, msg)
 }
 
@@ -629,10 +738,20 @@ func newChangefeedPartitioner(topic string) sarama.Partitioner {
 	}
 }
 
+// kafkaPartitionKey is set as the Metadata of messages which are partitioned
+// by something other than their key (see the partition_key option).
+type kafkaPartitionKey []byte
+
 func (p *changefeedPartitioner) RequiresConsistency() bool { return true }
 func (p *changefeedPartitioner) Partition(
 	message *sarama.ProducerMessage, numPartitions int32,
 ) (int32, error) {
+	if partitionKey, ok := message.Metadata.(kafkaPartitionKey); ok {
+		return p.hash.Partition(&sarama.ProducerMessage{
+			Topic: message.Topic,
+			Key:   sarama.ByteEncoder(partitionKey),
+		}, numPartitions)
+	}
 	if message.Key == nil {
 		return message.Partition, nil
 	}
@@ -715,6 +834,7 @@ func (s *sqlSink) EmitRow(
 	if _, ok := s.topics[topic]; !ok {
 		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
 	}
+	topic = familyQualifiedTopic(topic, table)
 
 	// Hashing logic copied from sarama.HashPartitioner.
 	s.hasher.Reset()
@@ -828,7 +948,7 @@ func (s *bufferSink) EmitRow(
 	if s.closed {
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
-	topic := table.GetName()
+	topic := familyQualifiedTopic(table.GetName(), table)
 	s.buf.Push(rowenc.EncDatumRow{
 		{Datum: tree.DNull}, // resolved span
 		{Datum: s.alloc.NewDString(tree.DString(topic))}, // topic
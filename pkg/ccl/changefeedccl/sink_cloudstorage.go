@@ -12,12 +12,17 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	gojson "encoding/json"
 	"fmt"
 	"io"
 	"net/url"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
@@ -25,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -52,11 +58,38 @@ func cloudStorageFormatTime(ts hlc.Timestamp) string {
 	return fmt.Sprintf(`%s%09d%010d`, t.Format(f), t.Nanosecond(), ts.Logical)
 }
 
+// parseCloudStorageFormatTime is the inverse of cloudStorageFormatTime.
+func parseCloudStorageFormatTime(s string) (hlc.Timestamp, error) {
+	const f = `20060102150405`
+	if len(s) != len(f)+9+10 {
+		return hlc.Timestamp{}, errors.Errorf(`malformed timestamp: %q`, s)
+	}
+	t, err := time.Parse(f, s[:len(f)])
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrapf(err, `malformed timestamp: %q`, s)
+	}
+	nanos, err := strconv.ParseInt(s[len(f):len(f)+9], 10, 64)
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrapf(err, `malformed timestamp: %q`, s)
+	}
+	logical, err := strconv.ParseInt(s[len(f)+9:], 10, 32)
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrapf(err, `malformed timestamp: %q`, s)
+	}
+	return hlc.Timestamp{WallTime: t.UnixNano() + nanos, Logical: int32(logical)}, nil
+}
+
 type cloudStorageSinkFile struct {
 	cloudStorageSinkKey
 	codec   io.WriteCloser
 	rawSize int
 	buf     bytes.Buffer
+	// parquet, if non-nil, encodes the rows of a format=parquet file into buf.
+	parquet *parquetFileWriter
+	// lowerBound is the timestamp used to name the file in exactly-once mode.
+	// It's an inclusive lower bound on the timestamps of its rows, all of which
+	// are within the manifest interval of the file's key.
+	lowerBound hlc.Timestamp
 }
 
 var _ io.Writer = &cloudStorageSinkFile{}
@@ -69,6 +102,14 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 	return f.buf.Write(p)
 }
 
+// size returns the number of bytes buffered for the file.
+func (f *cloudStorageSinkFile) size() int64 {
+	if f.parquet != nil {
+		return f.parquet.size()
+	}
+	return int64(f.buf.Len())
+}
+
 // cloudStorageSink writes changefeed output to files in a cloud storage bucket
 // (S3/GCS/HTTP) maintaining CDC's ordering guarantees (see below) for each
 // row through lexicographical filename ordering.
@@ -147,7 +188,9 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 // if `Flush()` hasn't been called yet). Intuitively, this can be thought of as an
 // inclusive lower bound on the timestamps of updates that can be seen in a given file.
 //
-// `<topic>` corresponds to one SQL table.
+// `<topic>` corresponds to one SQL table, or to one of its column families if
+// the `split_column_families` option is set, in which case it's the table name
+// followed by a `.` and the family name.
 //
 // `<schema_id>` changes whenever the SQL table schema changes, which allows us
 // to guarantee to users that _all entries in a given file have the same
@@ -186,6 +229,18 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 // deleted, included in hive queries, etc). A typical user of cloudStorageSink
 // would periodically do exactly this.
 //
+// With the `exactly_once` option, no resolved timestamp files are written.
+// Instead, at each multiple of the `resolved` interval, a `<timestamp>.MANIFEST`
+// file lists the data files which contain the row changes since the previous
+// manifest. Consumers which only read the files listed in manifests, in the
+// order of the manifests, see each row change exactly once. Other data files
+// were written by interrupted job sessions and are to be ignored. Each
+// session of the job names its data files with its own session ID, so a
+// session only ever commits the files it wrote itself. In this mode, each data
+// file only contains rows from one manifest interval, and its
+// `<timestamp>` is an inclusive lower bound on their timestamps. See
+// writeManifest for details.
+//
 // Still TODO is writing out data schemas, Avro support, bounding memory usage.
 //
 // Now what follows is a proof of why the above is correct even in the presence
@@ -274,6 +329,11 @@ type cloudStorageSink struct {
 
 	ext           string
 	recordDelimFn func(io.Writer) error
+	// parquet is set for format=parquet, in which case rows are written by a
+	// parquetFileWriter instead of being delimited by recordDelimFn.
+	parquet bool
+	// updated is whether parquet files include the updated timestamp column.
+	updated bool
 
 	compression string
 
@@ -292,6 +352,17 @@ type cloudStorageSink struct {
 	dataFileTs        string
 	dataFilePartition string
 	prevFilename      string
+
+	// exactlyOnce is set by the `exactly_once` option, in which case data files
+	// are only committed once they're listed in a manifest, see writeManifest.
+	// Manifests are written every manifestInterval, and each data file only
+	// contains rows from one such interval.
+	exactlyOnce      bool
+	manifestInterval time.Duration
+	// lastManifest is the timestamp of the latest manifest, which all data files
+	// written at or before it have been committed by. It's only maintained by
+	// the sink of the changeFrontier.
+	lastManifest hlc.Timestamp
 }
 
 const sinkCompressionGzip = "gzip"
@@ -339,6 +410,10 @@ func makeCloudStorageSink(
 			_, err := w.Write([]byte{'\n'})
 			return err
 		}
+	case changefeedbase.OptFormatParquet:
+		s.ext = `.parquet`
+		s.parquet = true
+		_, s.updated = opts[changefeedbase.OptUpdatedTimestamps]
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
@@ -358,12 +433,28 @@ func makeCloudStorageSink(
 	if codec, ok := opts[changefeedbase.OptCompression]; ok && codec != "" {
 		if strings.EqualFold(codec, "gzip") {
 			s.compression = sinkCompressionGzip
-			s.ext = s.ext + ".gz"
+			// Parquet compresses its column chunks itself, so the files remain
+			// valid parquet files.
+			if !s.parquet {
+				s.ext = s.ext + ".gz"
+			}
 		} else {
 			return nil, errors.Errorf(`unsupported compression codec %q`, codec)
 		}
 	}
 
+	if _, ok := opts[changefeedbase.OptExactlyOnce]; ok {
+		s.exactlyOnce = true
+		var err error
+		if s.manifestInterval, err = time.ParseDuration(opts[changefeedbase.OptResolvedTimestamps]); err != nil {
+			return nil, err
+		}
+		if s.manifestInterval <= 0 {
+			return nil, errors.Errorf(`%s requires the %s option with a positive interval`,
+				changefeedbase.OptExactlyOnce, changefeedbase.OptResolvedTimestamps)
+		}
+	}
+
 	var err error
 	if s.es, err = makeExternalStorageFromURI(ctx, baseURI, user); err != nil {
 		return nil, err
@@ -373,21 +464,44 @@ func makeCloudStorageSink(
 }
 
 func (s *cloudStorageSink) getOrCreateFile(
-	topic string, schemaID descpb.DescriptorVersion,
-) *cloudStorageSinkFile {
-	key := cloudStorageSinkKey{topic, schemaID}
+	table catalog.TableDescriptor, updated hlc.Timestamp,
+) (*cloudStorageSinkFile, error) {
+	key := cloudStorageSinkKey{
+		topic:    familyQualifiedTopic(table.GetName(), table),
+		schemaID: table.GetVersion(),
+	}
+	if s.exactlyOnce {
+		key.interval = manifestIntervalStart(updated, s.manifestInterval)
+	}
 	if item := s.files.Get(key); item != nil {
-		return item.(*cloudStorageSinkFile)
+		return item.(*cloudStorageSinkFile), nil
 	}
 	f := &cloudStorageSinkFile{
 		cloudStorageSinkKey: key,
 	}
-	switch s.compression {
-	case sinkCompressionGzip:
-		f.codec = gzip.NewWriter(&f.buf)
+	if s.exactlyOnce {
+		// The start of the interval is exclusive. Rows from before the changefeed
+		// started (or resumed) are never emitted, so files are also named after
+		// the local frontier, which keeps them from being named at or before the
+		// changefeed's initial high-water.
+		f.lowerBound = key.interval.Next()
+		if s.timestampOracle != nil {
+			f.lowerBound.Forward(s.timestampOracle.inclusiveLowerBoundTS())
+		}
+	}
+	if s.parquet {
+		var err error
+		if f.parquet, err = newParquetFileWriter(&f.buf, table, s.updated, s.compression); err != nil {
+			return nil, err
+		}
+	} else {
+		switch s.compression {
+		case sinkCompressionGzip:
+			f.codec = gzip.NewWriter(&f.buf)
+		}
 	}
 	s.files.ReplaceOrInsert(f)
-	return f
+	return f, nil
 }
 
 // EmitRow implements the Sink interface.
@@ -398,17 +512,27 @@ func (s *cloudStorageSink) EmitRow(
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
 
-	file := s.getOrCreateFile(table.GetName(), table.GetVersion())
-
-	// TODO(dan): Memory monitoring for this
-	if _, err := file.Write(value); err != nil {
+	file, err := s.getOrCreateFile(table, updated)
+	if err != nil {
 		return err
 	}
-	if err := s.recordDelimFn(file); err != nil {
-		return err
+
+	// TODO(dan): Memory monitoring for this
+	if file.parquet != nil {
+		if err := file.parquet.addRow(value, updated); err != nil {
+			return err
+		}
+		file.rawSize += len(value)
+	} else {
+		if _, err := file.Write(value); err != nil {
+			return err
+		}
+		if err := s.recordDelimFn(file); err != nil {
+			return err
+		}
 	}
 
-	if int64(file.buf.Len()) > s.targetMaxFileSize {
+	if file.size() > s.targetMaxFileSize {
 		if err := s.flushTopicVersions(ctx, file.topic, file.schemaID); err != nil {
 			return err
 		}
@@ -424,6 +548,10 @@ func (s *cloudStorageSink) EmitResolvedTimestamp(
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
 
+	if s.exactlyOnce {
+		return s.writeManifest(ctx, resolved)
+	}
+
 	var noTopic string
 	payload, err := encoder.EncodeResolvedTimestamp(ctx, noTopic, resolved)
 	if err != nil {
@@ -458,19 +586,19 @@ func (s *cloudStorageSink) EmitResolvedTimestamp(
 func (s *cloudStorageSink) flushTopicVersions(
 	ctx context.Context, topic string, maxVersionToFlush descpb.DescriptorVersion,
 ) (err error) {
-	var toRemoveAlloc [2]descpb.DescriptorVersion // generally avoid allocating
-	toRemove := toRemoveAlloc[:0]                 // schemaIDs of flushed files
+	var toRemoveAlloc [2]cloudStorageSinkKey // generally avoid allocating
+	toRemove := toRemoveAlloc[:0]            // keys of flushed files
 	gte := cloudStorageSinkKey{topic: topic}
 	lt := cloudStorageSinkKey{topic: topic, schemaID: maxVersionToFlush + 1}
 	s.files.AscendRange(gte, lt, func(i btree.Item) (wantMore bool) {
 		f := i.(*cloudStorageSinkFile)
 		if err = s.flushFile(ctx, f); err == nil {
-			toRemove = append(toRemove, f.schemaID)
+			toRemove = append(toRemove, f.cloudStorageSinkKey)
 		}
 		return err == nil
 	})
-	for _, v := range toRemove {
-		s.files.Delete(cloudStorageSinkKey{topic: topic, schemaID: v})
+	for _, k := range toRemove {
+		s.files.Delete(k)
 	}
 	return err
 }
@@ -491,6 +619,11 @@ func (s *cloudStorageSink) Flush(ctx context.Context) error {
 	}
 	s.files.Clear(true /* addNodesToFreeList */)
 
+	// The sink used by the changeFrontier to emit resolved timestamps has no
+	// oracle, but it never has data files to name either.
+	if s.timestampOracle == nil {
+		return nil
+	}
 	// Record the least resolved timestamp being tracked in the frontier as of this point,
 	// to use for naming files until the next `Flush()`. See comment on cloudStorageSink
 	// for an overview of the naming convention and proof of correctness.
@@ -514,6 +647,12 @@ func (s *cloudStorageSink) flushFile(ctx context.Context, file *cloudStorageSink
 			return err
 		}
 	}
+	// Similarly, parquet files aren't complete until their footer is written.
+	if file.parquet != nil {
+		if err := file.parquet.close(); err != nil {
+			return err
+		}
+	}
 
 	// We use this monotonically increasing fileID to ensure correct ordering
 	// among files emitted at the same timestamp during the same job session.
@@ -523,14 +662,24 @@ func (s *cloudStorageSink) flushFile(ctx context.Context, file *cloudStorageSink
 	// Note that we use `-` here to delimit the filename because we want
 	// `%d.RESOLVED` files to lexicographically succeed data files that have the
 	// same timestamp. This works because ascii `-` < ascii '.'.
-	filename := fmt.Sprintf(`%s-%s-%d-%d-%08x-%s-%x%s`, s.dataFileTs,
+	dataFileTs, dataFilePartition := s.dataFileTs, s.dataFilePartition
+	if s.exactlyOnce {
+		// Files are ordered by the manifests rather than by name, and a file for
+		// an earlier interval of one topic may well be flushed after a file for a
+		// later interval of another.
+		dataFileTs = cloudStorageFormatTime(file.lowerBound)
+		dataFilePartition = file.lowerBound.GoTime().Format(s.partitionFormat)
+	}
+	filename := fmt.Sprintf(`%s-%s-%d-%d-%08x-%s-%x%s`, dataFileTs,
 		s.jobSessionID, s.srcID, s.sinkID, fileID, file.topic, file.schemaID, s.ext)
-	if s.prevFilename != "" && filename < s.prevFilename {
-		return errors.AssertionFailedf("error: detected a filename %s that lexically "+
-			"precedes a file emitted before: %s", filename, s.prevFilename)
+	if !s.exactlyOnce {
+		if s.prevFilename != "" && filename < s.prevFilename {
+			return errors.AssertionFailedf("error: detected a filename %s that lexically "+
+				"precedes a file emitted before: %s", filename, s.prevFilename)
+		}
+		s.prevFilename = filename
 	}
-	s.prevFilename = filename
-	return s.es.WriteFile(ctx, filepath.Join(s.dataFilePartition, filename), bytes.NewReader(file.buf.Bytes()))
+	return s.es.WriteFile(ctx, filepath.Join(dataFilePartition, filename), bytes.NewReader(file.buf.Bytes()))
 }
 
 // Close implements the Sink interface.
@@ -542,6 +691,9 @@ func (s *cloudStorageSink) Close() error {
 type cloudStorageSinkKey struct {
 	topic    string
 	schemaID descpb.DescriptorVersion
+	// interval is the exclusive start of the manifest interval of the file's
+	// rows in exactly-once mode, and empty otherwise.
+	interval hlc.Timestamp
 }
 
 func (k cloudStorageSinkKey) Less(other btree.Item) bool {
@@ -556,8 +708,149 @@ func (k cloudStorageSinkKey) Less(other btree.Item) bool {
 }
 
 func keyLess(a, b cloudStorageSinkKey) bool {
-	if a.topic == b.topic {
+	if a.topic != b.topic {
+		return a.topic < b.topic
+	}
+	if a.schemaID != b.schemaID {
 		return a.schemaID < b.schemaID
 	}
-	return a.topic < b.topic
+	return a.interval.Less(b.interval)
+}
+
+// manifestSuffix is the extension of the manifests written in exactly-once
+// mode, which are named `<timestamp>.MANIFEST`.
+const manifestSuffix = `.MANIFEST`
+
+// cloudStorageManifest is the JSON content of a manifest. Files lists the
+// paths, relative to the sink's URI, of the data files committed by the
+// manifest, which contain every row change with an updated timestamp after the
+// previous manifest's resolved timestamp and at or before this one's.
+type cloudStorageManifest struct {
+	Resolved string   `json:"resolved"`
+	Files    []string `json:"files"`
+}
+
+// manifestTimestamp returns the latest multiple of the manifest interval which
+// is at or before ts.
+func manifestTimestamp(ts hlc.Timestamp, interval time.Duration) hlc.Timestamp {
+	return hlc.Timestamp{WallTime: ts.WallTime - ts.WallTime%interval.Nanoseconds()}
+}
+
+// manifestIntervalStart returns the exclusive start of the manifest interval
+// containing ts, that is the latest multiple of the manifest interval which is
+// before ts.
+func manifestIntervalStart(ts hlc.Timestamp, interval time.Duration) hlc.Timestamp {
+	return manifestTimestamp(ts.Prev(), interval)
+}
+
+// startExactlyOnceSession sets the session ID used to name data files. It's
+// generated for each changefeed flow and shared by all of its sinks, so that
+// the manifests written by the flow commit the files written by its
+// changeAggregators and none of those left behind by earlier flows.
+func (s *cloudStorageSink) startExactlyOnceSession(sessionID string) {
+	s.jobSessionID = sessionID
+}
+
+// startExactlyOnceManifests sets the session ID of the data files to commit and
+// finds the latest manifest written by an earlier session, if any. The first
+// manifest written by this session commits the files written by it after that
+// manifest, or after committed if that's later. committed is the high-water
+// the changefeed resumed from, or the predecessor of its statement time, and
+// now is the current time.
+func (s *cloudStorageSink) startExactlyOnceManifests(
+	ctx context.Context, sessionID string, committed, now hlc.Timestamp,
+) error {
+	s.jobSessionID = sessionID
+	s.lastManifest = committed
+	// The job is checkpointed after each manifest is written, so only a manifest
+	// written right before a restart can be later than the high-water.
+	for _, part := range s.partitions(committed.GoTime(), now.GoTime()) {
+		files, err := s.es.ListFiles(ctx, part+`/*`+manifestSuffix)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			ts, err := parseCloudStorageFormatTime(strings.TrimSuffix(path.Base(file), manifestSuffix))
+			if err != nil {
+				return errors.Wrapf(err, `parsing manifest %s`, file)
+			}
+			s.lastManifest.Forward(ts)
+		}
+	}
+	return nil
+}
+
+// partitions returns the date partitions of files named after timestamps
+// between from and to, inclusive.
+func (s *cloudStorageSink) partitions(from, to time.Time) []string {
+	last := to.Format(s.partitionFormat)
+	var parts []string
+	for t := from; ; t = t.Add(24 * time.Hour) {
+		part := t.Format(s.partitionFormat)
+		parts = append(parts, part)
+		if part >= last {
+			return parts
+		}
+	}
+}
+
+// writeManifest writes the manifest committing the data files written by this
+// session since the last manifest. The manifest is named after the resolved
+// timestamp, so consumers that only read the files listed in manifests, in
+// the order of the manifests, see each row change exactly once and in order.
+// Data files not listed in any manifest are leftovers of sessions that were
+// interrupted and should be ignored. Those may contain any of the rows
+// replayed by this session, but as they're named with a different session ID,
+// they're never committed.
+//
+// The files written by the changeAggregators have all been flushed by the time
+// the frontier resolves the timestamp, and each of them only contains rows from
+// one manifest interval. They are named after an inclusive lower bound of
+// their rows' timestamps, which is after the start of their interval, so the
+// files to commit are exactly those of this session named after a timestamp
+// after the last manifest and at or before the resolved one.
+func (s *cloudStorageSink) writeManifest(ctx context.Context, resolved hlc.Timestamp) error {
+	if resolved.LessEq(s.lastManifest) {
+		// The changefeed resumed from before a manifest written by an earlier
+		// session, whose rows have already been committed.
+		return nil
+	}
+	lower, upper := cloudStorageFormatTime(s.lastManifest), cloudStorageFormatTime(resolved)
+	session := `-` + s.jobSessionID + `-`
+	manifest := cloudStorageManifest{
+		Resolved: tree.TimestampToDecimalDatum(resolved).Decimal.String(),
+		Files:    []string{},
+	}
+	for _, part := range s.partitions(s.lastManifest.Next().GoTime(), resolved.GoTime()) {
+		files, err := s.es.ListFiles(ctx, part+`/*`)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			name := path.Base(file)
+			if len(name) <= len(upper) || !strings.HasPrefix(name[len(upper):], session) {
+				continue
+			}
+			if ts := name[:len(upper)]; ts > lower && ts <= upper {
+				manifest.Files = append(manifest.Files, file)
+			}
+		}
+	}
+	sort.Strings(manifest.Files)
+	payload, err := gojson.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	part := resolved.GoTime().Format(s.partitionFormat)
+	filename := upper + manifestSuffix
+	if log.V(1) {
+		log.Infof(ctx, "writing manifest %s %s with %d files",
+			filename, resolved.AsOfSystemTime(), len(manifest.Files))
+	}
+	if err := s.es.WriteFile(ctx, filepath.Join(part, filename), bytes.NewReader(payload)); err != nil {
+		return err
+	}
+	s.lastManifest = resolved
+	return nil
 }
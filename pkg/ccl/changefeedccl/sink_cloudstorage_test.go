@@ -12,7 +12,9 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	gojson "encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"os"
@@ -20,7 +22,9 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/apd/v2"
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/blobs"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
@@ -29,6 +33,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -36,6 +43,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	goparquet "github.com/fraugster/parquet-go"
 	"github.com/stretchr/testify/require"
 )
 
@@ -537,3 +546,278 @@ func TestCloudStorageSink(t *testing.T) {
 		}, slurpDir(t, dir))
 	})
 }
+
+func TestCloudStorageSinkParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = dir
+	clientFactory := blobs.TestBlobServiceClient(settings.ExternalIODir)
+	externalStorageFromURI := func(ctx context.Context, uri string, user security.SQLUsername) (cloud.ExternalStorage,
+		error) {
+		return cloudimpl.ExternalStorageFromURI(ctx, uri, base.ExternalIODirConfig{}, settings,
+			clientFactory, user, nil, nil)
+	}
+	user := security.RootUserName()
+	ts := func(i int64) hlc.Timestamp { return hlc.Timestamp{WallTime: i} }
+
+	t1 := tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:    `t1`,
+		Version: 1,
+		Columns: []descpb.ColumnDescriptor{
+			{ID: 1, Name: `a`, Type: types.Int},
+			{ID: 2, Name: `b`, Type: types.String, Nullable: true},
+			{ID: 3, Name: `c`, Type: types.Timestamp, Nullable: true},
+			{ID: 4, Name: `d`, Type: types.Decimal, Nullable: true},
+		},
+	})
+	row := func(deleted bool, datums ...tree.Datum) encodeRow {
+		r := encodeRow{tableDesc: t1, deleted: deleted}
+		for i, col := range t1.PublicColumns() {
+			r.datums = append(r.datums, rowenc.DatumToEncDatum(col.GetType(), datums[i]))
+		}
+		return r
+	}
+
+	opts := map[string]string{
+		changefeedbase.OptFormat:            string(changefeedbase.OptFormatParquet),
+		changefeedbase.OptEnvelope:          string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue:        ``,
+		changefeedbase.OptUpdatedTimestamps: ``,
+	}
+	e, err := getEncoder(opts, nil /* targets */)
+	require.NoError(t, err)
+
+	// Parquet is rejected by sinks other than cloud storage.
+	var nilOracle timestampLowerBoundOracle
+	_, err = getSink(ctx, `kafka://nope`, 0 /* srcID */, opts,
+		nil /* targets */, settings, nilOracle, externalStorageFromURI, user)
+	require.EqualError(t, err, `format=parquet is only supported by cloud storage sinks`)
+
+	// So are options that only make sense for JSON.
+	_, err = getEncoder(map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatParquet),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptDiff:     ``,
+	}, nil /* targets */)
+	require.EqualError(t, err, `diff is not supported with format=parquet`)
+
+	for _, compression := range []string{``, `gzip`} {
+		t.Run(`compress=`+compression, func(t *testing.T) {
+			opts[changefeedbase.OptCompression] = compression
+			sinkDir := `parquet` + compression
+			testSpan := roachpb.Span{Key: []byte("a"), EndKey: []byte("b")}
+			timestampOracle := &changeAggregatorLowerBoundOracle{sf: span.MakeFrontier(testSpan)}
+			s, err := makeCloudStorageSink(
+				ctx, `nodelocal://0/`+sinkDir, 1, math.MaxInt64,
+				settings, opts, timestampOracle, externalStorageFromURI, user,
+			)
+			require.NoError(t, err)
+
+			ts1 := time.Date(2021, 1, 2, 3, 4, 5, 6000, time.UTC)
+			for _, r := range []encodeRow{
+				row(false, tree.NewDInt(1), tree.NewDString(`x`),
+					tree.MustMakeDTimestamp(ts1, time.Microsecond), &tree.DDecimal{Decimal: *apd.New(15, -1)}),
+				row(false, tree.NewDInt(2), tree.DNull, tree.DNull, tree.DNull),
+				row(true, tree.NewDInt(1), tree.DNull, tree.DNull, tree.DNull),
+			} {
+				value, err := e.EncodeValue(ctx, r)
+				require.NoError(t, err)
+				require.NoError(t, s.EmitRow(ctx, t1, nil /* key */, value, ts(7)))
+			}
+			require.NoError(t, s.Flush(ctx))
+
+			matches, err := filepath.Glob(filepath.Join(dir, sinkDir, `*`, `*`))
+			require.NoError(t, err)
+			require.Len(t, matches, 1)
+			require.True(t, strings.HasSuffix(matches[0], `-t1-1.parquet`), matches[0])
+
+			f, err := os.Open(matches[0])
+			require.NoError(t, err)
+			defer f.Close()
+			r, err := goparquet.NewFileReader(f)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), r.NumRows())
+			var rows []map[string]interface{}
+			for {
+				rec, err := r.NextRow()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				rows = append(rows, rec)
+			}
+			updated := []byte(`7.0000000000`)
+			require.Equal(t, []map[string]interface{}{
+				{`a`: int64(1), `b`: []byte(`x`), `c`: timeutil.ToUnixMicros(ts1), `d`: []byte(`1.5`),
+					`__crdb__event_type`: []byte(`upsert`), `__crdb__updated`: updated},
+				{`a`: int64(2), `__crdb__event_type`: []byte(`upsert`), `__crdb__updated`: updated},
+				{`a`: int64(1), `__crdb__event_type`: []byte(`delete`), `__crdb__updated`: updated},
+			}, rows)
+			require.NoError(t, s.Close())
+		})
+	}
+}
+
+func TestCloudStorageSinkExactlyOnce(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = dir
+	clientFactory := blobs.TestBlobServiceClient(settings.ExternalIODir)
+	externalStorageFromURI := func(ctx context.Context, uri string, user security.SQLUsername) (cloud.ExternalStorage,
+		error) {
+		return cloudimpl.ExternalStorageFromURI(ctx, uri, base.ExternalIODirConfig{}, settings,
+			clientFactory, user, nil, nil)
+	}
+	user := security.RootUserName()
+	ts := func(i int64) hlc.Timestamp { return hlc.Timestamp{WallTime: i} }
+	var noKey []byte
+	t1 := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `t1`})
+
+	opts := map[string]string{
+		changefeedbase.OptFormat:             string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope:           string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue:         ``,
+		changefeedbase.OptResolvedTimestamps: `10ns`,
+		changefeedbase.OptExactlyOnce:        ``,
+	}
+	e, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+
+	const sinkDir = `exactly-once`
+	statementTime := ts(1)
+	makeSink := func(oracle timestampLowerBoundOracle) *cloudStorageSink {
+		s, err := makeCloudStorageSink(
+			ctx, `nodelocal://0/`+sinkDir, 1, math.MaxInt64,
+			settings, opts, oracle, externalStorageFromURI, user,
+		)
+		require.NoError(t, err)
+		return s.(*cloudStorageSink)
+	}
+	makeAggregatorSink := func(sessionID string) *cloudStorageSink {
+		testSpan := roachpb.Span{Key: []byte("a"), EndKey: []byte("b")}
+		s := makeSink(&changeAggregatorLowerBoundOracle{
+			sf: span.MakeFrontier(testSpan), initialInclusiveLowerBound: statementTime,
+		})
+		s.startExactlyOnceSession(sessionID)
+		return s
+	}
+	// readManifest returns the contents of the files listed in a manifest.
+	readManifest := func(t *testing.T, resolved hlc.Timestamp) []string {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, sinkDir, `1970-01-01`,
+			cloudStorageFormatTime(resolved)+manifestSuffix))
+		require.NoError(t, err)
+		var manifest cloudStorageManifest
+		require.NoError(t, gojson.Unmarshal(raw, &manifest))
+		require.Equal(t, tree.TimestampToDecimalDatum(resolved).Decimal.String(), manifest.Resolved)
+		var contents []string
+		for _, file := range manifest.Files {
+			content, err := ioutil.ReadFile(filepath.Join(dir, sinkDir, file))
+			require.NoError(t, err)
+			contents = append(contents, string(content))
+		}
+		return contents
+	}
+
+	require.Equal(t, ts(10), manifestTimestamp(ts(19), 10))
+	require.Equal(t, ts(10), manifestIntervalStart(ts(20), 10))
+	parsed, err := parseCloudStorageFormatTime(cloudStorageFormatTime(hlc.Timestamp{WallTime: 123, Logical: 4}))
+	require.NoError(t, err)
+	require.Equal(t, hlc.Timestamp{WallTime: 123, Logical: 4}, parsed)
+
+	sessionID := generateChangefeedSessionID()
+	frontier := makeSink(nil /* timestampOracle */)
+	require.NoError(t, frontier.startExactlyOnceManifests(ctx, sessionID, statementTime.Prev(), ts(1)))
+
+	// An earlier session left behind a file which was never committed.
+	zombie := makeAggregatorSink(generateChangefeedSessionID())
+	require.NoError(t, zombie.EmitRow(ctx, t1, noKey, []byte(`z1`), ts(2)))
+	require.NoError(t, zombie.Flush(ctx))
+	require.NoError(t, zombie.Close())
+
+	// Rows are split into files by manifest interval, and each manifest only
+	// commits the files of its interval written by the current session.
+	agg := makeAggregatorSink(sessionID)
+	require.NoError(t, agg.EmitRow(ctx, t1, noKey, []byte(`v1`), ts(1)))
+	require.NoError(t, agg.EmitRow(ctx, t1, noKey, []byte(`v2`), ts(11)))
+	require.NoError(t, agg.EmitRow(ctx, t1, noKey, []byte(`v3`), ts(10)))
+	require.NoError(t, agg.Flush(ctx))
+	require.NoError(t, frontier.EmitResolvedTimestamp(ctx, e, ts(10)))
+	require.Equal(t, []string{"v1\nv3\n"}, readManifest(t, ts(10)))
+	require.NoError(t, frontier.EmitResolvedTimestamp(ctx, e, ts(20)))
+	require.Equal(t, []string{"v2\n"}, readManifest(t, ts(20)))
+	// No resolved timestamp files are written.
+	matches, err := filepath.Glob(filepath.Join(dir, sinkDir, `*`, `*.RESOLVED`))
+	require.NoError(t, err)
+	require.Empty(t, matches)
+	require.NoError(t, agg.Close())
+	require.NoError(t, frontier.Close())
+
+	// A session with several processors writing several files each is
+	// interrupted after flushing its files, but before they're committed.
+	t2 := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `t2`})
+	sessionID = generateChangefeedSessionID()
+	frontier = makeSink(nil /* timestampOracle */)
+	require.NoError(t, frontier.startExactlyOnceManifests(ctx, sessionID, ts(20), ts(25)))
+	agg1, agg2 := makeAggregatorSink(sessionID), makeAggregatorSink(sessionID)
+	require.NoError(t, agg1.EmitRow(ctx, t1, noKey, []byte(`v4`), ts(21)))
+	require.NoError(t, agg1.EmitRow(ctx, t2, noKey, []byte(`w1`), ts(22)))
+	require.NoError(t, agg2.EmitRow(ctx, t1, noKey, []byte(`v5`), ts(23)))
+	require.NoError(t, agg1.Flush(ctx))
+	require.NoError(t, agg2.Flush(ctx))
+	require.NoError(t, agg1.EmitRow(ctx, t1, noKey, []byte(`v6`), ts(31)))
+	require.NoError(t, agg1.Flush(ctx))
+	require.NoError(t, agg1.Close())
+	require.NoError(t, agg2.Close())
+	require.NoError(t, frontier.Close())
+
+	// The replay emits the same rows, split into files differently and only
+	// partially flushed before its manifest is written. The manifest commits
+	// each of the rows once, and none of the files of the interrupted session.
+	sessionID = generateChangefeedSessionID()
+	frontier = makeSink(nil /* timestampOracle */)
+	require.NoError(t, frontier.startExactlyOnceManifests(ctx, sessionID, ts(20), ts(25)))
+	require.Equal(t, ts(20), frontier.lastManifest)
+	agg1, agg2 = makeAggregatorSink(sessionID), makeAggregatorSink(sessionID)
+	require.NoError(t, agg1.EmitRow(ctx, t1, noKey, []byte(`v4`), ts(21)))
+	require.NoError(t, agg2.EmitRow(ctx, t2, noKey, []byte(`w1`), ts(22)))
+	require.NoError(t, agg2.EmitRow(ctx, t1, noKey, []byte(`v5`), ts(23)))
+	require.NoError(t, agg1.Flush(ctx))
+	require.NoError(t, agg2.Flush(ctx))
+	require.NoError(t, agg2.EmitRow(ctx, t1, noKey, []byte(`v6`), ts(31)))
+	require.NoError(t, frontier.EmitResolvedTimestamp(ctx, e, ts(30)))
+	require.ElementsMatch(t, []string{"v4\n", "v5\n", "w1\n"}, readManifest(t, ts(30)))
+	require.NoError(t, agg2.Flush(ctx))
+	require.NoError(t, frontier.EmitResolvedTimestamp(ctx, e, ts(40)))
+	require.Equal(t, []string{"v6\n"}, readManifest(t, ts(40)))
+	require.NoError(t, agg1.Close())
+	require.NoError(t, agg2.Close())
+	require.NoError(t, frontier.Close())
+
+	// A new session resuming from before the last manifest skips the rows it
+	// already committed.
+	sessionID = generateChangefeedSessionID()
+	frontier = makeSink(nil /* timestampOracle */)
+	require.NoError(t, frontier.startExactlyOnceManifests(ctx, sessionID, ts(30), ts(45)))
+	require.Equal(t, ts(40), frontier.lastManifest)
+	agg = makeAggregatorSink(sessionID)
+	require.NoError(t, agg.EmitRow(ctx, t1, noKey, []byte(`v6`), ts(31)))
+	require.NoError(t, agg.EmitRow(ctx, t1, noKey, []byte(`v7`), ts(41)))
+	require.NoError(t, agg.Flush(ctx))
+	require.NoError(t, frontier.EmitResolvedTimestamp(ctx, e, ts(40)))
+	require.NoError(t, frontier.EmitResolvedTimestamp(ctx, e, ts(50)))
+	require.Equal(t, []string{"v7\n"}, readManifest(t, ts(50)))
+	require.NoError(t, agg.Close())
+	require.NoError(t, frontier.Close())
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
@@ -174,6 +175,59 @@ func TestKafkaSinkEscaping(t *testing.T) {
 	require.Equal(t, sarama.ByteEncoder(`v☃`), m.Value)
 }
 
+func TestKafkaSinkTopicTemplate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	table := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `t`, ID: 52})
+
+	ctx := context.Background()
+	p := asyncProducerMock{
+		inputCh:     make(chan *sarama.ProducerMessage, 1),
+		successesCh: make(chan *sarama.ProducerMessage, 1),
+		errorsCh:    make(chan *sarama.ProducerError, 1),
+	}
+	targets := jobspb.ChangefeedTargets{
+		52: jobspb.ChangefeedTarget{StatementTimeName: `d.public.t`},
+	}
+	namer, err := makeTopicNamer(map[string]string{
+		changefeedbase.OptTopicTemplate: `{table}_{column:region}`,
+	}, targets)
+	require.NoError(t, err)
+	sink := &kafkaSink{producer: p}
+	sink.cfg.kafkaTopicPrefix = `cdc.`
+	sink.cfg.topicNamer = namer
+	sink.setTargets(targets)
+	require.Empty(t, sink.topics)
+	sink.start()
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// Rows are emitted to the topic they're routed to, which is then known to
+	// the sink.
+	routed := &routedTable{TableDescriptor: table, topic: `t_us`, partitionKey: []byte(`us`)}
+	require.NoError(t, sink.EmitRow(ctx, routed, []byte(`k1`), []byte(`v1`), zeroTS))
+	m := <-p.inputCh
+	require.Equal(t, `cdc.t_us`, m.Topic)
+	require.Equal(t, sarama.ByteEncoder(`k1`), m.Key)
+	require.Equal(t, kafkaPartitionKey(`us`), m.Metadata)
+	require.Contains(t, sink.topics, `cdc.t_us`)
+
+	// Messages with a partition key are partitioned by it rather than by their
+	// key.
+	partitioner := newChangefeedPartitioner(m.Topic)
+	partition := func(key, partitionKey string) int32 {
+		msg := &sarama.ProducerMessage{Topic: m.Topic, Key: sarama.ByteEncoder(key)}
+		if partitionKey != `` {
+			msg.Metadata = kafkaPartitionKey(partitionKey)
+		}
+		partition, err := partitioner.Partition(msg, 1000)
+		require.NoError(t, err)
+		return partition
+	}
+	require.Equal(t, partition(`us`, ``), partition(`k1`, `us`))
+	require.Equal(t, partition(`k1`, `us`), partition(`k2`, `us`))
+}
+
 type testEncoder struct{}
 
 func (testEncoder) EncodeKey(context.Context, encodeRow) ([]byte, error)   { panic(`unimplemented`) }
@@ -0,0 +1,292 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	gojson "encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	webhookSinkDefaultClientTimeout = 3 * time.Second
+	webhookSinkDefaultMaxBatchRows  = 1000
+	webhookSinkContentType          = `application/json`
+)
+
+func isWebhookSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeWebhookHTTPS
+}
+
+type webhookSinkConfig struct {
+	caCert        []byte
+	clientCert    []byte
+	clientKey     []byte
+	tlsSkipVerify bool
+	authHeader    string
+	clientTimeout time.Duration
+	maxBatchRows  int
+	retryOpts     retry.Options
+}
+
+func defaultWebhookRetryOptions() retry.Options {
+	return retry.Options{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		MaxRetries:     5,
+	}
+}
+
+// webhookSink emits to an HTTPS endpoint. Rows are buffered and sent as a
+// single JSON POST of the form `{"payload":[...],"length":N}` once
+// maxBatchRows have accumulated or Flush is called. Each entry in the payload
+// carries the topic, key and value of one row. Resolved timestamps are sent as
+// their own POST, containing exactly the encoder's resolved payload, after
+// every previously emitted row has been delivered.
+//
+// Requests are sent synchronously from the calling goroutine, so the order
+// between any two emits is preserved. It is not concurrency-safe; all calls to
+// Emit and Flush should be from the same goroutine.
+type webhookSink struct {
+	cfg    webhookSinkConfig
+	url    string
+	client *http.Client
+
+	topics      map[string]struct{}
+	targetNames map[descpb.ID]string
+
+	buf     bytes.Buffer
+	numRows int
+}
+
+var _ Sink = (*webhookSink)(nil)
+
+func makeWebhookSink(
+	cfg webhookSinkConfig, u *url.URL, targets jobspb.ChangefeedTargets,
+) (*webhookSink, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.tlsSkipVerify}
+	if cfg.caCert != nil {
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`invalid %s provided`, changefeedbase.SinkParamCACert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if cfg.clientCert != nil {
+		if cfg.clientKey == nil {
+			return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientCert, changefeedbase.SinkParamClientKey)
+		}
+		cert, err := tls.X509KeyPair(cfg.clientCert, cfg.clientKey)
+		if err != nil {
+			return nil, errors.Errorf(`invalid client certificate data provided: %s`, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if cfg.clientKey != nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientKey, changefeedbase.SinkParamClientCert)
+	}
+	if cfg.clientTimeout == 0 {
+		cfg.clientTimeout = webhookSinkDefaultClientTimeout
+	}
+	if cfg.maxBatchRows == 0 {
+		cfg.maxBatchRows = webhookSinkDefaultMaxBatchRows
+	}
+
+	dest := *u
+	dest.Scheme = `https`
+	s := &webhookSink{
+		cfg: cfg,
+		url: dest.String(),
+		client: &http.Client{
+			Timeout: cfg.clientTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}
+	s.setTargets(targets)
+	return s, nil
+}
+
+func (s *webhookSink) setTargets(targets jobspb.ChangefeedTargets) {
+	s.topics = make(map[string]struct{})
+	s.targetNames = make(map[descpb.ID]string)
+	for id, t := range targets {
+		s.targetNames[id] = t.StatementTimeName
+		s.topics[t.StatementTimeName] = struct{}{}
+	}
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, table catalog.TableDescriptor, key, value []byte, updated hlc.Timestamp,
+) error {
+	topic := s.targetNames[table.GetID()]
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	topicJSON, err := gojson.Marshal(familyQualifiedTopic(topic, table))
+	if err != nil {
+		return err
+	}
+
+	if s.numRows == 0 {
+		s.buf.WriteString(`{"payload":[`)
+	} else {
+		s.buf.WriteByte(',')
+	}
+	s.buf.WriteString(`{"topic":`)
+	s.buf.Write(topicJSON)
+	s.buf.WriteString(`,"key":`)
+	writeWebhookJSONOrNull(&s.buf, key)
+	s.buf.WriteString(`,"value":`)
+	writeWebhookJSONOrNull(&s.buf, value)
+	s.buf.WriteByte('}')
+	s.numRows++
+
+	if s.numRows >= s.cfg.maxBatchRows {
+		return s.flushRows(ctx)
+	}
+	return nil
+}
+
+// writeWebhookJSONOrNull writes the already encoded JSON in b, or a JSON null
+// if b is empty (e.g. the value of a key_only changefeed).
+func writeWebhookJSONOrNull(w *bytes.Buffer, b []byte) {
+	if len(b) == 0 {
+		w.WriteString(`null`)
+		return
+	}
+	w.Write(b)
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	// A resolved timestamp promises that every row at or below it has been
+	// delivered, so anything still buffered has to go out first.
+	if err := s.flushRows(ctx); err != nil {
+		return err
+	}
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(ctx, noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	return s.sendWithRetries(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	return s.flushRows(ctx)
+}
+
+func (s *webhookSink) flushRows(ctx context.Context) error {
+	if s.numRows == 0 {
+		return nil
+	}
+	s.buf.WriteString(`],"length":`)
+	s.buf.WriteString(strconv.Itoa(s.numRows))
+	s.buf.WriteByte('}')
+	// The batch is dropped even if the request fails. Any sink error restarts
+	// the changefeed from its last checkpoint, which re-emits these rows.
+	err := s.sendWithRetries(ctx, s.buf.Bytes())
+	s.buf.Reset()
+	s.numRows = 0
+	return err
+}
+
+func (s *webhookSink) sendWithRetries(ctx context.Context, body []byte) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, s.cfg.retryOpts); r.Next(); {
+		if err = s.send(ctx, body); err == nil {
+			return nil
+		}
+		if !errors.HasType(err, (*retryableWebhookError)(nil)) {
+			return err
+		}
+		if log.V(1) {
+			log.Infof(ctx, "retrying webhook request after error: %v", err)
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// retryableWebhookError is returned by send for failures that are likely to
+// succeed if the request is sent again.
+type retryableWebhookError struct {
+	cause error
+}
+
+func (e *retryableWebhookError) Error() string { return e.cause.Error() }
+func (e *retryableWebhookError) Cause() error  { return e.cause }
+func (e *retryableWebhookError) Unwrap() error { return e.cause }
+
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(`Content-Type`, webhookSinkContentType)
+	if s.cfg.authHeader != `` {
+		req.Header.Set(`Authorization`, s.cfg.authHeader)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return &retryableWebhookError{cause: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		// Drain the body so the connection can be reused.
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	err = errors.Errorf(`webhook sink request failed with %s: %s`, resp.Status, msg)
+	if resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout {
+		return &retryableWebhookError{cause: err}
+	}
+	return err
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
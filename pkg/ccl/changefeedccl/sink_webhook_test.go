@@ -0,0 +1,221 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookTestServer records the bodies of requests POSTed to it. The next
+// len(failures) requests are answered with the given status codes instead.
+type webhookTestServer struct {
+	*httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		bodies     []string
+		authHeader string
+		failures   []int
+	}
+}
+
+func makeWebhookTestServer(t *testing.T) *webhookTestServer {
+	s := &webhookTestServer{}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, webhookSinkContentType, r.Header.Get(`Content-Type`))
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if len(s.mu.failures) > 0 {
+			w.WriteHeader(s.mu.failures[0])
+			s.mu.failures = s.mu.failures[1:]
+			return
+		}
+		s.mu.bodies = append(s.mu.bodies, string(body))
+		s.mu.authHeader = r.Header.Get(`Authorization`)
+	}))
+	return s
+}
+
+func (s *webhookTestServer) failNext(codes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failures = append(s.mu.failures, codes...)
+}
+
+func (s *webhookTestServer) popBodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bodies := s.mu.bodies
+	s.mu.bodies = nil
+	return bodies
+}
+
+// sinkURI returns a webhook sink URI for the server that trusts its
+// self-signed certificate.
+func (s *webhookTestServer) sinkURI(params url.Values) string {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: s.Certificate().Raw})
+	u, _ := url.Parse(s.URL)
+	u.Scheme = changefeedbase.SinkSchemeWebhookHTTPS
+	u.Path = `/changefeed`
+	params.Set(changefeedbase.SinkParamCACert, base64.StdEncoding.EncodeToString(certPEM))
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	table := func(name string) catalog.TableDescriptor {
+		id, _ := strconv.ParseUint(name, 36, 64)
+		return tabledesc.NewImmutable(descpb.TableDescriptor{Name: name, ID: descpb.ID(id)})
+	}
+	targets := jobspb.ChangefeedTargets{
+		table(`foo`).GetID(): jobspb.ChangefeedTarget{StatementTimeName: `foo`},
+		table(`bar`).GetID(): jobspb.ChangefeedTarget{StatementTimeName: `bar`},
+	}
+	opts := map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+	}
+
+	ctx := context.Background()
+	srv := makeWebhookTestServer(t)
+	defer srv.Close()
+
+	makeSink := func(params url.Values) (*webhookSink, error) {
+		var nilOracle timestampLowerBoundOracle
+		s, err := getSink(ctx, srv.sinkURI(params), 0 /* srcID */, opts, targets,
+			nil /* settings */, nilOracle, nil /* makeExternalStorageFromURI */, security.RootUserName())
+		if err != nil {
+			return nil, err
+		}
+		ws := s.(*webhookSink)
+		ws.cfg.retryOpts.InitialBackoff = time.Millisecond
+		ws.cfg.retryOpts.MaxBackoff = time.Millisecond
+		return ws, nil
+	}
+
+	t.Run(`validation`, func(t *testing.T) {
+		_, err := makeSink(url.Values{`foo`: {`bar`}})
+		require.EqualError(t, err, `unknown sink query parameter: foo`)
+		_, err = makeSink(url.Values{changefeedbase.SinkParamWebhookMaxBatchRows: {`0`}})
+		require.EqualError(t, err, `param webhook_max_batch_rows must be positive`)
+		_, err = makeSink(url.Values{changefeedbase.SinkParamClientKey: {`Zm9v`}})
+		require.EqualError(t, err, `client_key requires client_cert to be set`)
+
+		var nilOracle timestampLowerBoundOracle
+		avroOpts := map[string]string{changefeedbase.OptFormat: string(changefeedbase.OptFormatAvro)}
+		_, err = getSink(ctx, srv.sinkURI(url.Values{}), 0 /* srcID */, avroOpts, targets,
+			nil /* settings */, nilOracle, nil /* makeExternalStorageFromURI */, security.RootUserName())
+		require.EqualError(t, err, `this sink is incompatible with format=experimental_avro`)
+	})
+
+	t.Run(`batching`, func(t *testing.T) {
+		sink, err := makeSink(url.Values{
+			changefeedbase.SinkParamWebhookMaxBatchRows: {`3`},
+			changefeedbase.SinkParamWebhookAuthHeader:   {`Basic Zm9vOmJhcg==`},
+		})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, sink.Close()) }()
+
+		// Empty
+		require.NoError(t, sink.Flush(ctx))
+		require.Empty(t, srv.popBodies())
+
+		// Undeclared topic
+		require.EqualError(t,
+			sink.EmitRow(ctx, table(`nope`), nil, nil, zeroTS), `cannot emit to undeclared topic: `)
+
+		// With one row, nothing is sent until Flush is called.
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), []byte(`[1]`), []byte(`{"after":{"a":1}}`), zeroTS))
+		require.Empty(t, srv.popBodies())
+		require.NoError(t, sink.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"foo","key":[1],"value":{"after":{"a":1}}}],"length":1}`,
+		}, srv.popBodies())
+		srv.mu.Lock()
+		require.Equal(t, `Basic Zm9vOmJhcg==`, srv.mu.authHeader)
+		srv.mu.Unlock()
+
+		// Batches are sent implicitly once they're full and keep the emit order
+		// across tables.
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), []byte(`[1]`), []byte(`{"after":{"a":2}}`), zeroTS))
+		require.NoError(t, sink.EmitRow(ctx, table(`bar`), []byte(`[2]`), []byte(`{"after":null}`), zeroTS))
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), []byte(`[1]`), nil, zeroTS))
+		require.NoError(t, sink.EmitRow(ctx, table(`bar`), []byte(`[3]`), []byte(`{"after":{"b":3}}`), zeroTS))
+		require.Equal(t, []string{
+			`{"payload":[` +
+				`{"topic":"foo","key":[1],"value":{"after":{"a":2}}},` +
+				`{"topic":"bar","key":[2],"value":{"after":null}},` +
+				`{"topic":"foo","key":[1],"value":null}` +
+				`],"length":3}`,
+		}, srv.popBodies())
+
+		// Resolved timestamps are only sent after every buffered row.
+		enc, err := makeJSONEncoder(opts)
+		require.NoError(t, err)
+		require.NoError(t, sink.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 2}))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"bar","key":[3],"value":{"after":{"b":3}}}],"length":1}`,
+			`{"resolved":"2.0000000000"}`,
+		}, srv.popBodies())
+	})
+
+	t.Run(`retries`, func(t *testing.T) {
+		sink, err := makeSink(url.Values{})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, sink.Close()) }()
+
+		// Transient failures are retried.
+		srv.failNext(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), []byte(`[1]`), []byte(`{}`), zeroTS))
+		require.NoError(t, sink.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"foo","key":[1],"value":{}}],"length":1}`,
+		}, srv.popBodies())
+
+		// Other client errors are not.
+		srv.failNext(http.StatusBadRequest)
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), []byte(`[1]`), []byte(`{}`), zeroTS))
+		require.Regexp(t, `webhook sink request failed with 400 Bad Request`, sink.Flush(ctx))
+		require.Empty(t, srv.popBodies())
+
+		// Neither are persistent failures, once the retries are exhausted.
+		sink.cfg.retryOpts.MaxRetries = 2
+		srv.failNext(http.StatusInternalServerError, http.StatusInternalServerError,
+			http.StatusInternalServerError)
+		require.NoError(t, sink.EmitRow(ctx, table(`foo`), []byte(`[1]`), []byte(`{}`), zeroTS))
+		require.Regexp(t, `webhook sink request failed with 500 Internal Server Error`, sink.Flush(ctx))
+		require.Empty(t, srv.popBodies())
+	})
+}
@@ -17,7 +17,8 @@ type TestingKnobs struct {
 	// AfterSinkFlush is called after a sink flush operation has returned without
 	// error.
 	AfterSinkFlush func() error
-	// MemBufferCapacity, if non-zero, overrides memBufferDefaultCapacity.
+	// MemBufferCapacity, if non-zero, overrides the per-changefeed memory limit
+	// (see changefeedbase.PerChangefeedMemLimit).
 	MemBufferCapacity int64
 }
 
@@ -0,0 +1,394 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+type topicTemplatePartKind int
+
+const (
+	topicTemplateLiteral topicTemplatePartKind = iota
+	topicTemplateDatabase
+	topicTemplateSchema
+	topicTemplateTable
+	topicTemplateFamily
+	topicTemplateColumn
+)
+
+// topicTemplateColumnPrefix introduces a placeholder for the value of a
+// column, as in `{column:region}`.
+const topicTemplateColumnPrefix = `column:`
+
+var topicTemplatePlaceholders = map[string]topicTemplatePartKind{
+	`database`: topicTemplateDatabase,
+	`schema`:   topicTemplateSchema,
+	`table`:    topicTemplateTable,
+	`family`:   topicTemplateFamily,
+}
+
+// topicTemplatePart is either literal text or a placeholder of a topic
+// template. For column placeholders, value is the name of the column.
+type topicTemplatePart struct {
+	kind  topicTemplatePartKind
+	value string
+}
+
+// parseTopicTemplate parses the value of the `topic_template` option, which is
+// literal text with placeholders in braces: `{database}`, `{schema}`,
+// `{table}`, `{family}` and `{column:<name>}`.
+func parseTopicTemplate(template string) ([]topicTemplatePart, error) {
+	if template == `` {
+		return nil, errors.Errorf(`%s must not be empty`, changefeedbase.OptTopicTemplate)
+	}
+	var parts []topicTemplatePart
+	for len(template) > 0 {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			parts = append(parts, topicTemplatePart{kind: topicTemplateLiteral, value: template})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, topicTemplatePart{kind: topicTemplateLiteral, value: template[:start]})
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return nil, errors.Errorf(`unterminated placeholder in %s: %s`,
+				changefeedbase.OptTopicTemplate, template[start:])
+		}
+		placeholder := template[start+1 : start+end]
+		if kind, ok := topicTemplatePlaceholders[placeholder]; ok {
+			parts = append(parts, topicTemplatePart{kind: kind})
+		} else if col := strings.TrimPrefix(placeholder, topicTemplateColumnPrefix); col != placeholder && col != `` {
+			parts = append(parts, topicTemplatePart{kind: topicTemplateColumn, value: col})
+		} else {
+			return nil, errors.Errorf(`unknown placeholder in %s: {%s}`,
+				changefeedbase.OptTopicTemplate, placeholder)
+		}
+		template = template[start+end+1:]
+	}
+	return parts, nil
+}
+
+// parsePartitionKey parses the value of the `partition_key` option, a
+// comma-separated list of column names. Each name is optionally qualified by
+// the name of a watched table, in which case it only applies to that table.
+// The result maps table names to their columns, with unqualified columns
+// under the empty table name.
+func parsePartitionKey(partitionKey string) (map[string][]string, error) {
+	cols := make(map[string][]string)
+	for _, s := range strings.Split(partitionKey, `,`) {
+		expr, err := parser.ParseExpr(strings.TrimSpace(s))
+		if err != nil {
+			return nil, errors.Wrapf(err, `parsing %s`, changefeedbase.OptPartitionKey)
+		}
+		name, ok := expr.(*tree.UnresolvedName)
+		if !ok || name.NumParts > 2 || name.Star {
+			return nil, errors.Errorf(`%s must be a list of column names: %s`,
+				changefeedbase.OptPartitionKey, tree.AsString(expr))
+		}
+		table := ``
+		if name.NumParts == 2 {
+			table = name.Parts[1]
+		}
+		cols[table] = append(cols[table], name.Parts[0])
+	}
+	return cols, nil
+}
+
+// validateTopicTemplate checks the `topic_template` and `partition_key`
+// options, if any, against the tables watched by the changefeed. Every column
+// they mention must exist in each table it applies to.
+func validateTopicTemplate(opts map[string]string, targetDescs []catalog.Descriptor) error {
+	template, hasTemplate := opts[changefeedbase.OptTopicTemplate]
+	partitionKey, hasPartitionKey := opts[changefeedbase.OptPartitionKey]
+	if !hasTemplate && !hasPartitionKey {
+		return nil
+	}
+	var templateCols []string
+	if hasTemplate {
+		parts, err := parseTopicTemplate(template)
+		if err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if part.kind == topicTemplateColumn {
+				templateCols = append(templateCols, part.value)
+			}
+		}
+	}
+	var keyCols map[string][]string
+	if hasPartitionKey {
+		var err error
+		if keyCols, err = parsePartitionKey(partitionKey); err != nil {
+			return err
+		}
+	}
+
+	checkCols := func(table catalog.TableDescriptor, opt string, names []string) error {
+		for _, name := range names {
+			col, err := table.FindColumnWithName(tree.Name(name))
+			if err != nil || !col.Public() {
+				return errors.Errorf(`column %q used in %s does not exist in table %s`,
+					name, opt, table.GetName())
+			}
+			// Partition keys are key encoded, so they're restricted to the same
+			// types as index columns.
+			if opt == changefeedbase.OptPartitionKey && !colinfo.ColumnTypeIsIndexable(col.GetType()) {
+				return errors.Errorf(`column %q of type %s cannot be used in %s`,
+					name, col.GetType().SQLString(), opt)
+			}
+		}
+		return nil
+	}
+	seen := make(map[string]struct{})
+	for _, desc := range targetDescs {
+		table, isTable := desc.(catalog.TableDescriptor)
+		if !isTable {
+			continue
+		}
+		seen[table.GetName()] = struct{}{}
+		if err := checkCols(table, changefeedbase.OptTopicTemplate, templateCols); err != nil {
+			return err
+		}
+		if err := checkCols(table, changefeedbase.OptPartitionKey, keyCols[``]); err != nil {
+			return err
+		}
+		if err := checkCols(table, changefeedbase.OptPartitionKey, keyCols[table.GetName()]); err != nil {
+			return err
+		}
+	}
+	for tableName := range keyCols {
+		if _, ok := seen[tableName]; !ok && tableName != `` {
+			return errors.Errorf(`table %s used in %s is not watched by the changefeed`,
+				tableName, changefeedbase.OptPartitionKey)
+		}
+	}
+	return nil
+}
+
+// topicTarget is the statement time name of a watched table, along with its
+// parts if it's fully qualified, and the columns of its partition key.
+type topicTarget struct {
+	name                    string
+	database, schema, table string
+	partitionKey            []string
+}
+
+// topicNamer routes rows to kafka topics and picks their partition keys, as
+// configured by the `topic_template` and `partition_key` options. Without a
+// template, rows are routed to the topic of their table, as usual, qualified
+// by their column family if the `split_column_families` option is set.
+//
+// Database and schema names are those of the watched tables at statement
+// time, like the table names used for topics. Changefeeds with a template
+// always record fully qualified statement time names for this reason.
+type topicNamer struct {
+	template      []topicTemplatePart
+	targets       map[descpb.ID]topicTarget
+	splitFamilies bool
+
+	alloc rowenc.DatumAlloc
+	buf   strings.Builder
+	key   []byte
+}
+
+// makeTopicNamer returns a topicNamer for the changefeed, or nil if it has
+// none of the `topic_template`, `partition_key` and `split_column_families`
+// options.
+func makeTopicNamer(
+	opts map[string]string, targets jobspb.ChangefeedTargets,
+) (*topicNamer, error) {
+	template, hasTemplate := opts[changefeedbase.OptTopicTemplate]
+	partitionKey, hasPartitionKey := opts[changefeedbase.OptPartitionKey]
+	_, splitFamilies := opts[changefeedbase.OptSplitColumnFamilies]
+	if !hasTemplate && !hasPartitionKey && !splitFamilies {
+		return nil, nil
+	}
+	n := &topicNamer{
+		targets:       make(map[descpb.ID]topicTarget, len(targets)),
+		splitFamilies: splitFamilies,
+	}
+	if hasTemplate {
+		var err error
+		if n.template, err = parseTopicTemplate(template); err != nil {
+			return nil, err
+		}
+	}
+	var keyCols map[string][]string
+	if hasPartitionKey {
+		var err error
+		if keyCols, err = parsePartitionKey(partitionKey); err != nil {
+			return nil, err
+		}
+	}
+	_, qualified := opts[changefeedbase.OptFullTableName]
+	for id, target := range targets {
+		t := topicTarget{name: target.StatementTimeName, table: target.StatementTimeName}
+		if qualified || hasTemplate {
+			tn, err := parser.ParseQualifiedTableName(target.StatementTimeName)
+			if err != nil {
+				return nil, errors.Wrapf(err, `parsing name of table %s`, target.StatementTimeName)
+			}
+			t.database, t.schema, t.table = tn.Catalog(), tn.Schema(), tn.Table()
+		}
+		t.partitionKey = append(t.partitionKey, keyCols[``]...)
+		t.partitionKey = append(t.partitionKey, keyCols[t.table]...)
+		n.targets[id] = t
+	}
+	return n, nil
+}
+
+// isStatic returns whether each table is routed to a single topic, which is
+// then returned by staticTopic.
+func (n *topicNamer) isStatic() bool {
+	if n.template == nil {
+		return !n.splitFamilies
+	}
+	for _, part := range n.template {
+		if part.kind == topicTemplateFamily || part.kind == topicTemplateColumn {
+			return false
+		}
+	}
+	return true
+}
+
+// staticTopic returns the topic of the given table. It's only valid if
+// isStatic.
+func (n *topicNamer) staticTopic(id descpb.ID) string {
+	t := n.targets[id]
+	if n.template == nil {
+		return SQLNameToKafkaName(t.name)
+	}
+	n.buf.Reset()
+	for _, part := range n.template {
+		n.writeStaticPart(t, part)
+	}
+	return n.buf.String()
+}
+
+func (n *topicNamer) writeStaticPart(t topicTarget, part topicTemplatePart) {
+	switch part.kind {
+	case topicTemplateLiteral:
+		n.buf.WriteString(part.value)
+	case topicTemplateDatabase:
+		n.buf.WriteString(SQLNameToKafkaName(t.database))
+	case topicTemplateSchema:
+		n.buf.WriteString(SQLNameToKafkaName(t.schema))
+	case topicTemplateTable:
+		n.buf.WriteString(SQLNameToKafkaName(t.table))
+	}
+}
+
+// route returns the topic and partition key of the row. A nil partition key
+// means the row is partitioned by its key, as usual. The returned key is only
+// valid until the next call.
+func (n *topicNamer) route(r encodeRow) (topic string, partitionKey []byte, _ error) {
+	t, ok := n.targets[r.tableDesc.GetID()]
+	if !ok {
+		return ``, nil, errors.AssertionFailedf(`unwatched table: %s`, r.tableDesc.GetName())
+	}
+
+	if n.isStatic() {
+		topic = n.staticTopic(r.tableDesc.GetID())
+	} else if n.template == nil {
+		family, err := r.tableDesc.FindFamilyByID(r.familyID)
+		if err != nil {
+			return ``, nil, err
+		}
+		topic = SQLNameToKafkaName(t.name) + `.` + SQLNameToKafkaName(family.Name)
+	} else {
+		n.buf.Reset()
+		for _, part := range n.template {
+			switch part.kind {
+			case topicTemplateFamily:
+				family, err := r.tableDesc.FindFamilyByID(r.familyID)
+				if err != nil {
+					return ``, nil, err
+				}
+				n.buf.WriteString(SQLNameToKafkaName(family.Name))
+			case topicTemplateColumn:
+				datum, err := n.columnValue(r, part.value)
+				if err != nil {
+					return ``, nil, err
+				}
+				n.buf.WriteString(SQLNameToKafkaName(tree.AsStringWithFlags(datum, tree.FmtExport)))
+			default:
+				n.writeStaticPart(t, part)
+			}
+		}
+		topic = n.buf.String()
+	}
+
+	if len(t.partitionKey) == 0 {
+		return topic, nil, nil
+	}
+	n.key = n.key[:0]
+	for _, name := range t.partitionKey {
+		datum, err := n.columnValue(r, name)
+		if err != nil {
+			return ``, nil, err
+		}
+		if n.key, err = rowenc.EncodeTableKey(n.key, datum, encoding.Ascending); err != nil {
+			return ``, nil, err
+		}
+	}
+	return topic, n.key, nil
+}
+
+// columnValue returns the value of the named column in the row. Columns that
+// no longer exist, e.g. because they were dropped after the changefeed was
+// created, are NULL.
+func (n *topicNamer) columnValue(r encodeRow, name string) (tree.Datum, error) {
+	for i, col := range r.tableDesc.PublicColumns() {
+		if col.GetName() != name {
+			continue
+		}
+		datum := r.datums[i]
+		if err := datum.EnsureDecoded(col.GetType(), &n.alloc); err != nil {
+			return nil, err
+		}
+		return datum.Datum, nil
+	}
+	return tree.DNull, nil
+}
+
+// routedTable is handed to Sink.EmitRow in place of the table descriptor of a
+// row when the changefeed has a topicNamer. It carries the topic and partition
+// key computed from the row, which the kafka sink uses instead of deriving
+// them from the table. If the changefeed splits column families, it also
+// carries the name of the family of the row, which other sinks add to the
+// table name (see familyQualifiedTopic).
+type routedTable struct {
+	catalog.TableDescriptor
+	topic        string
+	partitionKey []byte
+	family       string
+}
+
+// familyQualifiedTopic returns the given topic of a row, qualified by the name
+// of its column family if the changefeed splits column families.
+func familyQualifiedTopic(topic string, table catalog.TableDescriptor) string {
+	if routed, ok := table.(*routedTable); ok && routed.family != `` {
+		return topic + `.` + routed.family
+	}
+	return topic
+}
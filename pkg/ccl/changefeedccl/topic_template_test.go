@@ -0,0 +1,153 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTopicTemplate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tests := []struct {
+		template string
+		expected []topicTemplatePart
+		err      string
+	}{
+		{template: `cdc`, expected: []topicTemplatePart{{kind: topicTemplateLiteral, value: `cdc`}}},
+		{
+			template: `{database}.{schema}_{table}-{family}{column:region}`,
+			expected: []topicTemplatePart{
+				{kind: topicTemplateDatabase},
+				{kind: topicTemplateLiteral, value: `.`},
+				{kind: topicTemplateSchema},
+				{kind: topicTemplateLiteral, value: `_`},
+				{kind: topicTemplateTable},
+				{kind: topicTemplateLiteral, value: `-`},
+				{kind: topicTemplateFamily},
+				{kind: topicTemplateColumn, value: `region`},
+			},
+		},
+		{template: ``, err: `topic_template must not be empty`},
+		{template: `cdc.{table`, err: `unterminated placeholder in topic_template: {table`},
+		{template: `{tenant}`, err: `unknown placeholder in topic_template: {tenant}`},
+		{template: `{column:}`, err: `unknown placeholder in topic_template: {column:}`},
+	}
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			parts, err := parseTopicTemplate(test.template)
+			if test.err != `` {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, parts)
+		})
+	}
+}
+
+func TestTopicNamer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	desc := tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name: `orders`,
+		ID:   52,
+		Columns: []descpb.ColumnDescriptor{
+			{ID: 1, Name: `id`, Type: types.Int},
+			{ID: 2, Name: `region`, Type: types.String},
+		},
+		Families: []descpb.ColumnFamilyDescriptor{
+			{ID: 0, Name: `primary`, ColumnIDs: []descpb.ColumnID{1, 2}, ColumnNames: []string{`id`, `region`}},
+		},
+	})
+	row := func(id int, region string) encodeRow {
+		return encodeRow{tableDesc: desc, datums: rowenc.EncDatumRow{
+			rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(id))),
+			rowenc.DatumToEncDatum(types.String, tree.NewDString(region)),
+		}}
+	}
+	targets := jobspb.ChangefeedTargets{
+		52: jobspb.ChangefeedTarget{StatementTimeName: `"tenant☃".public.orders`},
+	}
+
+	t.Run(`static`, func(t *testing.T) {
+		n, err := makeTopicNamer(map[string]string{
+			changefeedbase.OptTopicTemplate: `cdc.{database}.{schema}.{table}`,
+		}, targets)
+		require.NoError(t, err)
+		require.True(t, n.isStatic())
+		require.Equal(t, `cdc.tenant_u2603_.public.orders`, n.staticTopic(52))
+
+		topic, partitionKey, err := n.route(row(1, `us`))
+		require.NoError(t, err)
+		require.Equal(t, `cdc.tenant_u2603_.public.orders`, topic)
+		require.Nil(t, partitionKey)
+	})
+
+	t.Run(`dynamic`, func(t *testing.T) {
+		n, err := makeTopicNamer(map[string]string{
+			changefeedbase.OptTopicTemplate: `{table}.{family}.{column:region}`,
+			changefeedbase.OptPartitionKey:  `orders.region`,
+		}, targets)
+		require.NoError(t, err)
+		require.False(t, n.isStatic())
+
+		topic, usKey, err := n.route(row(1, `us`))
+		require.NoError(t, err)
+		require.Equal(t, `orders.primary.us`, topic)
+		usKey = append([]byte(nil), usKey...)
+
+		topic, euKey, err := n.route(row(1, `eu west`))
+		require.NoError(t, err)
+		require.Equal(t, `orders.primary.eu_u0020_west`, topic)
+		require.NotEqual(t, usKey, euKey)
+
+		// Rows with the same partition key columns share a partition key, no
+		// matter their primary key.
+		_, otherUSKey, err := n.route(row(2, `us`))
+		require.NoError(t, err)
+		require.Equal(t, usKey, otherUSKey)
+	})
+
+	t.Run(`partition key only`, func(t *testing.T) {
+		n, err := makeTopicNamer(map[string]string{
+			changefeedbase.OptPartitionKey: `region`,
+		}, jobspb.ChangefeedTargets{52: jobspb.ChangefeedTarget{StatementTimeName: `orders`}})
+		require.NoError(t, err)
+		topic, partitionKey, err := n.route(row(1, `us`))
+		require.NoError(t, err)
+		require.Equal(t, `orders`, topic)
+		require.NotNil(t, partitionKey)
+	})
+
+	t.Run(`split column families`, func(t *testing.T) {
+		n, err := makeTopicNamer(map[string]string{
+			changefeedbase.OptSplitColumnFamilies: ``,
+		}, jobspb.ChangefeedTargets{52: jobspb.ChangefeedTarget{StatementTimeName: `orders`}})
+		require.NoError(t, err)
+		require.False(t, n.isStatic())
+		topic, partitionKey, err := n.route(row(1, `us`))
+		require.NoError(t, err)
+		require.Equal(t, `orders.primary`, topic)
+		require.Nil(t, partitionKey)
+	})
+}
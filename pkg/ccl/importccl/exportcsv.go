@@ -18,6 +18,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -29,7 +31,69 @@ import (
 )
 
 const exportFilePatternPart = "%part%"
-const exportFilePatternDefault = exportFilePatternPart + ".csv"
+
+// exporter encodes the rows of one chunk of an EXPORT into a file, which is
+// then written to external storage by the csvWriter processor.
+type exporter interface {
+	// Write appends a row to the file.
+	Write(row tree.Datums) error
+	// Flush flushes any buffered rows.
+	Flush() error
+	// Close finishes the file, e.g. by writing its footer.
+	Close() error
+	// ResetBuffer discards the current file and starts a new one.
+	ResetBuffer() error
+	// Bytes returns the contents of the file.
+	Bytes() []byte
+	// Len returns the length of the file.
+	Len() int
+	// FileName returns the name of the file for the given part.
+	FileName(spec execinfrapb.CSVWriterSpec, part string) string
+}
+
+// newExporter returns the exporter for the format of the spec, writing rows of
+// the given types.
+func newExporter(sp execinfrapb.CSVWriterSpec, typs []*types.T) (exporter, error) {
+	switch sp.Format {
+	case execinfrapb.ExportFormat_CSV:
+		return newCSVExporter(sp), nil
+	case execinfrapb.ExportFormat_Parquet:
+		return newParquetExporter(sp, typs)
+	case execinfrapb.ExportFormat_JSON:
+		return newJSONExporter(sp, typs)
+	default:
+		return nil, errors.AssertionFailedf("unsupported export format %s", sp.Format)
+	}
+}
+
+// exportFileName returns the name of the file for the given part, using the
+// name pattern of the spec or, if it is not set, the given extension.
+func exportFileName(spec execinfrapb.CSVWriterSpec, part string, extension string) string {
+	pattern := exportFilePatternPart + "." + extension
+	if spec.NamePattern != "" {
+		pattern = spec.NamePattern
+	}
+	return strings.Replace(pattern, exportFilePatternPart, part, -1)
+}
+
+// exportColumnNames returns the names of the exported columns, which are used
+// as keys by the formats whose rows are not positional.
+func exportColumnNames(sp execinfrapb.CSVWriterSpec, typs []*types.T) ([]string, error) {
+	if len(sp.ColNames) != len(typs) {
+		return nil, errors.AssertionFailedf(
+			"expected %d column names, got %d", len(typs), len(sp.ColNames))
+	}
+	seen := make(map[string]struct{}, len(sp.ColNames))
+	for _, name := range sp.ColNames {
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateColumn,
+				"duplicate column name %q: EXPORT INTO %s requires unique column names",
+				name, strings.ToUpper(sp.Format.String()))
+		}
+		seen[name] = struct{}{}
+	}
+	return sp.ColNames, nil
+}
 
 // csvExporter data structure to augment the compression
 // and csv writer, encapsulating the internals to make
@@ -38,11 +102,31 @@ type csvExporter struct {
 	compressor *gzip.Writer
 	buf        *bytes.Buffer
 	csvWriter  *csv.Writer
+
+	nullsAs *string
+	f       *tree.FmtCtx
+	csvRow  []string
 }
 
+var _ exporter = &csvExporter{}
+
 // Write append record to csv file
-func (c *csvExporter) Write(record []string) error {
-	return c.csvWriter.Write(record)
+func (c *csvExporter) Write(row tree.Datums) error {
+	c.csvRow = c.csvRow[:0]
+	for _, d := range row {
+		if d == tree.DNull {
+			if c.nullsAs == nil {
+				return errors.New("NULL value encountered during EXPORT, " +
+					"use `WITH nullas` to specify the string representation of NULL")
+			}
+			c.csvRow = append(c.csvRow, *c.nullsAs)
+			continue
+		}
+		d.Format(c.f)
+		c.csvRow = append(c.csvRow, c.f.String())
+		c.f.Reset()
+	}
+	return c.csvWriter.Write(c.csvRow)
 }
 
 // Close closes the compressor writer which
@@ -65,12 +149,13 @@ func (c *csvExporter) Flush() error {
 }
 
 // ResetBuffer resets the buffer and compressor state.
-func (c *csvExporter) ResetBuffer() {
+func (c *csvExporter) ResetBuffer() error {
 	c.buf.Reset()
 	if c.compressor != nil {
 		// Brings compressor to its initial state
 		c.compressor.Reset(c.buf)
 	}
+	return nil
 }
 
 // Bytes results in the slice of bytes with compressed content
@@ -84,12 +169,7 @@ func (c *csvExporter) Len() int {
 }
 
 func (c *csvExporter) FileName(spec execinfrapb.CSVWriterSpec, part string) string {
-	pattern := exportFilePatternDefault
-	if spec.NamePattern != "" {
-		pattern = spec.NamePattern
-	}
-
-	fileName := strings.Replace(pattern, exportFilePatternPart, part, -1)
+	fileName := exportFileName(spec, part, "csv")
 	// TODO: add suffix based on compressor type
 	if c.compressor != nil {
 		fileName += ".gz"
@@ -121,6 +201,8 @@ func newCSVExporter(sp execinfrapb.CSVWriterSpec) *csvExporter {
 	if sp.Options.Comma != 0 {
 		exporter.csvWriter.Comma = sp.Options.Comma
 	}
+	exporter.nullsAs = sp.Options.NullEncoding
+	exporter.f = tree.NewFmtCtx(tree.FmtExport)
 	return exporter
 }
 
@@ -175,22 +257,20 @@ func (sp *csvWriter) Run(ctx context.Context) {
 
 		alloc := &rowenc.DatumAlloc{}
 
-		writer := newCSVExporter(sp.spec)
-
-		var nullsAs string
-		if sp.spec.Options.NullEncoding != nil {
-			nullsAs = *sp.spec.Options.NullEncoding
+		writer, err := newExporter(sp.spec, typs)
+		if err != nil {
+			return err
 		}
-		f := tree.NewFmtCtx(tree.FmtExport)
-		defer f.Close()
 
-		csvRow := make([]string, len(typs))
+		datums := make(tree.Datums, len(typs))
 
 		chunk := 0
 		done := false
 		for {
 			var rows int64
-			writer.ResetBuffer()
+			if err := writer.ResetBuffer(); err != nil {
+				return err
+			}
 			for {
 				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
 					break
@@ -206,23 +286,12 @@ func (sp *csvWriter) Run(ctx context.Context) {
 				rows++
 
 				for i, ed := range row {
-					if ed.IsNull() {
-						if sp.spec.Options.NullEncoding != nil {
-							csvRow[i] = nullsAs
-							continue
-						} else {
-							return errors.New("NULL value encountered during EXPORT, " +
-								"use `WITH nullas` to specify the string representation of NULL")
-						}
-					}
 					if err := ed.EnsureDecoded(typs[i], alloc); err != nil {
 						return err
 					}
-					ed.Datum.Format(f)
-					csvRow[i] = f.String()
-					f.Reset()
+					datums[i] = ed.Datum
 				}
-				if err := writer.Write(csvRow); err != nil {
+				if err := writer.Write(datums); err != nil {
 					return err
 				}
 			}
@@ -230,7 +299,7 @@ func (sp *csvWriter) Run(ctx context.Context) {
 				break
 			}
 			if err := writer.Flush(); err != nil {
-				return errors.Wrap(err, "failed to flush csv writer")
+				return errors.Wrap(err, "failed to flush exporting writer")
 			}
 
 			conf, err := cloudimpl.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User())
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config"
@@ -32,8 +33,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/workload/bank"
 	"github.com/cockroachdb/cockroach/pkg/workload/workloadsql"
+	goparquet "github.com/fraugster/parquet-go"
 	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/require"
 )
@@ -486,3 +489,116 @@ func TestExportPrivileges(t *testing.T) {
 	_, err = testuser.Exec(`EXPORT INTO CSV $1 FROM TABLE privs`, dest)
 	require.NoError(t, err)
 }
+
+func TestExportJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (
+		i INT PRIMARY KEY, d DECIMAL(10, 2), a INT[], j JSONB, ts TIMESTAMPTZ, s STRING
+	)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 1.5, ARRAY[1, NULL, 3], '{"b": [1, 2]}', '2021-01-02 03:04:05.123456+02', 'a"b'),
+		(2, NULL, ARRAY[], 'null', NULL, NULL)`)
+
+	sqlDB.Exec(t, `EXPORT INTO JSON 'nodelocal://0/json' FROM SELECT * FROM foo ORDER BY i`)
+	content := readFileByGlob(t, filepath.Join(dir, "json", "export*-n1.0.json"))
+	require.Equal(t,
+		`{"i": 1, "d": 1.50, "a": [1, null, 3], "j": {"b": [1, 2]}, "ts": "2021-01-02T01:04:05.123456Z", "s": "a\"b"}`+"\n"+
+			`{"i": 2, "d": null, "a": [], "j": null, "ts": null, "s": null}`+"\n",
+		string(content))
+
+	// The files are split into chunks and compressed like CSV files.
+	sqlDB.Exec(t, `EXPORT INTO JSON 'nodelocal://0/json-chunks' WITH chunk_rows = '2', compression = 'gzip'
+		FROM SELECT generate_series(1, 5) AS x`)
+	var lines []string
+	for i := 0; i < 3; i++ {
+		compressed := readFileByGlob(t, filepath.Join(dir, "json-chunks", fmt.Sprintf("export*-n1.%d.json.gz", i)))
+		gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(gzipReader)
+		require.NoError(t, err)
+		require.NoError(t, gzipReader.Close())
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")...)
+	}
+	require.Equal(t, []string{`{"x": 1}`, `{"x": 2}`, `{"x": 3}`, `{"x": 4}`, `{"x": 5}`}, lines)
+
+	sqlDB.ExpectErr(t, "delimiter option is only supported by the CSV format",
+		`EXPORT INTO JSON 'nodelocal://0/json-err' WITH delimiter = '|' FROM SELECT * FROM foo`)
+	sqlDB.ExpectErr(t, `duplicate column name "x"`,
+		`EXPORT INTO JSON 'nodelocal://0/json-err' FROM SELECT 1 AS x, 2 AS x`)
+}
+
+func TestExportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (
+		i INT PRIMARY KEY, d DECIMAL(10, 2), e DECIMAL, a INT[], j JSONB, ts TIMESTAMPTZ, s STRING
+	)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 1.5, 1.2345, ARRAY[1, 3], '{"b": [1, 2]}', '2021-01-02 03:04:05.123456+02', 'abc'),
+		(2, -1.25, NULL, ARRAY[], NULL, NULL, NULL)`)
+
+	for _, tc := range []struct {
+		name string
+		opts string
+	}{
+		{name: "uncompressed"},
+		{name: "gzip", opts: ` WITH compression = 'gzip'`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB.Exec(t, fmt.Sprintf(`EXPORT INTO PARQUET 'nodelocal://0/%s'%s FROM SELECT * FROM foo ORDER BY i`,
+				tc.name, tc.opts))
+			// Parquet files are compressed internally, so they keep their extension.
+			content := readFileByGlob(t, filepath.Join(dir, tc.name, "export*-n1.0.parquet"))
+
+			fr, err := goparquet.NewFileReader(bytes.NewReader(content))
+			require.NoError(t, err)
+			var rows []map[string]interface{}
+			for {
+				row, err := fr.NextRow()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				rows = append(rows, row)
+			}
+			require.Equal(t, []map[string]interface{}{
+				{
+					"i": int64(1),
+					// Decimals with a scale are stored unscaled: 150 is 1.50.
+					"d": []byte{0x00, 0x96},
+					"e": []byte("1.2345"),
+					"a": map[string]interface{}{"list": []map[string]interface{}{
+						{"element": int64(1)}, {"element": int64(3)},
+					}},
+					"j":  []byte(`{"b": [1, 2]}`),
+					"ts": timeutil.ToUnixMicros(time.Date(2021, 1, 2, 1, 4, 5, 123456000, time.UTC)),
+					"s":  []byte("abc"),
+				},
+				{
+					"i": int64(2),
+					// -125 in two's complement.
+					"d": []byte{0x83},
+					"a": map[string]interface{}{},
+				},
+			}, rows)
+		})
+	}
+
+	sqlDB.ExpectErr(t, "nullas option is only supported by the CSV format",
+		`EXPORT INTO PARQUET 'nodelocal://0/parquet-err' WITH nullas = '' FROM SELECT * FROM foo`)
+}
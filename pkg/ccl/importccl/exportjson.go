@@ -0,0 +1,152 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+)
+
+// jsonExporter writes the rows of an EXPORT as newline-delimited JSON: one
+// object per row, keyed by column name. Decimals are written as exact JSON
+// numbers, arrays as JSON arrays, JSONB values as is and TIMESTAMPTZ values in
+// RFC 3339 format in UTC.
+type jsonExporter struct {
+	compressor *gzip.Writer
+	buf        *bytes.Buffer
+	writer     *bufio.Writer
+	colNames   []string
+
+	scratch bytes.Buffer
+}
+
+var _ exporter = &jsonExporter{}
+
+func newJSONExporter(sp execinfrapb.CSVWriterSpec, typs []*types.T) (*jsonExporter, error) {
+	colNames, err := exportColumnNames(sp, typs)
+	if err != nil {
+		return nil, err
+	}
+	e := &jsonExporter{
+		buf:      bytes.NewBuffer([]byte{}),
+		colNames: colNames,
+	}
+	var w io.Writer = e.buf
+	if sp.CompressionCodec == execinfrapb.FileCompression_Gzip {
+		e.compressor = gzip.NewWriter(e.buf)
+		w = e.compressor
+	}
+	e.writer = bufio.NewWriter(w)
+	return e, nil
+}
+
+// Write implements the exporter interface.
+func (e *jsonExporter) Write(row tree.Datums) error {
+	// The object is formatted by hand, rather than built as a JSON object, to
+	// keep its keys in the order of the columns.
+	e.scratch.Reset()
+	e.scratch.WriteByte('{')
+	for i, d := range row {
+		j, err := exportJSONValue(d)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			e.scratch.WriteString(", ")
+		}
+		json.FromString(e.colNames[i]).Format(&e.scratch)
+		e.scratch.WriteString(": ")
+		j.Format(&e.scratch)
+	}
+	e.scratch.WriteString("}\n")
+	_, err := e.writer.Write(e.scratch.Bytes())
+	return err
+}
+
+// Flush implements the exporter interface.
+func (e *jsonExporter) Flush() error {
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	if e.compressor != nil {
+		return e.compressor.Flush()
+	}
+	return nil
+}
+
+// Close implements the exporter interface.
+func (e *jsonExporter) Close() error {
+	if e.compressor != nil {
+		return e.compressor.Close()
+	}
+	return nil
+}
+
+// ResetBuffer implements the exporter interface.
+func (e *jsonExporter) ResetBuffer() error {
+	e.buf.Reset()
+	if e.compressor != nil {
+		e.compressor.Reset(e.buf)
+		e.writer.Reset(e.compressor)
+	} else {
+		e.writer.Reset(e.buf)
+	}
+	return nil
+}
+
+// Bytes implements the exporter interface.
+func (e *jsonExporter) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Len implements the exporter interface.
+func (e *jsonExporter) Len() int {
+	return e.buf.Len()
+}
+
+// FileName implements the exporter interface.
+func (e *jsonExporter) FileName(spec execinfrapb.CSVWriterSpec, part string) string {
+	fileName := exportFileName(spec, part, "json")
+	if e.compressor != nil {
+		fileName += ".gz"
+	}
+	return fileName
+}
+
+// exportJSONValue converts a datum into the JSON value it is exported as.
+// Decimals which are not finite have no JSON number representation, so they
+// are written as strings.
+func exportJSONValue(d tree.Datum) (json.JSON, error) {
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DDecimal:
+		if t.Form != apd.Finite {
+			return json.FromString(t.Decimal.String()), nil
+		}
+	case *tree.DArray:
+		builder := json.NewArrayBuilder(t.Len())
+		for _, e := range t.Array {
+			j, err := exportJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			builder.Add(j)
+		}
+		return builder.Build(), nil
+	}
+	return tree.AsJSON(d, time.UTC)
+}
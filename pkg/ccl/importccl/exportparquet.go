@@ -0,0 +1,321 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+const parquetExportCreator = "cockroachdb export"
+
+// parquetExporter writes the rows of an EXPORT into a parquet file whose
+// schema is derived from the types of the exported columns. Parquet files are
+// compressed internally, so unlike for the other formats compression doesn't
+// change the name of the files.
+type parquetExporter struct {
+	buf      *bytes.Buffer
+	fw       *goparquet.FileWriter
+	schema   *parquetschema.SchemaDefinition
+	codec    parquet.CompressionCodec
+	colNames []string
+	typs     []*types.T
+}
+
+var _ exporter = &parquetExporter{}
+
+func newParquetExporter(
+	sp execinfrapb.CSVWriterSpec, typs []*types.T,
+) (*parquetExporter, error) {
+	colNames, err := exportColumnNames(sp, typs)
+	if err != nil {
+		return nil, err
+	}
+	schema := parquetExportSchema(colNames, typs)
+	if err := schema.ValidateStrict(); err != nil {
+		return nil, errors.Wrap(err, "building parquet schema")
+	}
+	codec := parquet.CompressionCodec_UNCOMPRESSED
+	if sp.CompressionCodec == execinfrapb.FileCompression_Gzip {
+		codec = parquet.CompressionCodec_GZIP
+	}
+	e := &parquetExporter{
+		buf:      bytes.NewBuffer([]byte{}),
+		schema:   schema,
+		codec:    codec,
+		colNames: colNames,
+		typs:     typs,
+	}
+	if err := e.ResetBuffer(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Write implements the exporter interface.
+func (e *parquetExporter) Write(row tree.Datums) error {
+	data := make(map[string]interface{}, len(row))
+	for i, d := range row {
+		v, err := parquetExportValue(e.typs[i], d)
+		if err != nil {
+			return errors.Wrapf(err, "exporting column %q", e.colNames[i])
+		}
+		if v != nil {
+			data[e.colNames[i]] = v
+		}
+	}
+	return e.fw.AddData(data)
+}
+
+// Flush implements the exporter interface. Rows are buffered until the file is
+// closed, which writes them all in a single row group.
+func (e *parquetExporter) Flush() error {
+	return nil
+}
+
+// Close implements the exporter interface.
+func (e *parquetExporter) Close() error {
+	return e.fw.Close()
+}
+
+// ResetBuffer implements the exporter interface.
+func (e *parquetExporter) ResetBuffer() error {
+	e.buf.Reset()
+	e.fw = goparquet.NewFileWriter(e.buf,
+		goparquet.WithCreator(parquetExportCreator),
+		goparquet.WithCompressionCodec(e.codec),
+	)
+	return e.fw.SetSchemaDefinition(e.schema)
+}
+
+// Bytes implements the exporter interface.
+func (e *parquetExporter) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Len implements the exporter interface.
+func (e *parquetExporter) Len() int {
+	return e.buf.Len()
+}
+
+// FileName implements the exporter interface.
+func (e *parquetExporter) FileName(spec execinfrapb.CSVWriterSpec, part string) string {
+	return exportFileName(spec, part, "parquet")
+}
+
+// parquetExportSchema returns the schema of parquet files holding columns of
+// the given names and types. Every column is optional, since any of them can
+// hold NULLs.
+func parquetExportSchema(colNames []string, typs []*types.T) *parquetschema.SchemaDefinition {
+	children := make([]*parquetschema.ColumnDefinition, len(typs))
+	for i, typ := range typs {
+		children[i] = parquetExportColumn(colNames[i], typ)
+	}
+	numChildren := int32(len(children))
+	return parquetschema.SchemaDefinitionFromColumnDefinition(&parquetschema.ColumnDefinition{
+		Children: children,
+		SchemaElement: &parquet.SchemaElement{
+			Name:        "export",
+			NumChildren: &numChildren,
+		},
+	})
+}
+
+// parquetExportColumn returns the definition of an optional parquet column of
+// the given name holding values of the given type. Arrays are written as
+// LIST groups of optional elements.
+func parquetExportColumn(name string, typ *types.T) *parquetschema.ColumnDefinition {
+	if typ.Family() == types.ArrayFamily {
+		element := parquetExportColumn("element", typ.ArrayContents())
+		list := &parquetschema.ColumnDefinition{
+			Children: []*parquetschema.ColumnDefinition{element},
+			SchemaElement: &parquet.SchemaElement{
+				Name:           "list",
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+				NumChildren:    int32Ptr(1),
+			},
+		}
+		logical := parquet.NewLogicalType()
+		logical.LIST = parquet.NewListType()
+		return &parquetschema.ColumnDefinition{
+			Children: []*parquetschema.ColumnDefinition{list},
+			SchemaElement: &parquet.SchemaElement{
+				Name:           name,
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+				NumChildren:    int32Ptr(1),
+				ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+				LogicalType:    logical,
+			},
+		}
+	}
+	elem := parquetExportSchemaElement(typ)
+	elem.Name = name
+	elem.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+	return &parquetschema.ColumnDefinition{SchemaElement: elem}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// parquetExportSchemaElement returns the physical and logical parquet type
+// used for a column of the given scalar type. Types without a close parquet
+// equivalent are written as strings, in the same format as EXPORT INTO CSV.
+func parquetExportSchemaElement(typ *types.T) *parquet.SchemaElement {
+	switch typ.Family() {
+	case types.BoolFamily:
+		return &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BOOLEAN)}
+	case types.IntFamily:
+		logical := parquet.NewLogicalType()
+		logical.INTEGER = &parquet.IntType{BitWidth: 64, IsSigned: true}
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_INT64),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64),
+			LogicalType:   logical,
+		}
+	case types.FloatFamily:
+		return &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_DOUBLE)}
+	case types.DecimalFamily:
+		// Decimals without a precision have no fixed scale, so they can only be
+		// written exactly as strings.
+		if typ.Precision() == 0 {
+			return parquetExportStringSchemaElement()
+		}
+		logical := parquet.NewLogicalType()
+		logical.DECIMAL = &parquet.DecimalType{Precision: typ.Precision(), Scale: typ.Scale()}
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL),
+			Precision:     int32Ptr(typ.Precision()),
+			Scale:         int32Ptr(typ.Scale()),
+			LogicalType:   logical,
+		}
+	case types.BytesFamily:
+		return &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY)}
+	case types.TimestampFamily, types.TimestampTZFamily:
+		logical := parquet.NewLogicalType()
+		logical.TIMESTAMP = parquet.NewTimestampType()
+		logical.TIMESTAMP.IsAdjustedToUTC = typ.Family() == types.TimestampTZFamily
+		logical.TIMESTAMP.Unit = parquet.NewTimeUnit()
+		logical.TIMESTAMP.Unit.MICROS = parquet.NewMicroSeconds()
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_INT64),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS),
+			LogicalType:   logical,
+		}
+	case types.JsonFamily:
+		logical := parquet.NewLogicalType()
+		logical.JSON = parquet.NewJsonType()
+		return &parquet.SchemaElement{
+			Type:          parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+			ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_JSON),
+			LogicalType:   logical,
+		}
+	default:
+		return parquetExportStringSchemaElement()
+	}
+}
+
+func parquetExportStringSchemaElement() *parquet.SchemaElement {
+	logical := parquet.NewLogicalType()
+	logical.STRING = parquet.NewStringType()
+	return &parquet.SchemaElement{
+		Type:          parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+		ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+		LogicalType:   logical,
+	}
+}
+
+// parquetExportValue converts a datum of the given type into the value the
+// parquet writer expects for a column defined by parquetExportColumn, or nil
+// for NULL.
+func parquetExportValue(typ *types.T, d tree.Datum) (interface{}, error) {
+	if d == tree.DNull {
+		return nil, nil
+	}
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DBool:
+		return bool(*t), nil
+	case *tree.DInt:
+		return int64(*t), nil
+	case *tree.DFloat:
+		return float64(*t), nil
+	case *tree.DDecimal:
+		if typ.Precision() == 0 {
+			return []byte(t.Decimal.String()), nil
+		}
+		return parquetDecimalBytes(&t.Decimal, typ.Scale())
+	case *tree.DBytes:
+		return []byte(*t), nil
+	case *tree.DString:
+		return []byte(*t), nil
+	case *tree.DCollatedString:
+		return []byte(t.Contents), nil
+	case *tree.DTimestamp:
+		return timeutil.ToUnixMicros(t.Time), nil
+	case *tree.DTimestampTZ:
+		return timeutil.ToUnixMicros(t.Time), nil
+	case *tree.DJSON:
+		return []byte(t.JSON.String()), nil
+	case *tree.DArray:
+		// The parquet writer mishandles empty lists of elements, so empty arrays
+		// are written as present lists without a list of elements, which is how
+		// they are encoded anyway.
+		if t.Len() == 0 {
+			return map[string]interface{}{}, nil
+		}
+		elems := make([]map[string]interface{}, len(t.Array))
+		for i, e := range t.Array {
+			v, err := parquetExportValue(typ.ArrayContents(), e)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = map[string]interface{}{}
+			if v != nil {
+				elems[i]["element"] = v
+			}
+		}
+		return map[string]interface{}{"list": elems}, nil
+	default:
+		return []byte(tree.AsStringWithFlags(d, tree.FmtExport)), nil
+	}
+}
+
+// parquetDecimalBytes returns the big-endian two's complement encoding of the
+// unscaled value of d at the given scale, which is how parquet stores
+// decimals.
+func parquetDecimalBytes(d *apd.Decimal, scale int32) ([]byte, error) {
+	if d.Form != apd.Finite {
+		return nil, errors.Errorf("cannot write %s as a parquet decimal", d)
+	}
+	var scaled apd.Decimal
+	if _, err := tree.HighPrecisionCtx.Quantize(&scaled, d, -scale); err != nil {
+		return nil, err
+	}
+	n := new(big.Int).Set(&scaled.Coeff)
+	if scaled.Negative {
+		n.Neg(n)
+	}
+	// One more byte than needed for the magnitude leaves room for the sign bit.
+	size := n.BitLen()/8 + 1
+	if n.Sign() < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), uint(8*size)))
+	}
+	return n.FillBytes(make([]byte, size)), nil
+}
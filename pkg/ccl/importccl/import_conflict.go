@@ -0,0 +1,279 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+// conflictResolver handles the imported rows of an IMPORT INTO whose primary
+// key collides with a row of the table, as requested by the on_conflict
+// option.
+//
+// The imported rows are decoded from the KVs of their primary index entries,
+// and the rows with the same primary keys are looked up in a transaction.
+// Rows which do not collide are re-encoded, along with their secondary index
+// entries, and ingested like any other imported row. Colliding rows are either
+// skipped, or written with a regular transactional update which also replaces
+// the secondary index entries of the existing row.
+//
+// Of several imported rows with the same primary key, only the last one is
+// upserted, and only the first one is kept when colliding rows are skipped.
+// Within a batch, such rows are collapsed before being looked up. The rows
+// ingested from earlier batches are flushed before a batch is resolved, so
+// that they are resolved like the rows which existed before the import.
+type conflictResolver struct {
+	db        *kv.DB
+	codec     keys.SQLCodec
+	tableDesc catalog.TableDescriptor
+	mode      roachpb.IOFileFormat_OnConflict
+
+	// fetcher decodes the primary index KVs of a single row into datums
+	// ordered like the public columns of the table.
+	fetcher row.Fetcher
+	ri      row.Inserter
+	ru      row.Updater
+	// updateColOrds are the ordinals of the public columns which are not part
+	// of the primary key, i.e. the columns updated by an upsert.
+	updateColOrds []int
+	alloc         rowenc.DatumAlloc
+
+	// upserted is the number of existing rows overwritten so far.
+	upserted int64
+}
+
+func newConflictResolver(
+	ctx context.Context,
+	db *kv.DB,
+	codec keys.SQLCodec,
+	tableDesc catalog.TableDescriptor,
+	mode roachpb.IOFileFormat_OnConflict,
+) (*conflictResolver, error) {
+	r := &conflictResolver{
+		db:        db,
+		codec:     codec,
+		tableDesc: tableDesc,
+		mode:      mode,
+	}
+
+	var pkCols catalog.TableColSet
+	for i := 0; i < tableDesc.GetPrimaryIndex().NumColumns(); i++ {
+		pkCols.Add(tableDesc.GetPrimaryIndex().GetColumnID(i))
+	}
+	cols := make([]descpb.ColumnDescriptor, len(tableDesc.PublicColumns()))
+	var updateCols []descpb.ColumnDescriptor
+	var colIdxMap catalog.TableColMap
+	var valNeededForCol util.FastIntSet
+	for i, col := range tableDesc.PublicColumns() {
+		cols[i] = *col.ColumnDesc()
+		colIdxMap.Set(col.GetID(), i)
+		valNeededForCol.Add(i)
+		if !pkCols.Contains(col.GetID()) {
+			updateCols = append(updateCols, cols[i])
+			r.updateColOrds = append(r.updateColOrds, i)
+		}
+	}
+
+	if err := r.fetcher.Init(
+		ctx,
+		codec,
+		false, /* reverse */
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		false, /* isCheck */
+		&r.alloc,
+		nil, /* memMonitor */
+		row.FetcherTableArgs{
+			Spans:            tableDesc.AllIndexSpans(codec),
+			Desc:             tableDesc,
+			Index:            tableDesc.GetPrimaryIndex().IndexDesc(),
+			ColIdxMap:        colIdxMap,
+			IsSecondaryIndex: false,
+			Cols:             cols,
+			ValNeededForCol:  valNeededForCol,
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if r.ri, err = row.MakeInserter(ctx, nil /* txn */, codec, tableDesc, cols, &r.alloc); err != nil {
+		return nil, errors.Wrap(err, "make row inserter")
+	}
+	if r.ru, err = row.MakeUpdater(
+		ctx, nil /* txn */, codec, tableDesc, updateCols, cols, row.UpdaterDefault, &r.alloc,
+	); err != nil {
+		return nil, errors.Wrap(err, "make row updater")
+	}
+	return r, nil
+}
+
+// resolve looks up the rows of a batch of imported KVs in the table, skips or
+// upserts the rows colliding with an existing row, and returns the KVs of the
+// remaining rows, which are to be ingested.
+func (r *conflictResolver) resolve(
+	ctx context.Context, kvs []roachpb.KeyValue,
+) ([]roachpb.KeyValue, error) {
+	// Group the primary index KVs by row. The secondary index KVs are dropped
+	// since they are encoded again for the rows which are ingested.
+	//
+	// The KVs of a row are consecutive and ordered by column family, so a KV
+	// which does not sort after the previous one of the same row starts another
+	// row with the same primary key. Such a row replaces the earlier one when
+	// upserting, and is dropped otherwise.
+	var rowKeys []roachpb.Key
+	var rowKVs [][]roachpb.KeyValue
+	rowIdx := make(map[string]int)
+	// cur is the index in rowKVs of the row the last KV was added to, or -1
+	// if that row is dropped.
+	cur := -1
+	var prevRowKey, prevKey roachpb.Key
+	for _, kv := range kvs {
+		_, _, indexID, err := r.codec.DecodeIndexPrefix(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		if descpb.IndexID(indexID) != r.tableDesc.GetPrimaryIndexID() {
+			continue
+		}
+		n, err := keys.GetRowPrefixLength(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		rowKey := kv.Key[:n]
+		sameRow := prevRowKey.Equal(rowKey) && prevKey.Compare(kv.Key) < 0
+		prevRowKey, prevKey = rowKey, kv.Key
+		if sameRow {
+			if cur >= 0 {
+				rowKVs[cur] = append(rowKVs[cur], kv)
+			}
+			continue
+		}
+		if i, ok := rowIdx[string(rowKey)]; ok {
+			cur = -1
+			if r.mode == roachpb.IOFileFormat_Upsert {
+				cur = i
+				rowKVs[i] = []roachpb.KeyValue{kv}
+			}
+			continue
+		}
+		cur = len(rowKVs)
+		rowIdx[string(rowKey)] = cur
+		rowKeys = append(rowKeys, rowKey)
+		rowKVs = append(rowKVs, []roachpb.KeyValue{kv})
+	}
+
+	newRows := make([]tree.Datums, len(rowKVs))
+	for i := range rowKVs {
+		var err error
+		if newRows[i], err = r.decodeRow(ctx, rowKVs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var ingest []roachpb.KeyValue
+	var upserted int64
+	if err := r.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		ingest, upserted = nil, 0
+
+		existing := txn.NewBatch()
+		for _, key := range rowKeys {
+			existing.Scan(key, key.PrefixEnd())
+		}
+		if err := txn.Run(ctx, existing); err != nil {
+			return err
+		}
+
+		// The partial indexes are not maintained by IMPORT, which rejects the
+		// tables that have any.
+		var pm row.PartialIndexUpdateHelper
+		updates := txn.NewBatch()
+		for i, res := range existing.Results {
+			if len(res.Rows) == 0 {
+				if err := r.ri.InsertRow(
+					ctx,
+					row.KVInserter(func(kv roachpb.KeyValue) {
+						kv.Value.InitChecksum(kv.Key)
+						ingest = append(ingest, kv)
+					}),
+					newRows[i],
+					pm,
+					true,  /* overwrite */
+					false, /* traceKV */
+				); err != nil {
+					return errors.Wrap(err, "insert row")
+				}
+				continue
+			}
+			if r.mode != roachpb.IOFileFormat_Upsert || len(r.updateColOrds) == 0 {
+				continue
+			}
+
+			existingKVs := make([]roachpb.KeyValue, len(res.Rows))
+			for j, kv := range res.Rows {
+				existingKVs[j] = roachpb.KeyValue{Key: kv.Key, Value: *kv.Value}
+			}
+			oldValues, err := r.decodeRow(ctx, existingKVs)
+			if err != nil {
+				return err
+			}
+			updateValues := make(tree.Datums, len(r.updateColOrds))
+			for j, ord := range r.updateColOrds {
+				updateValues[j] = newRows[i][ord]
+			}
+			if _, err := r.ru.UpdateRow(
+				ctx, updates, oldValues, updateValues, pm, false, /* traceKV */
+			); err != nil {
+				return errors.Wrap(err, "upsert row")
+			}
+			upserted++
+		}
+		return txn.CommitInBatch(ctx, updates)
+	}); err != nil {
+		return nil, err
+	}
+	r.upserted += upserted
+	return ingest, nil
+}
+
+// decodeRow decodes the primary index KVs of a single row.
+func (r *conflictResolver) decodeRow(
+	ctx context.Context, kvs []roachpb.KeyValue,
+) (tree.Datums, error) {
+	if err := r.fetcher.StartScanFrom(ctx, &row.SpanKVFetcher{KVs: kvs}); err != nil {
+		return nil, err
+	}
+	datums, _, _, err := r.fetcher.NextRowDecoded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if datums == nil {
+		return nil, errors.AssertionFailedf("no row decoded from %d KVs", len(kvs))
+	}
+	// The datums are only valid until the next row is decoded.
+	return append(tree.Datums(nil), datums...), nil
+}
+
+// summary returns the entries written for the upserted rows, which are not
+// accounted for by the BulkAdders.
+func (r *conflictResolver) summary() roachpb.BulkOpSummary {
+	pkID := roachpb.BulkOpSummaryID(uint64(r.tableDesc.GetID()), uint64(r.tableDesc.GetPrimaryIndexID()))
+	return roachpb.BulkOpSummary{EntryCounts: map[uint64]int64{pkID: r.upserted}}
+}
@@ -10,9 +10,12 @@ package importccl_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -21,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -163,6 +167,166 @@ func TestProtectedTimestampsDuringImportInto(t *testing.T) {
 	})
 }
 
+// TestImportIntoOnConflict tests that IMPORT INTO with the on_conflict option
+// upserts or skips the imported rows colliding with existing rows, and keeps
+// the secondary indexes consistent.
+func TestImportIntoOnConflict(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data.csv"), []byte("2,x,200\n3,y,\n4,z,400\n"), 0644))
+	const data = "nodelocal://0/data.csv"
+	createTable := func(name string) {
+		sqlDB.Exec(t, fmt.Sprintf(`CREATE TABLE %s (
+			k INT PRIMARY KEY, v STRING, n INT, INDEX v_idx (v), FAMILY (k, v), FAMILY (n)
+		)`, name))
+		sqlDB.Exec(t, fmt.Sprintf(`INSERT INTO %s VALUES (1, 'a', 10), (2, 'b', 20), (3, 'c', 30)`, name))
+	}
+
+	t.Run("upsert", func(t *testing.T) {
+		createTable("upserted")
+		sqlDB.Exec(t, `IMPORT INTO upserted CSV DATA ($1) WITH on_conflict = 'upsert', nullif = ''`, data)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM upserted ORDER BY k`, [][]string{
+			{"1", "a", "10"}, {"2", "x", "200"}, {"3", "y", "NULL"}, {"4", "z", "400"},
+		})
+		// The index entries of the overwritten rows are replaced.
+		sqlDB.CheckQueryResults(t, `SELECT v, k FROM upserted@v_idx ORDER BY v`, [][]string{
+			{"a", "1"}, {"x", "2"}, {"y", "3"}, {"z", "4"},
+		})
+	})
+
+	t.Run("ignore", func(t *testing.T) {
+		createTable("ignored")
+		sqlDB.Exec(t, `IMPORT INTO ignored CSV DATA ($1) WITH on_conflict = 'ignore', nullif = ''`, data)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM ignored ORDER BY k`, [][]string{
+			{"1", "a", "10"}, {"2", "b", "20"}, {"3", "c", "30"}, {"4", "z", "400"},
+		})
+		sqlDB.CheckQueryResults(t, `SELECT v, k FROM ignored@v_idx ORDER BY v`, [][]string{
+			{"a", "1"}, {"b", "2"}, {"c", "3"}, {"z", "4"},
+		})
+	})
+
+	// Of several imported rows with the same primary key, the last one is
+	// upserted, and the first one is kept when colliding rows are skipped.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "repeated.csv"),
+		[]byte("2,x,200\n4,z,400\n2,w,\n4,q,401\n5,u,500\n5,v,\n"), 0644))
+	const repeated = "nodelocal://0/repeated.csv"
+
+	// The rows with the same primary key are resolved alike whether they are
+	// in the same batch of KVs or in different ones.
+	for _, batchSize := range []int{1000, 1} {
+		name := "same-batch"
+		if batchSize == 1 {
+			name = "across-batches"
+		}
+
+		t.Run("upsert-repeated-keys/"+name, func(t *testing.T) {
+			defer row.TestingSetDatumRowConverterBatchSize(batchSize)()
+			table := "upserted_repeated_" + strings.ReplaceAll(name, "-", "_")
+			createTable(table)
+			sqlDB.Exec(t, fmt.Sprintf(`IMPORT INTO %s CSV DATA ($1) WITH on_conflict = 'upsert', nullif = ''`, table), repeated)
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT * FROM %s ORDER BY k`, table), [][]string{
+				{"1", "a", "10"}, {"2", "w", "NULL"}, {"3", "c", "30"}, {"4", "q", "401"}, {"5", "v", "NULL"},
+			})
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT v, k FROM %s@v_idx ORDER BY v`, table), [][]string{
+				{"a", "1"}, {"c", "3"}, {"q", "4"}, {"v", "5"}, {"w", "2"},
+			})
+		})
+
+		t.Run("ignore-repeated-keys/"+name, func(t *testing.T) {
+			defer row.TestingSetDatumRowConverterBatchSize(batchSize)()
+			table := "ignored_repeated_" + strings.ReplaceAll(name, "-", "_")
+			createTable(table)
+			sqlDB.Exec(t, fmt.Sprintf(`IMPORT INTO %s CSV DATA ($1) WITH on_conflict = 'ignore', nullif = ''`, table), repeated)
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT * FROM %s ORDER BY k`, table), [][]string{
+				{"1", "a", "10"}, {"2", "b", "20"}, {"3", "c", "30"}, {"4", "z", "400"}, {"5", "u", "500"},
+			})
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT v, k FROM %s@v_idx ORDER BY v`, table), [][]string{
+				{"a", "1"}, {"b", "2"}, {"c", "3"}, {"u", "5"}, {"z", "4"},
+			})
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		createTable("invalid")
+		sqlDB.ExpectErr(t, `invalid on_conflict value "replace"`,
+			`IMPORT INTO invalid CSV DATA ($1) WITH on_conflict = 'replace'`, data)
+		sqlDB.ExpectErr(t, `on_conflict option is only supported by IMPORT INTO`,
+			`IMPORT TABLE created (k INT PRIMARY KEY, v STRING, n INT) CSV DATA ($1) WITH on_conflict = 'upsert'`,
+			data)
+	})
+}
+
+func TestImportIntoRejectedRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	// The second row cannot be parsed, the third one has too many fields and
+	// the fourth one violates the NOT NULL constraint.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "dirty.csv"),
+		[]byte("1,a\nb,b\n3,c,c\n4,\n5,e\n"), 0644))
+	const data = "nodelocal://0/dirty.csv"
+	createTable := func(name string) {
+		sqlDB.Exec(t, fmt.Sprintf(`CREATE TABLE %s (k INT PRIMARY KEY, v STRING NOT NULL)`, name))
+	}
+
+	t.Run("rejected-rows-dir", func(t *testing.T) {
+		createTable("t")
+		sqlDB.Exec(t, `IMPORT INTO t CSV DATA ($1) WITH nullif = '', rejected_rows_dir = 'nodelocal://0/rejected'`,
+			data)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM t ORDER BY k`, [][]string{{"1", "a"}, {"5", "e"}})
+
+		rejected, err := ioutil.ReadFile(filepath.Join(dir, "rejected", "dirty.csv.0.rejected"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(rejected), "\n"), "\n")
+		require.Len(t, lines, 3)
+		for i, expected := range []struct {
+			row  int64
+			data string
+			err  string
+		}{
+			{row: 2, data: "b,b", err: `parse "k" as INT8`},
+			{row: 3, data: "3,c,c", err: "expected 2 fields, got 3"},
+			{row: 4, data: "4,", err: `null value in column "v" violates not-null constraint`},
+		} {
+			var rec struct {
+				Row   int64
+				Error string
+				Data  string
+			}
+			require.NoError(t, json.Unmarshal([]byte(lines[i]), &rec))
+			require.Equal(t, expected.row, rec.Row)
+			require.Equal(t, expected.data, rec.Data)
+			require.Contains(t, rec.Error, expected.err)
+		}
+	})
+
+	t.Run("max-row-errors", func(t *testing.T) {
+		createTable("limited")
+		sqlDB.ExpectErr(t, `too many parsing errors \(3\) encountered for file`,
+			`IMPORT INTO limited CSV DATA ($1) WITH nullif = '', max_row_errors = '2'`, data)
+		sqlDB.Exec(t, `IMPORT INTO limited CSV DATA ($1) WITH nullif = '', max_row_errors = '3'`, data)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM limited ORDER BY k`, [][]string{{"1", "a"}, {"5", "e"}})
+
+		sqlDB.ExpectErr(t, `max_row_errors must be > 0`,
+			`IMPORT INTO limited CSV DATA ($1) WITH max_row_errors = '0'`, data)
+		sqlDB.ExpectErr(t, `invalid option "max_row_errors" specified for PGCOPY import format`,
+			`IMPORT INTO limited PGCOPY DATA ($1) WITH max_row_errors = '1'`, data)
+	})
+}
+
 func getFirstStoreReplica(
 	t *testing.T, s serverutils.TestServerInterface, key roachpb.Key,
 ) (*kvserver.Store, *kvserver.Replica) {
@@ -243,6 +243,14 @@ func makeInputConverter(
 		return newAvroInputReader(
 			kvCh, singleTable, spec.Format.Avro, spec.WalltimeNanos,
 			int(spec.ReaderParallelism), evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		return newParquetInputReader(
+			kvCh, singleTable, singleTableTargetCols, spec.Format.Parquet, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), evalCtx)
+	case roachpb.IOFileFormat_NDJSON:
+		return newNDJSONInputReader(
+			kvCh, singleTable, singleTableTargetCols, spec.Format.Ndjson, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), evalCtx)
 	default:
 		return nil, errors.Errorf(
 			"Requested IMPORT format (%d) not supported by this node", spec.Format.Format)
@@ -304,6 +312,22 @@ func ingestKvs(
 	}
 	defer indexAdder.Close(ctx)
 
+	// Rows colliding with existing rows are resolved before the KVs of the other
+	// rows are handed to the BulkAdders, which do not allow shadowing.
+	var resolver *conflictResolver
+	if spec.Format.OnConflict != roachpb.IOFileFormat_Fail {
+		if len(spec.Tables) != 1 {
+			return nil, errors.AssertionFailedf("%s requires a single table", spec.Format.OnConflict)
+		}
+		for _, table := range spec.Tables {
+			resolver, err = newConflictResolver(
+				ctx, flowCtx.Cfg.DB, flowCtx.Codec(), tabledesc.NewImmutable(*table.Desc), spec.Format.OnConflict)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Setup progress tracking:
 	//  - offsets maps source file IDs to offsets in the slices below.
 	//  - writtenRow contains LastRow of batch most recently added to the buffer.
@@ -407,7 +431,23 @@ func ingestKvs(
 		// results in flushing a much larger number of small SSTs. This increases the
 		// number of L0 (and total) files, but with a lower memory usage.
 		for kvBatch := range kvCh {
-			for _, kv := range kvBatch.KVs {
+			kvs := kvBatch.KVs
+			if resolver != nil {
+				// The rows ingested from the previous batches are flushed before
+				// resolving the rows of this batch, so that the rows with the same
+				// primary key in different batches are resolved like colliding rows.
+				if err := pkIndexAdder.Flush(ctx); err != nil {
+					return err
+				}
+				if err := indexAdder.Flush(ctx); err != nil {
+					return err
+				}
+				var err error
+				if kvs, err = resolver.resolve(ctx, kvs); err != nil {
+					return err
+				}
+			}
+			for _, kv := range kvs {
 				_, _, indexID, indexErr := flowCtx.Codec().DecodeIndexPrefix(kv.Key)
 				if indexErr != nil {
 					return indexErr
@@ -466,6 +506,9 @@ func ingestKvs(
 
 	addedSummary := pkIndexAdder.GetSummary()
 	addedSummary.Add(indexAdder.GetSummary())
+	if resolver != nil {
+		addedSummary.Add(resolver.summary())
+	}
 	return &addedSummary, nil
 }
 
@@ -46,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -80,13 +81,21 @@ const (
 	importOptionSkipFKs          = "skip_foreign_keys"
 	importOptionDisableGlobMatch = "disable_glob_matching"
 	importOptionSaveRejected     = "experimental_save_rejected"
+	importOptionOnConflict       = "on_conflict"
+	importOptionMaxRowErrors     = "max_row_errors"
+	importOptionRejectedRowsDir  = "rejected_rows_dir"
 
 	pgCopyDelimiter = "delimiter"
 	pgCopyNull      = "nullif"
 
+	// Skip the statements of a PGDUMP file which cannot be imported.
+	pgDumpIgnoreAllUnsupported = "ignore_unsupported_statements"
+	// Directory the skipped statements of a PGDUMP file are logged to.
+	pgDumpIgnoreShuntFileDest = "log_ignored_statements"
+
 	optMaxRowSize = "max_row_size"
 
-	// Turn on strict validation when importing avro records.
+	// Turn on strict validation when importing avro records or parquet files.
 	avroStrict = "strict_validation"
 	// Default input format is assumed to be OCF (object container file).
 	// This default can be changed by specified either of these options.
@@ -99,6 +108,16 @@ const (
 	avroSchema    = "schema"
 	avroSchemaURI = "schema_uri"
 
+	// Name of a JSONB column to store each whole NDJSON document in.
+	ndjsonDocumentColumn = "document_column"
+
+	// Resolved timestamp of a changefeed up to which its row changes are
+	// applied; defaults to its latest resolved timestamp.
+	changefeedResolved = "resolved"
+	// Topic of the changefeed data files which are applied; defaults to the
+	// name of the table imported into.
+	changefeedTopic = "topic"
+
 	// RunningStatusImportBundleParseSchema indicates to the user that a bundle format
 	// schema is being parsed
 	runningStatusImportBundleParseSchema jobs.RunningStatus = "parsing schema on Import Bundle"
@@ -121,18 +140,30 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	importOptionDecompress:   sql.KVStringOptRequireValue,
 	importOptionOversample:   sql.KVStringOptRequireValue,
 	importOptionSaveRejected: sql.KVStringOptRequireNoValue,
+	importOptionOnConflict:   sql.KVStringOptRequireValue,
+
+	importOptionMaxRowErrors:    sql.KVStringOptRequireValue,
+	importOptionRejectedRowsDir: sql.KVStringOptRequireValue,
 
 	importOptionSkipFKs:          sql.KVStringOptRequireNoValue,
 	importOptionDisableGlobMatch: sql.KVStringOptRequireNoValue,
 
 	optMaxRowSize: sql.KVStringOptRequireValue,
 
+	pgDumpIgnoreAllUnsupported: sql.KVStringOptRequireNoValue,
+	pgDumpIgnoreShuntFileDest:  sql.KVStringOptRequireValue,
+
 	avroStrict:             sql.KVStringOptRequireNoValue,
 	avroSchema:             sql.KVStringOptRequireValue,
 	avroSchemaURI:          sql.KVStringOptRequireValue,
 	avroRecordsSeparatedBy: sql.KVStringOptRequireValue,
 	avroBinRecords:         sql.KVStringOptRequireNoValue,
 	avroJSONRecords:        sql.KVStringOptRequireNoValue,
+
+	ndjsonDocumentColumn: sql.KVStringOptRequireValue,
+
+	changefeedResolved: sql.KVStringOptRequireValue,
+	changefeedTopic:    sql.KVStringOptRequireValue,
 }
 
 func makeStringSet(opts ...string) map[string]struct{} {
@@ -146,7 +177,7 @@ func makeStringSet(opts ...string) map[string]struct{} {
 // Options common to all formats.
 var allowedCommonOptions = makeStringSet(
 	importOptionSSTSize, importOptionDecompress, importOptionOversample,
-	importOptionSaveRejected, importOptionDisableGlobMatch)
+	importOptionSaveRejected, importOptionDisableGlobMatch, importOptionOnConflict)
 
 // Format specific allowed options.
 var avroAllowedOptions = makeStringSet(
@@ -155,14 +186,25 @@ var avroAllowedOptions = makeStringSet(
 )
 var csvAllowedOptions = makeStringSet(
 	csvDelimiter, csvComment, csvNullIf, csvSkip, csvStrictQuotes, csvRowLimit,
+	importOptionMaxRowErrors, importOptionRejectedRowsDir,
 )
 var mysqlOutAllowedOptions = makeStringSet(
 	mysqlOutfileRowSep, mysqlOutfileFieldSep, mysqlOutfileEnclose,
 	mysqlOutfileEscape, csvNullIf, csvSkip, csvRowLimit,
+	importOptionMaxRowErrors, importOptionRejectedRowsDir,
 )
 var mysqlDumpAllowedOptions = makeStringSet(importOptionSkipFKs, csvRowLimit)
 var pgCopyAllowedOptions = makeStringSet(pgCopyDelimiter, pgCopyNull, optMaxRowSize)
-var pgDumpAllowedOptions = makeStringSet(optMaxRowSize, importOptionSkipFKs, csvRowLimit)
+var pgDumpAllowedOptions = makeStringSet(
+	optMaxRowSize, importOptionSkipFKs, csvRowLimit,
+	pgDumpIgnoreAllUnsupported, pgDumpIgnoreShuntFileDest,
+)
+var parquetAllowedOptions = makeStringSet(avroStrict, csvRowLimit)
+var ndjsonAllowedOptions = makeStringSet(
+	avroStrict, ndjsonDocumentColumn, optMaxRowSize, csvRowLimit,
+	importOptionMaxRowErrors, importOptionRejectedRowsDir,
+)
+var changefeedAllowedOptions = makeStringSet(changefeedResolved, changefeedTopic)
 
 // DROP is required because the target table needs to be take offline during
 // IMPORT INTO.
@@ -170,10 +212,13 @@ var importIntoRequiredPrivileges = []privilege.Kind{privilege.INSERT, privilege.
 
 // File formats supported for IMPORT INTO
 var allowedIntoFormats = map[string]struct{}{
-	"CSV":       {},
-	"AVRO":      {},
-	"DELIMITED": {},
-	"PGCOPY":    {},
+	"CSV":        {},
+	"AVRO":       {},
+	"DELIMITED":  {},
+	"PGCOPY":     {},
+	"PARQUET":    {},
+	"NDJSON":     {},
+	"CHANGEFEED": {},
 }
 
 // featureImportEnabled is used to enable and disable the IMPORT feature.
@@ -218,6 +263,13 @@ func importJobDescription(
 	stmt.Options = nil
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
+		if k == importOptionRejectedRowsDir || k == pgDumpIgnoreShuntFileDest {
+			clean, err := cloudimpl.SanitizeExternalStorageURI(v, nil /* extraParams */)
+			if err != nil {
+				return "", err
+			}
+			v = clean
+		}
 		val := importOptionExpectValues[k] == sql.KVStringOptRequireValue
 		val = val || (importOptionExpectValues[k] == sql.KVStringOptAny && len(v) > 0)
 		if val {
@@ -332,6 +384,23 @@ func importPlanHook(
 				}
 			}
 		}
+		for _, opt := range []string{importOptionRejectedRowsDir, pgDumpIgnoreShuntFileDest} {
+			dir, ok := opts[opt]
+			if !ok {
+				continue
+			}
+			hasExplicitAuth, uriScheme, err := cloud.AccessIsWithExplicitAuth(dir)
+			if err != nil {
+				return err
+			}
+			if !hasExplicitAuth {
+				err := p.RequireAdminRole(ctx,
+					fmt.Sprintf("IMPORT %s to the specified %s URI", opt, uriScheme))
+				if err != nil {
+					return err
+				}
+			}
+		}
 
 		var files []string
 		if _, ok := opts[importOptionDisableGlobMatch]; ok {
@@ -618,6 +687,8 @@ func importPlanHook(
 				}
 				format.PgDump.RowLimit = int64(rowLimit)
 			}
+			_, format.PgDump.IgnoreUnsupported = opts[pgDumpIgnoreAllUnsupported]
+			format.PgDump.IgnoreUnsupportedLog = opts[pgDumpIgnoreShuntFileDest]
 		case "AVRO":
 			if err = validateFormatOptions(importStmt.FileFormat, opts, avroAllowedOptions); err != nil {
 				return err
@@ -626,6 +697,79 @@ func importPlanHook(
 			if err != nil {
 				return err
 			}
+		case "PARQUET":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, parquetAllowedOptions); err != nil {
+				return err
+			}
+			format.Format = roachpb.IOFileFormat_Parquet
+			_, format.Parquet.StrictMode = opts[avroStrict]
+			if override, ok := opts[csvRowLimit]; ok {
+				rowLimit, err := strconv.Atoi(override)
+				if err != nil {
+					return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+				}
+				if rowLimit <= 0 {
+					return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+				}
+				format.Parquet.RowLimit = int64(rowLimit)
+			}
+		case "NDJSON":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, ndjsonAllowedOptions); err != nil {
+				return err
+			}
+			format.Format = roachpb.IOFileFormat_NDJSON
+			_, format.Ndjson.StrictMode = opts[avroStrict]
+			format.Ndjson.DocumentColumn = opts[ndjsonDocumentColumn]
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
+			maxRowSize := int32(defaultScanBuffer)
+			if override, ok := opts[optMaxRowSize]; ok {
+				sz, err := humanizeutil.ParseBytes(override)
+				if err != nil {
+					return err
+				}
+				if sz < 1 || sz > math.MaxInt32 {
+					return errors.Errorf("%d out of range: %d", maxRowSize, sz)
+				}
+				maxRowSize = int32(sz)
+			}
+			format.Ndjson.MaxRowSize = maxRowSize
+			if override, ok := opts[csvRowLimit]; ok {
+				rowLimit, err := strconv.Atoi(override)
+				if err != nil {
+					return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+				}
+				if rowLimit <= 0 {
+					return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+				}
+				format.Ndjson.RowLimit = int64(rowLimit)
+			}
+		case "CHANGEFEED":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, changefeedAllowedOptions); err != nil {
+				return err
+			}
+			// The row changes are applied to the existing rows, and cannot be
+			// rejected.
+			for _, opt := range []string{importOptionSaveRejected, importOptionOnConflict} {
+				if _, ok := opts[opt]; ok {
+					return errors.Errorf("invalid option %q specified for %s import format", opt, importStmt.FileFormat)
+				}
+			}
+			if !importStmt.Into {
+				return errors.Errorf("%s format is only supported by IMPORT INTO", importStmt.FileFormat)
+			}
+			if len(files) != 1 {
+				return errors.Errorf("%s format requires a single changefeed output directory", importStmt.FileFormat)
+			}
+			format.Format = roachpb.IOFileFormat_Changefeed
+			if override, ok := opts[changefeedResolved]; ok {
+				if _, err := parseChangefeedResolved(override); err != nil {
+					return pgerror.Wrapf(err, pgcode.InvalidParameterValue, "invalid %s value", changefeedResolved)
+				}
+				format.Changefeed.Resolved = override
+			}
+			format.Changefeed.Topic = opts[changefeedTopic]
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -667,7 +811,43 @@ func importPlanHook(
 			if !found {
 				return unimplemented.Newf("import.compression", "unsupported compression value: %q", override)
 			}
+			// Parquet files are compressed internally and need to be read at
+			// arbitrary offsets, so they cannot be decompressed as a whole.
+			if format.Format == roachpb.IOFileFormat_Parquet &&
+				format.Compression != roachpb.IOFileFormat_Auto && format.Compression != roachpb.IOFileFormat_None {
+				return errors.Errorf("%s option cannot be used with PARQUET files", importOptionDecompress)
+			}
+		}
+
+		if override, ok := opts[importOptionOnConflict]; ok {
+			if !importStmt.Into {
+				return errors.Errorf("%s option is only supported by IMPORT INTO", importOptionOnConflict)
+			}
+			found := false
+			for name, value := range roachpb.IOFileFormat_OnConflict_value {
+				if strings.EqualFold(name, override) {
+					format.OnConflict = roachpb.IOFileFormat_OnConflict(value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return pgerror.Newf(pgcode.InvalidParameterValue,
+					"invalid %s value %q, expected 'upsert' or 'ignore'", importOptionOnConflict, override)
+			}
+		}
+
+		if override, ok := opts[importOptionMaxRowErrors]; ok {
+			maxRowErrors, err := strconv.ParseInt(override, 10, 64)
+			if err != nil {
+				return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", importOptionMaxRowErrors)
+			}
+			if maxRowErrors <= 0 {
+				return pgerror.Newf(pgcode.InvalidParameterValue, "%s must be > 0", importOptionMaxRowErrors)
+			}
+			format.MaxRowErrors = maxRowErrors
 		}
+		format.RejectedRowsDir = opts[importOptionRejectedRowsDir]
 
 		var tableDetails []jobspb.ImportDetails_Table
 		var tableDescs []*tabledesc.Mutable // parallel with tableDetails
@@ -714,6 +894,36 @@ func importPlanHook(
 				return pgerror.New(pgcode.FeatureNotSupported, "Cannot use IMPORT INTO with interleaved tables")
 			}
 
+			// Resolving conflicts requires decoding the existing rows, whose
+			// virtual columns are not stored.
+			if format.OnConflict != roachpb.IOFileFormat_Fail {
+				for _, col := range found.PublicColumns() {
+					if col.IsVirtual() {
+						return unimplemented.Newf("import.on-conflict.virtual",
+							"%s option cannot be used with virtual computed columns", importOptionOnConflict)
+					}
+				}
+			}
+
+			// The changefeed rows are applied whole, with the existing rows decoded
+			// like for on_conflict. Changefeeds on tables with several column
+			// families emit the columns of each family separately.
+			if format.Format == roachpb.IOFileFormat_Changefeed {
+				if len(importStmt.IntoCols) > 0 {
+					return errors.Errorf("target columns cannot be specified with the %s format", importStmt.FileFormat)
+				}
+				if len(found.GetFamilies()) > 1 {
+					return unimplemented.Newf("import.changefeed.families",
+						"%s format cannot be used with tables with multiple column families", importStmt.FileFormat)
+				}
+				for _, col := range found.PublicColumns() {
+					if col.IsVirtual() {
+						return unimplemented.Newf("import.changefeed.virtual",
+							"%s format cannot be used with virtual computed columns", importStmt.FileFormat)
+					}
+				}
+			}
+
 			// Validate target columns.
 			var intoCols []string
 			var isTargetCol = make(map[string]bool)
@@ -1262,9 +1472,11 @@ func parseAndCreateBundleTableDescs(
 	format roachpb.IOFileFormat,
 	walltime int64,
 	owner security.SQLUsername,
-) ([]*tabledesc.Mutable, error) {
+	jobID int64,
+) ([]*tabledesc.Mutable, []string, error) {
 
 	var tableDescs []*tabledesc.Mutable
+	var deferredStmts []string
 	var tableName string
 
 	// A single table entry in the import job details when importing a bundle format
@@ -1276,18 +1488,18 @@ func parseAndCreateBundleTableDescs(
 
 	store, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, files[0], p.User())
 	if err != nil {
-		return tableDescs, err
+		return tableDescs, deferredStmts, err
 	}
 	defer store.Close()
 
 	raw, err := store.ReadFile(ctx, "")
 	if err != nil {
-		return tableDescs, err
+		return tableDescs, deferredStmts, err
 	}
 	defer raw.Close()
 	reader, err := decompressingReader(raw, files[0], format.Compression)
 	if err != nil {
-		return tableDescs, err
+		return tableDescs, deferredStmts, err
 	}
 	defer reader.Close()
 
@@ -1298,20 +1510,26 @@ func parseAndCreateBundleTableDescs(
 		tableDescs, err = readMysqlCreateTable(ctx, reader, evalCtx, p, defaultCSVTableID, parentID, tableName, fks, seqVals, owner, walltime)
 	case roachpb.IOFileFormat_PgDump:
 		evalCtx := &p.ExtendedEvalContext().EvalContext
-		tableDescs, err = readPostgresCreateTable(ctx, reader, evalCtx, p, tableName, parentID, walltime, fks, int(format.PgDump.MaxRowSize), owner)
+		unsupportedStmtLogger := makeUnsupportedStmtLogger(
+			ctx, owner, jobID, "ignored_statements", format.PgDump,
+			p.ExecCfg().DistSQLSrv.ExternalStorageFromURI)
+		tableDescs, deferredStmts, err = readPostgresCreateTable(ctx, reader, evalCtx, p, tableName, parentID, walltime, fks, int(format.PgDump.MaxRowSize), owner, unsupportedStmtLogger)
+		if err == nil {
+			err = unsupportedStmtLogger.flush()
+		}
 	default:
-		return tableDescs, errors.Errorf("non-bundle format %q does not support reading schemas", format.Format.String())
+		return tableDescs, deferredStmts, errors.Errorf("non-bundle format %q does not support reading schemas", format.Format.String())
 	}
 
 	if err != nil {
-		return tableDescs, err
+		return tableDescs, deferredStmts, err
 	}
 
 	if tableDescs == nil && len(details.Tables) > 0 {
-		return tableDescs, errors.Errorf("table definition not found for %q", tableName)
+		return tableDescs, deferredStmts, errors.Errorf("table definition not found for %q", tableName)
 	}
 
-	return tableDescs, err
+	return tableDescs, deferredStmts, err
 }
 
 func (r *importResumer) parseBundleSchemaIfNeeded(ctx context.Context, phs interface{}) error {
@@ -1333,11 +1551,13 @@ func (r *importResumer) parseBundleSchemaIfNeeded(ctx context.Context, phs inter
 		}
 
 		var tableDescs []*tabledesc.Mutable
+		var deferredStmts []string
 		var err error
 		walltime := p.ExecCfg().Clock.Now().WallTime
 
-		if tableDescs, err = parseAndCreateBundleTableDescs(
-			ctx, p, details, seqVals, skipFKs, parentID, files, format, walltime, owner); err != nil {
+		if tableDescs, deferredStmts, err = parseAndCreateBundleTableDescs(
+			ctx, p, details, seqVals, skipFKs, parentID, files, format, walltime, owner, *r.job.ID(),
+		); err != nil {
 			return err
 		}
 
@@ -1350,6 +1570,7 @@ func (r *importResumer) parseBundleSchemaIfNeeded(ctx context.Context, phs inter
 			}
 		}
 		details.Tables = tableDetails
+		details.DeferredStatements = deferredStmts
 
 		for _, tbl := range tableDescs {
 			// For reasons relating to #37691, we disallow user defined types in
@@ -1454,8 +1675,17 @@ func (r *importResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		}
 	}
 
-	res, err := sql.DistIngest(ctx, p, r.job, tables, files, format, details.Walltime,
-		r.testingKnobs.alwaysFlushJobProgress)
+	var res roachpb.BulkOpSummary
+	var err error
+	if format.Format == roachpb.IOFileFormat_Changefeed {
+		// The row changes of a changefeed are applied in order by the
+		// coordinator, rather than ingested by the import processors.
+		res, err = applyChangefeedFiles(ctx, p, r.job, tables, files[0], format,
+			r.job.Payload().UsernameProto.Decode())
+	} else {
+		res, err = sql.DistIngest(ctx, p, r.job, tables, files, format, details.Walltime,
+			r.testingKnobs.alwaysFlushJobProgress)
+	}
 	if err != nil {
 		return err
 	}
@@ -1480,6 +1710,9 @@ func (r *importResumer) Resume(ctx context.Context, execCtx interface{}) error {
 	if err := r.publishTables(ctx, p.ExecCfg()); err != nil {
 		return err
 	}
+	if err := r.runDeferredStatements(ctx, p.ExecCfg()); err != nil {
+		return err
+	}
 	// TODO(ajwerner): Should this actually return the error? At this point we've
 	// successfully finished the import but failed to drop the protected
 	// timestamp. The reconciliation loop ought to pick it up.
@@ -1590,6 +1823,66 @@ func (r *importResumer) publishTables(ctx context.Context, execCfg *sql.Executor
 	return nil
 }
 
+// runDeferredStatements executes the statements of a bundle which could only
+// be executed once the imported tables were published, e.g. the CREATE VIEW
+// statements of a PGDUMP file. They are executed in order, as the owner of the
+// job. Since the imported tables are already public, a statement which fails
+// does not fail the import, which would drop them: it is skipped, and logged
+// along with the statements which could not be imported.
+func (r *importResumer) runDeferredStatements(
+	ctx context.Context, execCfg *sql.ExecutorConfig,
+) error {
+	details := r.job.Details().(jobspb.ImportDetails)
+	if len(details.DeferredStatements) == 0 {
+		return nil
+	}
+	log.Event(ctx, "executing deferred statements")
+
+	owner := r.job.Payload().UsernameProto.Decode()
+	failedStmtLogger := makeUnsupportedStmtLogger(
+		ctx, owner, *r.job.ID(), "failed_statements", details.Format.PgDump,
+		execCfg.DistSQLSrv.ExternalStorageFromURI)
+	for len(details.DeferredStatements) > 0 {
+		stmt, remaining := details.DeferredStatements[0], details.DeferredStatements[1:]
+		// The statement is removed from the job in the transaction executing it,
+		// so that it is not executed again if the job is resumed.
+		err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+			dbDesc, err := catalogkv.MustGetDatabaseDescByID(ctx, txn, execCfg.Codec, details.ParentID)
+			if err != nil {
+				return err
+			}
+			override := sessiondata.InternalExecutorOverride{User: owner, Database: dbDesc.GetName()}
+			if _, err := execCfg.InternalExecutor.ExecEx(
+				ctx, "import-deferred-stmt", txn, override, stmt,
+			); err != nil {
+				return err
+			}
+			updated := details
+			updated.DeferredStatements = remaining
+			return r.job.WithTxn(txn).SetDetails(ctx, updated)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			log.Warningf(ctx, "skipping deferred statement %s which failed: %v", stmt, err)
+			if err := failedStmtLogger.log(stmt, fmt.Sprintf("failed to execute: %v", err)); err != nil {
+				log.Warningf(ctx, "%v", err)
+			}
+			updated := details
+			updated.DeferredStatements = remaining
+			if err := r.job.WithTxn(nil).SetDetails(ctx, updated); err != nil {
+				return err
+			}
+		}
+		details.DeferredStatements = remaining
+	}
+	if err := failedStmtLogger.flush(); err != nil {
+		log.Warningf(ctx, "%v", err)
+	}
+	return nil
+}
+
 // OnFailOrCancel is part of the jobs.Resumer interface. Removes data that has
 // been committed from a import that has failed or been canceled. It does this
 // by adding the table descriptors in DROP state, which causes the schema change
@@ -5845,6 +5845,125 @@ func TestImportPgDumpDropTable(t *testing.T) {
 	})
 }
 
+func TestImportPgDumpSchemaObjects(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	const dump = `
+CREATE SCHEMA reports;
+CREATE AGGREGATE public.my_avg (float8) (sfunc = float8_accum, stype = float8[]);
+CREATE TYPE public.mood AS ENUM ('sad', 'ok', 'happy');
+CREATE TABLE public.person (
+    id integer NOT NULL,
+    name text,
+    happy boolean
+);
+CREATE SEQUENCE public.person_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+ALTER SEQUENCE public.person_id_seq OWNED BY public.person.id;
+ALTER TABLE ONLY public.person ALTER COLUMN id SET DEFAULT nextval('public.person_id_seq'::regclass);
+CREATE VIEW reports.happy_people AS
+    SELECT person.id, person.name FROM public.person WHERE person.happy;
+COMMENT ON TABLE public.person IS 'people';
+COMMENT ON COLUMN public.person.name IS 'full name';
+COMMENT ON EXTENSION plpgsql IS 'PL/pgSQL procedural language';
+COPY public.person (id, name, happy) FROM stdin;
+1	alice	t
+2	bob	f
+\.
+SELECT pg_catalog.setval('public.person_id_seq', 2, true);
+ALTER TABLE ONLY public.person
+    ADD CONSTRAINT person_pkey PRIMARY KEY (id);
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, "dump.sql"), []byte(dump), 0644))
+	const data = "nodelocal://0/dump.sql"
+
+	// Statements which cannot be parsed fail the import, unless they are
+	// ignored.
+	sqlDB.ExpectErr(t, "postgres parse error.*unimplemented", `IMPORT PGDUMP ($1)`, data)
+
+	var jobID int64
+	sqlDB.QueryRow(t, `IMPORT PGDUMP ($1) WITH ignore_unsupported_statements, log_ignored_statements = $2`,
+		data, "nodelocal://0/ignored").Scan(&jobID, new(string), new(float64), new(int64), new(int64), new(int64))
+
+	sqlDB.CheckQueryResults(t, `SELECT * FROM person ORDER BY id`,
+		[][]string{{"1", "alice", "true"}, {"2", "bob", "false"}})
+	sqlDB.CheckQueryResults(t, `SELECT * FROM reports.happy_people`, [][]string{{"1", "alice"}})
+	sqlDB.CheckQueryResults(t, `INSERT INTO person (name) VALUES ('carol') RETURNING id`, [][]string{{"3"}})
+	sqlDB.CheckQueryResults(t, `SELECT obj_description('person'::REGCLASS)`, [][]string{{"people"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT col_description('person'::REGCLASS, 2)`, [][]string{{"full name"}})
+
+	ignored, err := ioutil.ReadFile(filepath.Join(baseDir, "ignored", fmt.Sprintf("import%d_ignored_statements_0.log", jobID)))
+	require.NoError(t, err)
+	require.Contains(t, string(ignored), "-- ignored statement\nCOMMENT ON EXTENSION plpgsql")
+	require.Contains(t, string(ignored), "CREATE AGGREGATE public.my_avg")
+	require.Contains(t, string(ignored), "by a CHECK constraint\nCREATE TYPE public.mood")
+
+	// The sequence is owned by the column, and dropped along with its table.
+	sqlDB.Exec(t, `DROP VIEW reports.happy_people`)
+	sqlDB.Exec(t, `DROP TABLE person`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM [SHOW SEQUENCES]`, [][]string{{"0"}})
+
+	// The enums are not imported, unless unsupported statements are ignored, in
+	// which case the columns of their types are imported as STRING columns
+	// restricted to their labels.
+	const enumDump = `
+CREATE TYPE public.mood AS ENUM ('sad', 'ok', 'happy');
+CREATE TABLE public.person (id integer NOT NULL, current_mood public.mood DEFAULT 'ok'::public.mood);
+COPY public.person (id, current_mood) FROM stdin;
+1	happy
+2	sad
+\.
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, "enum.sql"), []byte(enumDump), 0644))
+	const enumData = "nodelocal://0/enum.sql"
+	sqlDB.ExpectErr(t, "unsupported statement: CREATE TYPE .* enum types cannot be imported",
+		`IMPORT PGDUMP ($1)`, enumData)
+	sqlDB.Exec(t, `IMPORT PGDUMP ($1) WITH ignore_unsupported_statements`, enumData)
+	sqlDB.CheckQueryResults(t, `SELECT id, current_mood FROM person ORDER BY id`,
+		[][]string{{"1", "happy"}, {"2", "sad"}})
+	sqlDB.CheckQueryResults(t, `INSERT INTO person (id) VALUES (3) RETURNING current_mood`,
+		[][]string{{"ok"}})
+	sqlDB.ExpectErr(t, "imported_from_enum_current_mood",
+		`INSERT INTO person VALUES (4, 'angry')`)
+
+	// The statements executed once the tables are published which fail, e.g.
+	// the CREATE SCHEMA of a schema created by the first import, are skipped
+	// and logged rather than failing the import, which would drop the tables.
+	const accountDump = `
+CREATE SCHEMA reports;
+CREATE TABLE public.account (id integer NOT NULL, balance integer);
+CREATE VIEW reports.overdrawn AS SELECT account.id FROM public.account WHERE account.balance < 0;
+COPY public.account (id, balance) FROM stdin;
+1	-10
+2	20
+\.
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, "account.sql"), []byte(accountDump), 0644))
+	sqlDB.QueryRow(t, `IMPORT PGDUMP ($1) WITH log_ignored_statements = $2`,
+		"nodelocal://0/account.sql", "nodelocal://0/ignored",
+	).Scan(&jobID, new(string), new(float64), new(int64), new(int64), new(int64))
+	sqlDB.CheckQueryResults(t, `SELECT * FROM account ORDER BY id`, [][]string{{"1", "-10"}, {"2", "20"}})
+	sqlDB.CheckQueryResults(t, `SELECT * FROM reports.overdrawn`, [][]string{{"1"}})
+	failed, err := ioutil.ReadFile(filepath.Join(baseDir, "ignored", fmt.Sprintf("import%d_failed_statements_0.log", jobID)))
+	require.NoError(t, err)
+	require.Regexp(t, `-- failed to execute: .*already exists\nCREATE SCHEMA reports;`, string(failed))
+}
+
 func TestImportCockroachDump(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
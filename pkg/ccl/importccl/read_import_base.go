@@ -13,11 +13,13 @@ import (
 	"compress/bzip2"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
 	"net/url"
+	"path"
 	"runtime"
 	"strings"
 	"sync/atomic"
@@ -138,7 +140,7 @@ func runImport(
 	return summary, nil
 }
 
-type readFileFunc func(context.Context, *fileReader, int32, int64, chan string) error
+type readFileFunc func(context.Context, *fileReader, int32, int64, chan *importRowError) error
 
 // readInputFile reads each of the passed dataFiles using the passed func. The
 // key part of dataFiles is the unique index of the data file among all files in
@@ -211,49 +213,51 @@ func readInputFiles(
 			defer decompressed.Close()
 			src.Reader = decompressed
 
-			var rejected chan string
-			if (format.Format == roachpb.IOFileFormat_CSV && format.SaveRejected) ||
-				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) {
-				rejected = make(chan string)
+			var rejected chan *importRowError
+			if rejectsRows(format) {
+				rejected = make(chan *importRowError)
 			}
 			if rejected != nil {
 				grp := ctxgroup.WithContext(ctx)
 				grp.GoCtx(func(ctx context.Context) error {
+					maxRowErrors := format.MaxRowErrors
+					if maxRowErrors == 0 {
+						maxRowErrors = defaultMaxRowErrors
+					}
 					var buf []byte
 					var countRejected int64
-					for s := range rejected {
+					for rowErr := range rejected {
 						countRejected++
-						if countRejected > 1000 { // TODO(spaskob): turn the magic constant into an option
-							return pgerror.Newf(
+						if countRejected > maxRowErrors {
+							err := pgerror.Newf(
 								pgcode.DataCorrupted,
 								"too many parsing errors (%d) encountered for file %s",
 								countRejected,
 								dataFile,
 							)
+							// The rows rejected so far are still saved to the
+							// rejected_rows_dir, to help finding out what is wrong
+							// with the file.
+							if format.RejectedRowsDir != "" {
+								if writeErr := writeRejectedRows(
+									ctx, format, dataFile, dataFileIndex, buf, makeExternalStorage, user,
+								); writeErr != nil {
+									err = errors.CombineErrors(err, writeErr)
+								}
+							}
+							return err
+						}
+						var err error
+						if buf, err = appendRejectedRow(buf, rowErr, format); err != nil {
+							return err
 						}
-						buf = append(buf, s...)
 					}
 					if countRejected == 0 {
 						// no rejected rows
 						return nil
 					}
-					rejFn, err := rejectedFilename(dataFile)
-					if err != nil {
-						return err
-					}
-					conf, err := cloudimpl.ExternalStorageConfFromURI(rejFn, user)
-					if err != nil {
-						return err
-					}
-					rejectedStorage, err := makeExternalStorage(ctx, conf)
-					if err != nil {
-						return err
-					}
-					defer rejectedStorage.Close()
-					if err := rejectedStorage.WriteFile(ctx, "", bytes.NewReader(buf)); err != nil {
-						return err
-					}
-					return nil
+					return writeRejectedRows(
+						ctx, format, dataFile, dataFileIndex, buf, makeExternalStorage, user)
 				})
 
 				grp.GoCtx(func(ctx context.Context) error {
@@ -280,6 +284,88 @@ func readInputFiles(
 	return nil
 }
 
+// defaultMaxRowErrors is the number of rows of an input file which may be
+// rejected before the import fails, unless max_row_errors is specified.
+const defaultMaxRowErrors = 1000
+
+// rejectsRows returns whether the rows of the input files which cannot be
+// imported are rejected, rather than failing the import.
+func rejectsRows(format roachpb.IOFileFormat) bool {
+	switch format.Format {
+	case roachpb.IOFileFormat_CSV, roachpb.IOFileFormat_MysqlOutfile, roachpb.IOFileFormat_NDJSON:
+		return format.SaveRejected || format.MaxRowErrors > 0 || format.RejectedRowsDir != ""
+	}
+	return false
+}
+
+// rejectedRow is the record written to the rejected_rows_dir for each rejected
+// row of an input file.
+type rejectedRow struct {
+	Row   int64  `json:"row"`
+	Error string `json:"error"`
+	Data  string `json:"data"`
+}
+
+// appendRejectedRow appends a rejected row to the contents of the rejected
+// rows file. Files written to the rejected_rows_dir contain a JSON record per
+// rejected row which includes the error. Otherwise, the rejected row is
+// written as is, so that the file can be fixed up and imported again.
+func appendRejectedRow(
+	buf []byte, rowErr *importRowError, format roachpb.IOFileFormat,
+) ([]byte, error) {
+	if format.RejectedRowsDir == "" {
+		buf = append(buf, rowErr.row...)
+		return append(buf, '\n'), nil
+	}
+	rec, err := json.Marshal(rejectedRow{Row: rowErr.rowNum, Error: rowErr.err.Error(), Data: rowErr.row})
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, rec...)
+	return append(buf, '\n'), nil
+}
+
+// writeRejectedRows writes the rows rejected from a data file, either to the
+// rejected_rows_dir, or next to the data file if save_rejected is specified.
+func writeRejectedRows(
+	ctx context.Context,
+	format roachpb.IOFileFormat,
+	dataFile string,
+	dataFileIndex int32,
+	buf []byte,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	var uri, name string
+	switch {
+	case format.RejectedRowsDir != "":
+		uri = format.RejectedRowsDir
+		var err error
+		if name, err = rejectedRowsDirFilename(dataFile, dataFileIndex); err != nil {
+			return err
+		}
+	case format.SaveRejected:
+		var err error
+		if uri, err = rejectedFilename(dataFile); err != nil {
+			return err
+		}
+	default:
+		// The rejected rows are only counted.
+		return nil
+	}
+
+	conf, err := cloudimpl.ExternalStorageConfFromURI(uri, user)
+	if err != nil {
+		return err
+	}
+	rejectedStorage, err := makeExternalStorage(ctx, conf)
+	if err != nil {
+		return err
+	}
+	defer rejectedStorage.Close()
+	return rejectedStorage.WriteFile(ctx, name, bytes.NewReader(buf))
+}
+
 func rejectedFilename(datafile string) (string, error) {
 	parsedURI, err := url.Parse(datafile)
 	if err != nil {
@@ -289,6 +375,17 @@ func rejectedFilename(datafile string) (string, error) {
 	return parsedURI.String(), nil
 }
 
+// rejectedRowsDirFilename returns the name of the file of the rejected_rows_dir
+// the rows rejected from a data file are written to. The index of the data
+// file tells apart the data files with the same name.
+func rejectedRowsDirFilename(datafile string, dataFileIndex int32) (string, error) {
+	parsedURI, err := url.Parse(datafile)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%d.rejected", path.Base(parsedURI.Path), dataFileIndex), nil
+}
+
 func decompressingReader(
 	in io.Reader, name string, hint roachpb.IOFileFormat_Compression,
 ) (io.ReadCloser, error) {
@@ -356,6 +453,8 @@ type inputConverter interface {
 func formatHasNamedColumns(format roachpb.IOFileFormat_FileFormat) bool {
 	switch format {
 	case roachpb.IOFileFormat_Avro,
+		roachpb.IOFileFormat_Parquet,
+		roachpb.IOFileFormat_NDJSON,
 		roachpb.IOFileFormat_Mysqldump,
 		roachpb.IOFileFormat_PgDump:
 		return true
@@ -424,10 +523,10 @@ type parallelImportContext struct {
 
 // importFileContext describes state specific to a file being imported.
 type importFileContext struct {
-	source   int32       // Source is where the row data in the batch came from.
-	skip     int64       // Number of records to skip
-	rejected chan string // Channel for reporting corrupt "rows"
-	rowLimit int64       // Number of records to process before we stop importing from a file.
+	source   int32                // Source is where the row data in the batch came from.
+	skip     int64                // Number of records to skip
+	rejected chan *importRowError // Channel for reporting corrupt "rows"
+	rowLimit int64                // Number of records to process before we stop importing from a file.
 }
 
 // handleCorruptRow reports an error encountered while processing a row
@@ -436,13 +535,29 @@ func handleCorruptRow(ctx context.Context, fileCtx *importFileContext, err error
 	log.Errorf(ctx, "%+v", err)
 
 	if rowErr := (*importRowError)(nil); errors.As(err, &rowErr) && fileCtx.rejected != nil {
-		fileCtx.rejected <- rowErr.row + "\n"
-		return nil
+		select {
+		case fileCtx.rejected <- rowErr:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
 	return err
 }
 
+// isRowDataError returns whether an error encountered while converting a row
+// is caused by the data of the row, e.g. a value which violates a NOT NULL
+// constraint or doesn't fit its column, so that the row can be rejected like
+// the rows which cannot be parsed.
+func isRowDataError(err error) bool {
+	switch pgerror.GetPGCode(err).String()[:2] {
+	case "22" /* data exception */, "23" /* integrity constraint violation */ :
+		return true
+	}
+	return false
+}
+
 func makeDatumConverter(
 	ctx context.Context, importCtx *parallelImportContext, fileCtx *importFileContext,
 ) (*row.DatumRowConverter, error) {
@@ -488,6 +603,23 @@ type importRowConsumer interface {
 	FillDatums(row interface{}, rowNum int64, conv *row.DatumRowConverter) error
 }
 
+// importRowStringer is implemented by the importRowConsumers of the textual
+// formats, so that the rows rejected after they were parsed are recorded as
+// they appear in the input, like the rows which cannot be parsed.
+type importRowStringer interface {
+	// RowString returns the input line a row was read from.
+	RowString(row interface{}) string
+}
+
+// rowString returns the input line a row was read from, if the consumer of the
+// rows has one, or a description of the row otherwise.
+func rowString(consumer importRowConsumer, row interface{}) string {
+	if s, ok := consumer.(importRowStringer); ok {
+		return s.RowString(row)
+	}
+	return fmt.Sprintf("%v", row)
+}
+
 // batch represents batch of data to convert.
 type batch struct {
 	data     []interface{}
@@ -679,7 +811,14 @@ func (p *parallelImporter) importWorker(
 
 			rowIndex := int64(timestamp) + rowNum
 			if err := conv.Row(ctx, conv.KvBatch.Source, rowIndex); err != nil {
-				return newImportRowError(err, fmt.Sprintf("%v", record), rowNum)
+				rowErr := newImportRowError(err, rowString(consumer, record), rowNum)
+				if !isRowDataError(err) {
+					return rowErr
+				}
+				if err = handleCorruptRow(ctx, fileCtx, rowErr); err != nil {
+					return err
+				}
+				continue
 			}
 		}
 	}
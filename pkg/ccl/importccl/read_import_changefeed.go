@@ -0,0 +1,651 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// The files written by a changefeed into a cloud storage sink are partitioned
+// into directories by date, and named after timestamps formatted as
+// YYYYMMDDHHMMSSNNNNNNNNNLLLLLLLLLL. The data files are named
+// `<timestamp>-<session_id>-<node_id>-<sink_id>-<file_id>-<topic>-<schema_id><ext>`,
+// the resolved timestamp files `<timestamp>.RESOLVED` and the manifests of
+// exactly-once changefeeds `<timestamp>.MANIFEST`. See cloudStorageSink in
+// changefeedccl for the ordering guarantees they provide.
+const (
+	changefeedTimestampLen   = len(`20060102150405`) + 9 + 10
+	changefeedResolvedSuffix = `.RESOLVED`
+	changefeedManifestSuffix = `.MANIFEST`
+	// changefeedUniquerParts is the number of dash-separated components of
+	// the data file names between their timestamp and their topic.
+	changefeedUniquerParts = 4
+)
+
+// changefeedApplyBatchSize is the number of row changes applied per
+// transaction.
+var changefeedApplyBatchSize = 1000
+
+// parseChangefeedTimestamp parses a timestamp naming a file written by a
+// changefeed. It is the inverse of cloudStorageFormatTime in changefeedccl,
+// which cannot be imported from here.
+func parseChangefeedTimestamp(s string) (hlc.Timestamp, error) {
+	const f = `20060102150405`
+	if len(s) != changefeedTimestampLen {
+		return hlc.Timestamp{}, errors.Errorf(`malformed timestamp: %q`, s)
+	}
+	t, err := time.Parse(f, s[:len(f)])
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrapf(err, `malformed timestamp: %q`, s)
+	}
+	nanos, err := strconv.ParseInt(s[len(f):len(f)+9], 10, 64)
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrapf(err, `malformed timestamp: %q`, s)
+	}
+	logical, err := strconv.ParseInt(s[len(f)+9:], 10, 32)
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrapf(err, `malformed timestamp: %q`, s)
+	}
+	return hlc.Timestamp{WallTime: t.UnixNano() + nanos, Logical: int32(logical)}, nil
+}
+
+// parseChangefeedResolved parses a timestamp formatted as a decimal, like the
+// updated and resolved timestamps emitted by changefeeds.
+func parseChangefeedResolved(s string) (hlc.Timestamp, error) {
+	d, err := tree.ParseDDecimal(s)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	return tree.DecimalToHLC(&d.Decimal)
+}
+
+// changefeedDataFile is a data file written by a changefeed.
+type changefeedDataFile struct {
+	// path is relative to the directory of the changefeed.
+	path string
+	// ts is an inclusive lower bound of the updated timestamps of the rows of
+	// the file.
+	ts    hlc.Timestamp
+	topic string
+	// lowWater is an inclusive lower bound of the updated timestamps of the
+	// rows of the file and of the files applied after it.
+	lowWater hlc.Timestamp
+}
+
+func parseChangefeedDataFile(p string) (changefeedDataFile, error) {
+	name := path.Base(p)
+	f := changefeedDataFile{path: p}
+	if len(name) <= changefeedTimestampLen || name[changefeedTimestampLen] != '-' {
+		return f, errors.Errorf("malformed changefeed file name: %q", p)
+	}
+	var err error
+	if f.ts, err = parseChangefeedTimestamp(name[:changefeedTimestampLen]); err != nil {
+		return f, errors.Wrapf(err, "parsing changefeed file name %q", p)
+	}
+	// Topics may contain dashes, but the uniquer and the schema ID do not.
+	parts := strings.SplitN(name[changefeedTimestampLen+1:], "-", changefeedUniquerParts+1)
+	if len(parts) <= changefeedUniquerParts {
+		return f, errors.Errorf("malformed changefeed file name: %q", p)
+	}
+	topicAndSchema := parts[changefeedUniquerParts]
+	i := strings.LastIndexByte(topicAndSchema, '-')
+	if i < 0 {
+		return f, errors.Errorf("malformed changefeed file name: %q", p)
+	}
+	f.topic = topicAndSchema[:i]
+	return f, nil
+}
+
+// changefeedFilesToApply returns the data files of a topic written by a
+// changefeed, in the order in which their row changes are to be applied,
+// along with the resolved timestamp up to which they are applied. If resolved
+// is empty, the latest resolved timestamp of the changefeed is used. It is an
+// error for the changefeed to have written no data files of the topic.
+//
+// The files of exactly-once changefeeds are listed by their manifests, and
+// only the files of the manifests up to the first one at or after the
+// resolved timestamp are returned. The rows of these files are all later than
+// the previous manifest. Otherwise, the data files are ordered by name, and
+// those named after a timestamp later than the resolved one, whose rows are
+// all later than it, are skipped.
+func changefeedFilesToApply(
+	ctx context.Context, es cloud.ExternalStorage, topic string, resolved string,
+) ([]changefeedDataFile, hlc.Timestamp, error) {
+	names, err := es.ListFiles(ctx, "*/*")
+	if err != nil {
+		return nil, hlc.Timestamp{}, err
+	}
+
+	type manifest struct {
+		path string
+		ts   hlc.Timestamp
+	}
+	var manifests []manifest
+	var dataFiles []changefeedDataFile
+	topics := make(map[string]struct{})
+	var latest hlc.Timestamp
+	for _, name := range names {
+		base := path.Base(name)
+		switch {
+		case strings.HasSuffix(base, changefeedResolvedSuffix):
+			ts, err := parseChangefeedTimestamp(strings.TrimSuffix(base, changefeedResolvedSuffix))
+			if err != nil {
+				return nil, hlc.Timestamp{}, errors.Wrapf(err, "parsing resolved timestamp file %s", name)
+			}
+			latest.Forward(ts)
+		case strings.HasSuffix(base, changefeedManifestSuffix):
+			ts, err := parseChangefeedTimestamp(strings.TrimSuffix(base, changefeedManifestSuffix))
+			if err != nil {
+				return nil, hlc.Timestamp{}, errors.Wrapf(err, "parsing manifest %s", name)
+			}
+			latest.Forward(ts)
+			manifests = append(manifests, manifest{path: name, ts: ts})
+		default:
+			f, err := parseChangefeedDataFile(name)
+			if err != nil {
+				return nil, hlc.Timestamp{}, err
+			}
+			topics[f.topic] = struct{}{}
+			if f.topic == topic {
+				dataFiles = append(dataFiles, f)
+			}
+		}
+	}
+	if latest.IsEmpty() {
+		return nil, hlc.Timestamp{}, errors.Errorf(
+			"no resolved timestamp found in changefeed output, the changefeed must be created WITH resolved")
+	}
+	if len(dataFiles) == 0 {
+		found := make([]string, 0, len(topics))
+		for t := range topics {
+			found = append(found, t)
+		}
+		sort.Strings(found)
+		return nil, hlc.Timestamp{}, errors.Errorf(
+			"no data files of topic %q found in changefeed output (found topics: %s)",
+			topic, strings.Join(found, ", "))
+	}
+
+	stop := latest
+	if resolved != "" {
+		if stop, err = parseChangefeedResolved(resolved); err != nil {
+			return nil, hlc.Timestamp{}, err
+		}
+		if latest.Less(stop) {
+			return nil, hlc.Timestamp{}, errors.Errorf(
+				"changefeed has not resolved %s, its latest resolved timestamp is %s",
+				stop.AsOfSystemTime(), latest.AsOfSystemTime())
+		}
+	}
+
+	if len(manifests) == 0 {
+		sort.Slice(dataFiles, func(i, j int) bool {
+			return path.Base(dataFiles[i].path) < path.Base(dataFiles[j].path)
+		})
+		n := sort.Search(len(dataFiles), func(i int) bool { return stop.Less(dataFiles[i].ts) })
+		for i := range dataFiles[:n] {
+			dataFiles[i].lowWater = dataFiles[i].ts
+		}
+		return dataFiles[:n], stop, nil
+	}
+
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].ts.Less(manifests[j].ts) })
+	var files []changefeedDataFile
+	var lowWater hlc.Timestamp
+	for _, m := range manifests {
+		var content struct {
+			Files []string `json:"files"`
+		}
+		if err := func() error {
+			raw, err := es.ReadFile(ctx, m.path)
+			if err != nil {
+				return err
+			}
+			defer raw.Close()
+			return gojson.NewDecoder(raw).Decode(&content)
+		}(); err != nil {
+			return nil, hlc.Timestamp{}, errors.Wrapf(err, "reading manifest %s", m.path)
+		}
+		for _, name := range content.Files {
+			f, err := parseChangefeedDataFile(name)
+			if err != nil {
+				return nil, hlc.Timestamp{}, err
+			}
+			if f.topic == topic {
+				f.lowWater = lowWater
+				files = append(files, f)
+			}
+		}
+		if stop.LessEq(m.ts) {
+			break
+		}
+		lowWater = m.ts.Next()
+	}
+	return files, stop, nil
+}
+
+// changefeedRowChange is a change of a row of the table imported into.
+type changefeedRowChange struct {
+	// key is the primary index key of the row.
+	key roachpb.Key
+	// values are the datums of the public columns of the row after the change,
+	// or nil if the row was deleted.
+	values tree.Datums
+	size   int
+}
+
+// changefeedApplier applies the row changes written by a changefeed to the
+// table imported into, with regular transactional writes. Deletions cannot be
+// ingested, and the changes to a row have to be applied in order.
+//
+// The rows are emitted by changefeeds in order of their updated timestamps,
+// but a change may be emitted several times, even after later changes of the
+// same row. The applier remembers the timestamp of the latest change applied
+// to each row, and skips the changes which are not later. The changes of a
+// file and of the files after it are no earlier than its low water, unless
+// they were emitted again, so only the timestamps of the changes since the low
+// water of the file being applied are remembered, and the earlier changes are
+// skipped.
+type changefeedApplier struct {
+	// The conflictResolver decodes the existing rows, and inserts or updates
+	// the rows which are upserted.
+	*conflictResolver
+	rd      row.Deleter
+	evalCtx *tree.EvalContext
+
+	// colOrds maps the names of the public columns to their ordinals.
+	colOrds   map[string]int
+	colIdxMap catalog.TableColMap
+	// keyOrds are the ordinals of the primary key columns, in the order of the
+	// keys of the changefeed rows.
+	keyOrds []int
+	// resolved is the timestamp up to which the changes are applied.
+	resolved hlc.Timestamp
+
+	// applied maps the primary keys of the rows changed at or after lowWater
+	// to the updated timestamp of their latest change.
+	applied  map[string]hlc.Timestamp
+	lowWater hlc.Timestamp
+	// batch holds the latest change of each row changed since the last flush.
+	batch    []changefeedRowChange
+	batchIdx map[string]int
+
+	upserted, deleted, dataSize int64
+}
+
+func newChangefeedApplier(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	tableDesc catalog.TableDescriptor,
+	resolved hlc.Timestamp,
+	evalCtx *tree.EvalContext,
+) (*changefeedApplier, error) {
+	resolver, err := newConflictResolver(ctx, execCfg.DB, execCfg.Codec, tableDesc, roachpb.IOFileFormat_Upsert)
+	if err != nil {
+		return nil, err
+	}
+	a := &changefeedApplier{
+		conflictResolver: resolver,
+		evalCtx:          evalCtx,
+		colOrds:          make(map[string]int),
+		resolved:         resolved,
+		applied:          make(map[string]hlc.Timestamp),
+		batchIdx:         make(map[string]int),
+	}
+	cols := make([]descpb.ColumnDescriptor, len(tableDesc.PublicColumns()))
+	for i, col := range tableDesc.PublicColumns() {
+		cols[i] = *col.ColumnDesc()
+		a.colOrds[col.GetName()] = i
+		a.colIdxMap.Set(col.GetID(), i)
+	}
+	for i := 0; i < tableDesc.GetPrimaryIndex().NumColumns(); i++ {
+		ord, ok := a.colIdxMap.Get(tableDesc.GetPrimaryIndex().GetColumnID(i))
+		if !ok {
+			return nil, errors.AssertionFailedf("primary key column %d is not public",
+				tableDesc.GetPrimaryIndex().GetColumnID(i))
+		}
+		a.keyOrds = append(a.keyOrds, ord)
+	}
+	a.rd = row.MakeDeleter(execCfg.Codec, tableDesc, cols)
+	return a, nil
+}
+
+// applyFile applies the row changes of a data file.
+func (a *changefeedApplier) applyFile(
+	ctx context.Context,
+	es cloud.ExternalStorage,
+	f changefeedDataFile,
+	compression roachpb.IOFileFormat_Compression,
+) error {
+	if strings.HasSuffix(f.path, ".parquet") {
+		return errors.Errorf("cannot import changefeed file %s, only the JSON format is supported", f.path)
+	}
+	a.forward(f.lowWater)
+	raw, err := es.ReadFile(ctx, f.path)
+	if err != nil {
+		return err
+	}
+	defer raw.Close()
+	reader, err := decompressingReader(raw, f.path, compression)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	r := bufio.NewReader(reader)
+	for rowNum := int64(1); ; rowNum++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err := a.add(ctx, line); err != nil {
+				return errors.Wrapf(err, "applying row %d of %s", rowNum, f.path)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// forward forgets the timestamps of the changes earlier than lowWater.
+func (a *changefeedApplier) forward(lowWater hlc.Timestamp) {
+	if !a.lowWater.Forward(lowWater) {
+		return
+	}
+	for key, ts := range a.applied {
+		if ts.Less(a.lowWater) {
+			delete(a.applied, key)
+		}
+	}
+}
+
+// add parses a row change, and adds it to the batch if it is not later than
+// the resolved timestamp, nor earlier than the low water, nor was already
+// applied.
+func (a *changefeedApplier) add(ctx context.Context, line []byte) error {
+	doc, err := json.ParseJSON(string(line))
+	if err != nil {
+		return err
+	}
+	if doc.Type() != json.ObjectJSONType {
+		return errors.Errorf("expected a JSON object")
+	}
+
+	updated, err := doc.FetchValKey(`updated`)
+	if err != nil {
+		return err
+	}
+	if updated == nil || updated.Type() != json.StringJSONType {
+		return errors.Errorf(
+			"missing updated timestamp, the changefeed must be created WITH updated and envelope = 'wrapped'")
+	}
+	s, err := updated.AsText()
+	if err != nil {
+		return err
+	}
+	ts, err := parseChangefeedResolved(*s)
+	if err != nil {
+		return errors.Wrap(err, "parsing updated timestamp")
+	}
+	if a.resolved.Less(ts) || ts.Less(a.lowWater) {
+		return nil
+	}
+
+	key, err := doc.FetchValKey(`key`)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.Type() != json.ArrayJSONType || key.Len() != len(a.keyOrds) {
+		return errors.Errorf("expected a key of %d columns", len(a.keyOrds))
+	}
+	values := make(tree.Datums, len(a.colOrds))
+	for i, ord := range a.keyOrds {
+		elem, err := key.FetchValIdx(i)
+		if err != nil {
+			return err
+		}
+		col := a.tableDesc.PublicColumns()[ord]
+		if values[ord], err = ndjsonValueToDatum(elem, col.GetType(), a.evalCtx); err != nil {
+			return errors.Wrapf(err, "parse key column %q as %s", col.GetName(), col.GetType().SQLString())
+		}
+	}
+	change := changefeedRowChange{size: len(line)}
+	change.key, _, err = rowenc.EncodeIndexKey(
+		a.tableDesc, a.tableDesc.GetPrimaryIndex().IndexDesc(), a.colIdxMap, values,
+		rowenc.MakeIndexKeyPrefix(a.codec, a.tableDesc, a.tableDesc.GetPrimaryIndexID()))
+	if err != nil {
+		return err
+	}
+	if prev, ok := a.applied[string(change.key)]; ok && ts.LessEq(prev) {
+		return nil
+	}
+
+	// The row is deleted if the after field is null. The before field, if any,
+	// is not needed.
+	after, err := doc.FetchValKey(`after`)
+	if err != nil {
+		return err
+	}
+	if after == nil {
+		return errors.Errorf("missing after field, the changefeed must be created WITH envelope = 'wrapped'")
+	}
+	if after.Type() == json.ObjectJSONType {
+		it, err := after.ObjectIter()
+		if err != nil {
+			return err
+		}
+		for it.Next() {
+			ord, ok := a.colOrds[it.Key()]
+			if !ok {
+				return errors.Errorf("could not find column for key %q", it.Key())
+			}
+			col := a.tableDesc.PublicColumns()[ord]
+			if values[ord], err = ndjsonValueToDatum(it.Value(), col.GetType(), a.evalCtx); err != nil {
+				return errors.Wrapf(err, "parse %q as %s", col.GetName(), col.GetType().SQLString())
+			}
+		}
+		for i := range values {
+			if values[i] == nil {
+				values[i] = tree.DNull
+			}
+		}
+		change.values = values
+	} else if after.Type() != json.NullJSONType {
+		return errors.Errorf("expected the after field to be a JSON object")
+	}
+
+	a.applied[string(change.key)] = ts
+	if i, ok := a.batchIdx[string(change.key)]; ok {
+		a.batch[i] = change
+	} else {
+		a.batchIdx[string(change.key)] = len(a.batch)
+		a.batch = append(a.batch, change)
+	}
+	if len(a.batch) >= changefeedApplyBatchSize {
+		return a.flush(ctx)
+	}
+	return nil
+}
+
+// flush applies the changes of the batch in a transaction.
+func (a *changefeedApplier) flush(ctx context.Context) error {
+	if len(a.batch) == 0 {
+		return nil
+	}
+	var upserted, deleted, dataSize int64
+	if err := a.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		upserted, deleted, dataSize = 0, 0, 0
+
+		existing := txn.NewBatch()
+		for _, change := range a.batch {
+			existing.Scan(change.key, change.key.PrefixEnd())
+		}
+		if err := txn.Run(ctx, existing); err != nil {
+			return err
+		}
+
+		// Partial indexes are not maintained by IMPORT, which rejects the tables
+		// that have any.
+		var pm row.PartialIndexUpdateHelper
+		b := txn.NewBatch()
+		for i, res := range existing.Results {
+			change := a.batch[i]
+			dataSize += int64(change.size)
+			var oldValues tree.Datums
+			if len(res.Rows) > 0 {
+				existingKVs := make([]roachpb.KeyValue, len(res.Rows))
+				for j, kv := range res.Rows {
+					existingKVs[j] = roachpb.KeyValue{Key: kv.Key, Value: *kv.Value}
+				}
+				var err error
+				if oldValues, err = a.decodeRow(ctx, existingKVs); err != nil {
+					return err
+				}
+			}
+
+			switch {
+			case change.values == nil && oldValues == nil:
+				// The row was inserted and deleted before the resolved timestamp,
+				// or does not exist anymore.
+			case change.values == nil:
+				if err := a.rd.DeleteRow(ctx, b, oldValues, pm, false /* traceKV */); err != nil {
+					return errors.Wrap(err, "delete row")
+				}
+				deleted++
+			case oldValues == nil:
+				if err := a.ri.InsertRow(
+					ctx, b, change.values, pm, false /* overwrite */, false, /* traceKV */
+				); err != nil {
+					return errors.Wrap(err, "insert row")
+				}
+				upserted++
+			default:
+				if len(a.updateColOrds) > 0 {
+					updateValues := make(tree.Datums, len(a.updateColOrds))
+					for j, ord := range a.updateColOrds {
+						updateValues[j] = change.values[ord]
+					}
+					if _, err := a.ru.UpdateRow(
+						ctx, b, oldValues, updateValues, pm, false, /* traceKV */
+					); err != nil {
+						return errors.Wrap(err, "update row")
+					}
+				}
+				upserted++
+			}
+		}
+		return txn.CommitInBatch(ctx, b)
+	}); err != nil {
+		return err
+	}
+	a.upserted += upserted
+	a.deleted += deleted
+	a.dataSize += dataSize
+	a.batch = a.batch[:0]
+	a.batchIdx = make(map[string]int)
+	return nil
+}
+
+// summary returns the number of rows inserted or updated.
+func (a *changefeedApplier) summary() roachpb.BulkOpSummary {
+	pkID := roachpb.BulkOpSummaryID(uint64(a.tableDesc.GetID()), uint64(a.tableDesc.GetPrimaryIndexID()))
+	return roachpb.BulkOpSummary{
+		DataSize:    a.dataSize,
+		EntryCounts: map[uint64]int64{pkID: a.upserted},
+	}
+}
+
+// applyChangefeedFiles applies the row changes written by a changefeed into
+// the cloud storage sink at uri to the table imported into, up to the
+// resolved timestamp requested by the import, or the latest one.
+//
+// The files are read and applied in order by the coordinator of the import. If
+// the job is resumed, they are applied again from the beginning, which brings
+// the rows back to the same state once every file has been applied.
+func applyChangefeedFiles(
+	ctx context.Context,
+	p sql.JobExecContext,
+	job *jobs.Job,
+	tables map[string]*execinfrapb.ReadImportDataSpec_ImportTable,
+	uri string,
+	format roachpb.IOFileFormat,
+	user security.SQLUsername,
+) (roachpb.BulkOpSummary, error) {
+	if len(tables) != 1 {
+		return roachpb.BulkOpSummary{}, errors.AssertionFailedf(
+			"changefeed files can only be imported into a single table")
+	}
+	var tableDesc catalog.TableDescriptor
+	for _, table := range tables {
+		tableDesc = tabledesc.NewImmutable(*table.Desc)
+	}
+
+	es, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, uri, user)
+	if err != nil {
+		return roachpb.BulkOpSummary{}, err
+	}
+	defer es.Close()
+
+	topic := format.Changefeed.Topic
+	if topic == "" {
+		topic = tableDesc.GetName()
+	}
+	files, resolved, err := changefeedFilesToApply(ctx, es, topic, format.Changefeed.Resolved)
+	if err != nil {
+		return roachpb.BulkOpSummary{}, err
+	}
+	log.Infof(ctx, "applying %d changefeed files up to %s", len(files), resolved.AsOfSystemTime())
+
+	a, err := newChangefeedApplier(ctx, p.ExecCfg(), tableDesc, resolved, &p.ExtendedEvalContext().EvalContext)
+	if err != nil {
+		return roachpb.BulkOpSummary{}, err
+	}
+	for i, f := range files {
+		if err := a.applyFile(ctx, es, f, format.Compression); err != nil {
+			return roachpb.BulkOpSummary{}, err
+		}
+		if err := job.FractionProgressed(ctx, jobs.FractionUpdater(float32(i+1)/float32(len(files)))); err != nil {
+			return roachpb.BulkOpSummary{}, err
+		}
+	}
+	if err := a.flush(ctx); err != nil {
+		return roachpb.BulkOpSummary{}, err
+	}
+	log.Infof(ctx, "applied changefeed files: %d rows upserted, %d rows deleted", a.upserted, a.deleted)
+	return a.summary(), nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	// The files are named and partitioned like those of a cloud storage sink.
+	start := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano()
+	at := func(sec int) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: start + int64(sec)*int64(time.Second)}
+	}
+	fileTimestamp := func(sec int) string {
+		t := at(sec).GoTime()
+		return fmt.Sprintf(`%s%09d%010d`, t.Format(`20060102150405`), t.Nanosecond(), 0)
+	}
+	writeFile := func(name string, lines ...string) {
+		p := filepath.Join(dir, "feed", "2021-01-02", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+	}
+	writeData := func(sec, fileID int, topic string, lines ...string) {
+		writeFile(fmt.Sprintf(`%s-5e55i0n-1-1-%08x-%s-1.ndjson`, fileTimestamp(sec), fileID, topic), lines...)
+	}
+	change := func(sec, key int, after string) string {
+		return fmt.Sprintf(`{"after": %s, "key": [%d], "updated": "%s"}`, after, key, at(sec).AsOfSystemTime())
+	}
+
+	writeData(1, 0, "t",
+		change(1, 1, `{"id": 1, "v": "a"}`),
+		change(1, 2, `{"id": 2, "v": "b"}`),
+		change(2, 3, `{"id": 3, "v": "c"}`),
+		change(3, 1, `{"id": 1, "v": "a2"}`),
+	)
+	writeData(2, 1, "other-t", change(2, 1, `{"x": 1}`))
+	writeFile(fileTimestamp(3) + ".RESOLVED")
+	writeData(4, 2, "t",
+		`{"after": null, "before": {"id": 2, "v": "b"}, "key": [2], "updated": "`+at(4).AsOfSystemTime()+`"}`,
+		// A change which was already applied may be emitted again.
+		change(1, 1, `{"id": 1, "v": "a"}`),
+		change(5, 4, `{"id": 4, "v": "d"}`),
+		change(6, 3, `{"id": 3, "v": "c2"}`),
+	)
+	writeFile(fileTimestamp(5) + ".RESOLVED")
+	writeData(6, 3, "t", change(7, 4, `{"id": 4, "v": "d2"}`))
+
+	const feed = "nodelocal://0/feed"
+	const createTable = `CREATE TABLE %s (id INT PRIMARY KEY, v STRING, INDEX (v))`
+
+	t.Run("latest-resolved", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "t"))
+		sqlDB.Exec(t, `INSERT INTO t VALUES (2, 'x'), (9, 'z')`)
+		sqlDB.Exec(t, `IMPORT INTO t CHANGEFEED DATA ($1)`, feed)
+		expected := [][]string{{"1", "a2"}, {"3", "c"}, {"4", "d"}, {"9", "z"}}
+		sqlDB.CheckQueryResults(t, `SELECT id, v FROM t ORDER BY id`, expected)
+		sqlDB.CheckQueryResults(t, `SELECT id, v FROM t@t_v_idx ORDER BY id`, expected)
+	})
+
+	t.Run("chosen-resolved", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE DATABASE d2`)
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "d2.t"))
+		sqlDB.Exec(t, `IMPORT INTO d2.t CHANGEFEED DATA ($1) WITH resolved = $2`,
+			feed, at(3).AsOfSystemTime())
+		expected := [][]string{{"1", "a2"}, {"2", "b"}, {"3", "c"}}
+		sqlDB.CheckQueryResults(t, `SELECT id, v FROM d2.t ORDER BY id`, expected)
+		sqlDB.CheckQueryResults(t, `SELECT id, v FROM d2.t@t_v_idx ORDER BY id`, expected)
+	})
+
+	t.Run("topic", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE other (x INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `IMPORT INTO other CHANGEFEED DATA ($1) WITH topic = 'other-t'`, feed)
+		sqlDB.CheckQueryResults(t, `SELECT x FROM other`, [][]string{{"1"}})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE DATABASE d3`)
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "d3.t"))
+		sqlDB.ExpectErr(t, `changefeed has not resolved`,
+			`IMPORT INTO d3.t CHANGEFEED DATA ($1) WITH resolved = $2`, feed, at(6).AsOfSystemTime())
+		sqlDB.ExpectErr(t, `invalid resolved value`,
+			`IMPORT INTO d3.t CHANGEFEED DATA ($1) WITH resolved = 'now'`, feed)
+		sqlDB.ExpectErr(t, `invalid option "on_conflict" specified for CHANGEFEED import format`,
+			`IMPORT INTO d3.t CHANGEFEED DATA ($1) WITH on_conflict = 'upsert'`, feed)
+		sqlDB.ExpectErr(t, `CHANGEFEED format requires a single changefeed output directory`,
+			`IMPORT INTO d3.t CHANGEFEED DATA ($1, $1)`, feed)
+		sqlDB.ExpectErr(t, `no resolved timestamp found in changefeed output`,
+			`IMPORT INTO d3.t CHANGEFEED DATA ('nodelocal://0/feed/2021-01-02')`)
+		sqlDB.ExpectErr(t, `no data files of topic "missing" found in changefeed output \(found topics: other-t, t\)`,
+			`IMPORT INTO d3.t CHANGEFEED DATA ($1) WITH topic = 'missing'`, feed)
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "d3.u"))
+		sqlDB.ExpectErr(t, `no data files of topic "u" found in changefeed output`,
+			`IMPORT INTO d3.u CHANGEFEED DATA ($1)`, feed)
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM d3.t`, [][]string{{"0"}})
+	})
+}
@@ -79,7 +79,11 @@ func (c *csvInputReader) readFiles(
 }
 
 func (c *csvInputReader) readFile(
-	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	resumePos int64,
+	rejected chan *importRowError,
 ) error {
 	producer, consumer := newCSVPipeline(c, input)
 
@@ -172,6 +176,12 @@ type csvRowConsumer struct {
 }
 
 var _ importRowConsumer = &csvRowConsumer{}
+var _ importRowStringer = &csvRowConsumer{}
+
+// RowString implements importRowStringer interface.
+func (c *csvRowConsumer) RowString(row interface{}) string {
+	return strRecord(row.([]string), c.opts.Comma)
+}
 
 // FillDatums() implements importRowConsumer interface
 func (c *csvRowConsumer) FillDatums(
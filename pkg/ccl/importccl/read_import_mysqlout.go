@@ -167,6 +167,12 @@ type delimitedConsumer struct {
 }
 
 var _ importRowConsumer = &delimitedConsumer{}
+var _ importRowStringer = &delimitedConsumer{}
+
+// RowString implements importRowStringer
+func (d *delimitedConsumer) RowString(input interface{}) string {
+	return string(input.([]rune))
+}
 
 // FillDatums implements importRowConsumer
 func (d *delimitedConsumer) FillDatums(
@@ -341,7 +347,11 @@ func (d *delimitedConsumer) FillDatums(
 }
 
 func (d *mysqloutfileReader) readFile(
-	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	resumePos int64,
+	rejected chan *importRowError,
 ) error {
 	producer := &delimitedProducer{
 		importCtx: d.importCtx,
@@ -0,0 +1,306 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/errors"
+)
+
+// ndjsonInputReader imports newline-delimited JSON files: each line of a file
+// is a JSON object whose top level keys are mapped to the target columns by
+// name, and which may additionally be stored as a whole in a JSONB column.
+type ndjsonInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.NDJSONOptions
+
+	// keyToIdx maps the normalized top level keys of the documents to the
+	// index of the target column they are imported into.
+	keyToIdx map[string]int
+	// documentIdx is the index of the target column the documents are stored
+	// in, or -1.
+	documentIdx int
+}
+
+var _ inputConverter = &ndjsonInputReader{}
+
+func newNDJSONInputReader(
+	kvCh chan row.KVBatch,
+	tableDesc catalog.TableDescriptor,
+	targetCols tree.NameList,
+	opts roachpb.NDJSONOptions,
+	walltime int64,
+	parallelism int,
+	evalCtx *tree.EvalContext,
+) (*ndjsonInputReader, error) {
+	// The datums of a DatumRowConverter are ordered like the target columns,
+	// or the visible columns if there are no target columns.
+	var cols []catalog.Column
+	if len(targetCols) > 0 {
+		for _, name := range targetCols {
+			col, err := tableDesc.FindColumnWithName(name)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, col)
+		}
+	} else {
+		cols = tableDesc.VisibleColumns()
+	}
+
+	r := &ndjsonInputReader{
+		importCtx: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts:        opts,
+		keyToIdx:    make(map[string]int, len(cols)),
+		documentIdx: -1,
+	}
+	for i, col := range cols {
+		if opts.DocumentColumn != "" && col.GetName() == opts.DocumentColumn {
+			if col.GetType().Family() != types.JsonFamily {
+				return nil, errors.Errorf("document column %q must be of type JSONB, not %s",
+					col.GetName(), col.GetType().SQLString())
+			}
+			r.documentIdx = i
+			continue
+		}
+		r.keyToIdx[col.GetName()] = i
+	}
+	if opts.DocumentColumn != "" && r.documentIdx < 0 {
+		return nil, errors.Errorf("document column %q is not a target column of table %q",
+			opts.DocumentColumn, tableDesc.GetName())
+	}
+	return r, nil
+}
+
+func (n *ndjsonInputReader) start(group ctxgroup.Group) {}
+
+func (n *ndjsonInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, n.readFile, makeExternalStorage, user)
+}
+
+func (n *ndjsonInputReader) readFile(
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	resumePos int64,
+	rejected chan *importRowError,
+) error {
+	s := bufio.NewScanner(input)
+	s.Split(bufio.ScanLines)
+	maxRowSize := int(n.opts.MaxRowSize)
+	if maxRowSize <= 0 {
+		maxRowSize = defaultScanBuffer
+	}
+	s.Buffer(nil, maxRowSize)
+
+	producer := &ndjsonRowProducer{
+		scanner:  s,
+		progress: func() float32 { return input.ReadFraction() },
+	}
+	consumer := &ndjsonRowConsumer{
+		keyToIdx:    n.keyToIdx,
+		documentIdx: n.documentIdx,
+		strict:      n.opts.StrictMode,
+	}
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rejected: rejected,
+		rowLimit: n.opts.RowLimit,
+	}
+	return runParallelImport(ctx, n.importCtx, fileCtx, producer, consumer)
+}
+
+// ndjsonRowProducer produces the lines of a newline-delimited JSON file,
+// skipping empty lines. The lines are parsed by the consumer.
+type ndjsonRowProducer struct {
+	scanner  *bufio.Scanner
+	progress func() float32
+}
+
+var _ importRowProducer = &ndjsonRowProducer{}
+
+// Scan implements importRowProducer.
+func (p *ndjsonRowProducer) Scan() bool {
+	for p.scanner.Scan() {
+		if len(bytes.TrimSpace(p.scanner.Bytes())) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Err implements importRowProducer.
+func (p *ndjsonRowProducer) Err() error {
+	return p.scanner.Err()
+}
+
+// Skip implements importRowProducer.
+func (p *ndjsonRowProducer) Skip() error {
+	return nil
+}
+
+// Row implements importRowProducer.
+func (p *ndjsonRowProducer) Row() (interface{}, error) {
+	return p.scanner.Text(), nil
+}
+
+// Progress implements importRowProducer.
+func (p *ndjsonRowProducer) Progress() float32 {
+	return p.progress()
+}
+
+// ndjsonRowConsumer implements importRowConsumer.
+type ndjsonRowConsumer struct {
+	keyToIdx    map[string]int
+	documentIdx int
+	strict      bool
+}
+
+var _ importRowConsumer = &ndjsonRowConsumer{}
+var _ importRowStringer = &ndjsonRowConsumer{}
+
+// RowString implements importRowStringer.
+func (c *ndjsonRowConsumer) RowString(row interface{}) string {
+	return row.(string)
+}
+
+// FillDatums implements importRowConsumer.
+func (c *ndjsonRowConsumer) FillDatums(
+	row interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	line := row.(string)
+	doc, err := json.ParseJSON(line)
+	if err != nil {
+		return newImportRowError(err, line, rowNum)
+	}
+	if doc.Type() != json.ObjectJSONType {
+		return newImportRowError(errors.Errorf("expected a JSON object"), line, rowNum)
+	}
+
+	it, err := doc.ObjectIter()
+	if err != nil {
+		return newImportRowError(err, line, rowNum)
+	}
+	for it.Next() {
+		idx, ok := c.keyToIdx[lexbase.NormalizeName(it.Key())]
+		if !ok {
+			// Unknown keys are kept in the document column, if any.
+			if c.strict && c.documentIdx < 0 {
+				return newImportRowError(
+					errors.Errorf("could not find column for key %q", it.Key()), line, rowNum)
+			}
+			continue
+		}
+		col := conv.VisibleCols[idx]
+		conv.Datums[idx], err = ndjsonValueToDatum(it.Value(), conv.VisibleColTypes[idx], conv.EvalCtx)
+		if err != nil {
+			return newImportRowError(
+				errors.Wrapf(err, "parse %q as %s", col.Name, col.Type.SQLString()), line, rowNum)
+		}
+	}
+	if c.documentIdx >= 0 {
+		conv.Datums[c.documentIdx] = tree.NewDJSON(doc)
+	}
+
+	// Columns whose keys are missing from the document are NULL.
+	for i := range conv.Datums {
+		if conv.TargetColOrds.Contains(i) && conv.Datums[i] == nil {
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// ndjsonValueToDatum converts a JSON value to a datum of the target type.
+// Strings are parsed like the fields of a CSV file, numbers and booleans are
+// parsed from their JSON text and arrays are converted element by element.
+// Objects, and arrays which are not imported into an array column, can only
+// be imported into a JSONB or a string column.
+func ndjsonValueToDatum(j json.JSON, t *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if j.Type() == json.NullJSONType {
+		return tree.DNull, nil
+	}
+	switch t.Family() {
+	case types.JsonFamily:
+		return tree.NewDJSON(j), nil
+	case types.ArrayFamily:
+		if j.Type() != json.ArrayJSONType {
+			break
+		}
+		arr := tree.NewDArray(t.ArrayContents())
+		for i := 0; i < j.Len(); i++ {
+			elem, err := j.FetchValIdx(i)
+			if err != nil {
+				return nil, err
+			}
+			d, err := ndjsonValueToDatum(elem, t.ArrayContents(), evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			if err := arr.Append(d); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+
+	switch j.Type() {
+	case json.StringJSONType:
+		s, err := j.AsText()
+		if err != nil {
+			return nil, err
+		}
+		return rowenc.ParseDatumStringAs(t, *s, evalCtx)
+	case json.NumberJSONType, json.TrueJSONType, json.FalseJSONType:
+		return rowenc.ParseDatumStringAs(t, j.String(), evalCtx)
+	}
+	if t.Family() == types.StringFamily {
+		return rowenc.ParseDatumStringAs(t, j.String(), evalCtx)
+	}
+	return nil, errors.Errorf("cannot convert a JSON %s to %s", ndjsonTypeName(j), t.SQLString())
+}
+
+func ndjsonTypeName(j json.JSON) string {
+	switch j.Type() {
+	case json.ArrayJSONType:
+		return "array"
+	case json.ObjectJSONType:
+		return "object"
+	}
+	return "value"
+}
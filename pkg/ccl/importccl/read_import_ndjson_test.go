@@ -0,0 +1,110 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportNDJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	writeFile := func(name string, lines ...string) string {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")), 0644))
+		return "nodelocal://0/" + name
+	}
+	data := writeFile("data.ndjson",
+		`{"id": 1, "name": "a", "score": 1.5, "tags": ["x", "y"], "meta": {"k": [1, 2]}, "active": true}`,
+		``,
+		`{"id": 2, "Name": "b", "created": "2021-01-02T03:04:05Z", "extra": 1}`,
+		`{"id": 3, "name": null, "score": 12345678901234567890.123}`,
+	)
+
+	const createTable = `CREATE TABLE %s (
+		id INT PRIMARY KEY, name STRING, score DECIMAL, tags STRING[], meta JSONB, active BOOL, created TIMESTAMPTZ
+	)`
+	sqlDB.Exec(t, `SET TIME ZONE 'UTC'`)
+
+	t.Run("import-into", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "t"))
+		sqlDB.Exec(t, `IMPORT INTO t NDJSON DATA ($1)`, data)
+		sqlDB.CheckQueryResults(t, `SELECT id, name, score, tags, meta, active, created::STRING FROM t ORDER BY id`,
+			[][]string{
+				{"1", "a", "1.5", "{x,y}", `{"k": [1, 2]}`, "true", "NULL"},
+				{"2", "b", "NULL", "NULL", "NULL", "NULL", "2021-01-02 03:04:05+00:00"},
+				{"3", "NULL", "12345678901234567890.123", "NULL", "NULL", "NULL", "NULL"},
+			})
+	})
+
+	t.Run("document-column", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE docs (id INT PRIMARY KEY, doc JSONB, n INT DEFAULT 7)`)
+		sqlDB.Exec(t, `IMPORT INTO docs (id, doc) NDJSON DATA ($1) WITH document_column = 'doc', strict_validation`,
+			data)
+		sqlDB.CheckQueryResults(t, `SELECT id, doc->>'name', doc->'extra', n FROM docs ORDER BY id`,
+			[][]string{{"1", "a", "NULL", "7"}, {"2", "NULL", "1", "7"}, {"3", "NULL", "NULL", "7"}})
+
+		sqlDB.ExpectErr(t, `document column "id" must be of type JSONB`,
+			`IMPORT INTO docs (id, doc) NDJSON DATA ($1) WITH document_column = 'id'`, data)
+		sqlDB.ExpectErr(t, `document column "n" is not a target column`,
+			`IMPORT INTO docs (id, doc) NDJSON DATA ($1) WITH document_column = 'n'`, data)
+	})
+
+	t.Run("strict-validation", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "strict"))
+		sqlDB.ExpectErr(t, `error parsing row 2: could not find column for key \"extra\"`,
+			`IMPORT INTO strict NDJSON DATA ($1) WITH strict_validation`, data)
+	})
+
+	t.Run("row-errors", func(t *testing.T) {
+		bad := writeFile("bad.ndjson",
+			`{"id": 1, "name": "a"}`,
+			`{"id": "two", "name": "b"}`,
+			`["not", "an", "object"]`,
+			`{"id": 4, "tags": {"k": 1}}`,
+			`{"id": 5,`,
+			`{"id": 6, "active": "yes"}`,
+		)
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "bad"))
+		sqlDB.ExpectErr(t, `error parsing row 2: parse \"id\" as INT8`, `IMPORT INTO bad NDJSON DATA ($1)`, bad)
+
+		// The rows which cannot be imported are saved, and the others are
+		// imported.
+		sqlDB.Exec(t, `IMPORT INTO bad NDJSON DATA ($1) WITH experimental_save_rejected`, bad)
+		sqlDB.CheckQueryResults(t, `SELECT id, name, active FROM bad ORDER BY id`,
+			[][]string{{"1", "a", "NULL"}, {"6", "NULL", "true"}})
+		rejected, err := ioutil.ReadFile(filepath.Join(dir, "bad.ndjson.rejected"))
+		require.NoError(t, err)
+		require.Equal(t, strings.Join([]string{
+			`{"id": "two", "name": "b"}`,
+			`["not", "an", "object"]`,
+			`{"id": 4, "tags": {"k": 1}}`,
+			`{"id": 5,`,
+		}, "\n")+"\n", string(rejected))
+	})
+}
@@ -0,0 +1,904 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// parquetMagic is the magic number parquet files start and end with.
+var parquetMagic = []byte("PAR1")
+
+// parquetInputReader imports parquet files. Parquet files are compressed
+// internally and are split into row groups which can be decoded independently,
+// so unlike the other formats the files are not streamed: each row group is
+// read on its own with ReadFileAt, and up to the configured parallelism of row
+// groups of a file are read concurrently.
+type parquetInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.ParquetOptions
+}
+
+var _ inputConverter = &parquetInputReader{}
+
+func newParquetInputReader(
+	kvCh chan row.KVBatch,
+	tableDesc catalog.TableDescriptor,
+	targetCols tree.NameList,
+	opts roachpb.ParquetOptions,
+	walltime int64,
+	parallelism int,
+	evalCtx *tree.EvalContext,
+) (*parquetInputReader, error) {
+	return &parquetInputReader{
+		importCtx: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts: opts,
+	}, nil
+}
+
+func (p *parquetInputReader) start(group ctxgroup.Group) {}
+
+func (p *parquetInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	for dataFileIndex, dataFile := range dataFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := func() error {
+			conf, err := cloudimpl.ExternalStorageConfFromURI(dataFile, user)
+			if err != nil {
+				return err
+			}
+			es, err := makeExternalStorage(ctx, conf)
+			if err != nil {
+				return err
+			}
+			defer es.Close()
+			return p.readFile(ctx, es, dataFileIndex, resumePos[dataFileIndex])
+		}(); err != nil {
+			return errors.Wrapf(err, "%s", dataFile)
+		}
+	}
+	return nil
+}
+
+func (p *parquetInputReader) readFile(
+	ctx context.Context, es cloud.ExternalStorage, inputIdx int32, resumePos int64,
+) error {
+	file, err := openParquetFile(ctx, es)
+	if err != nil {
+		return err
+	}
+	consumer, err := newParquetConsumer(p.importCtx, file, p.opts.StrictMode)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parallelism := p.importCtx.numWorkers
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	producer := &parquetRowStream{
+		ctx:    ctx,
+		groups: make([]parquetRowGroup, len(file.meta.RowGroups)),
+		total:  file.meta.NumRows,
+		group:  -1,
+		sem:    make(chan struct{}, parallelism),
+	}
+	// Row groups which only contain rows that were already imported before the
+	// job was resumed are not read at all.
+	var firstRow int64
+	for i, rg := range file.meta.RowGroups {
+		producer.groups[i].numRows = rg.NumRows
+		if rg.NumRows > 0 && firstRow+rg.NumRows > resumePos {
+			producer.groups[i].results = make(chan parquetRowGroupResult, 1)
+		}
+		firstRow += rg.NumRows
+	}
+
+	// The row groups are loaded in order, and a row group is only loaded once
+	// fewer than parallelism row groups are loaded but not yet consumed. Errors
+	// loading a row group are returned to the producer along with its rows.
+	loaders := ctxgroup.WithContext(ctx)
+	loaders.GoCtx(func(ctx context.Context) error {
+		for i := range producer.groups {
+			results := producer.groups[i].results
+			if results == nil {
+				continue
+			}
+			select {
+			case producer.sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+			i := i
+			loaders.GoCtx(func(ctx context.Context) error {
+				rows, err := file.readRowGroup(ctx, i, consumer.projection)
+				results <- parquetRowGroupResult{rows: rows, err: err}
+				return nil
+			})
+		}
+		return nil
+	})
+
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rowLimit: p.opts.RowLimit,
+	}
+	err = runParallelImport(ctx, p.importCtx, fileCtx, producer, consumer)
+	// Stop loading row groups if the import of the file stopped early, e.g.
+	// because it reached the row limit.
+	cancel()
+	return errors.CombineErrors(err, loaders.Wait())
+}
+
+// parquetFile is a parquet file in external storage.
+type parquetFile struct {
+	es   cloud.ExternalStorage
+	meta *parquet.FileMetaData
+	// metaOffset is the offset of the metadata of the file, which follows all
+	// its row groups.
+	metaOffset int64
+	// columns are the top level columns of the file.
+	columns []*parquetColumn
+}
+
+// openParquetFile reads the metadata in the footer of a parquet file.
+func openParquetFile(ctx context.Context, es cloud.ExternalStorage) (*parquetFile, error) {
+	size, err := es.Size(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	readAt := func(offset, length int64) ([]byte, error) {
+		r, _, err := es.ReadFileAt(ctx, "", offset)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	// The file ends with its metadata, the length of the metadata as a 4 byte
+	// little endian integer and the magic number.
+	if size < int64(2*len(parquetMagic)+4) {
+		return nil, errors.Errorf("invalid parquet file: file is too small")
+	}
+	trailer, err := readAt(size-8, 8)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(trailer[4:], parquetMagic) {
+		return nil, errors.Errorf("invalid parquet file: missing magic number in footer")
+	}
+	metaLen := int64(binary.LittleEndian.Uint32(trailer[:4]))
+	metaOffset := size - 8 - metaLen
+	if metaLen <= 0 || metaOffset < int64(len(parquetMagic)) {
+		return nil, errors.Errorf("invalid parquet file: invalid metadata length %d", metaLen)
+	}
+	encoded, err := readAt(metaOffset, metaLen)
+	if err != nil {
+		return nil, err
+	}
+	meta := &parquet.FileMetaData{}
+	if err := meta.Read(thrift.NewTCompactProtocol(
+		&thrift.StreamTransport{Reader: bytes.NewReader(encoded)},
+	)); err != nil {
+		return nil, errors.Wrap(err, "decoding parquet file metadata")
+	}
+	if len(meta.Schema) == 0 {
+		return nil, errors.Errorf("invalid parquet file: empty schema")
+	}
+
+	columns, next, err := newParquetColumns(meta.Schema, 1, meta.Schema[0].GetNumChildren())
+	if err != nil {
+		return nil, err
+	}
+	if next != len(meta.Schema) {
+		return nil, errors.Errorf("invalid parquet file: malformed schema")
+	}
+	return &parquetFile{es: es, meta: meta, metaOffset: metaOffset, columns: columns}, nil
+}
+
+// readRowGroup reads the rows of the i'th row group of the file, only
+// decoding the given columns.
+func (f *parquetFile) readRowGroup(
+	ctx context.Context, i int, columns []string,
+) ([]map[string]interface{}, error) {
+	// The parquet reader can only skip row groups after decoding them, so it is
+	// given a view of the file whose footer only lists the row group to read.
+	// The offsets of its column chunks in the metadata are relative to the
+	// beginning of the file, so the data preceding the footer is unchanged.
+	meta := *f.meta
+	meta.RowGroups = []*parquet.RowGroup{f.meta.RowGroups[i]}
+	meta.NumRows = f.meta.RowGroups[i].NumRows
+	var footer bytes.Buffer
+	if err := meta.Write(thrift.NewTCompactProtocol(&thrift.StreamTransport{Writer: &footer})); err != nil {
+		return nil, err
+	}
+	metaLen := footer.Len()
+	var encodedLen [4]byte
+	binary.LittleEndian.PutUint32(encodedLen[:], uint32(metaLen))
+	footer.Write(encodedLen[:])
+	footer.Write(parquetMagic)
+
+	r := &parquetRowGroupReader{
+		ctx:     ctx,
+		es:      f.es,
+		dataEnd: f.metaOffset,
+		footer:  footer.Bytes(),
+	}
+	defer r.Close()
+	reader, err := goparquet.NewFileReader(r, columns...)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]interface{}, 0, meta.NumRows)
+	for {
+		record, err := reader.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading row group %d", i)
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+// parquetRowGroupReader is an io.ReadSeeker over a parquet file in external
+// storage, whose footer is replaced with the given one. Reads of the file
+// preceding the footer are served by ReadFileAt, reopening the file when the
+// reader is positioned at a different offset.
+type parquetRowGroupReader struct {
+	ctx     context.Context
+	es      cloud.ExternalStorage
+	dataEnd int64
+	footer  []byte
+
+	pos int64
+	// body, if set, is a reader of the file positioned at bodyPos.
+	body    io.ReadCloser
+	bodyPos int64
+}
+
+var _ io.ReadSeeker = &parquetRowGroupReader{}
+
+// Read implements io.Reader.
+func (r *parquetRowGroupReader) Read(p []byte) (int, error) {
+	if r.pos >= r.dataEnd {
+		if r.pos >= r.dataEnd+int64(len(r.footer)) {
+			return 0, io.EOF
+		}
+		n := copy(p, r.footer[r.pos-r.dataEnd:])
+		r.pos += int64(n)
+		return n, nil
+	}
+
+	if remaining := r.dataEnd - r.pos; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	if r.body == nil || r.bodyPos != r.pos {
+		if err := r.Close(); err != nil {
+			return 0, err
+		}
+		body, _, err := r.es.ReadFileAt(r.ctx, "", r.pos)
+		if err != nil {
+			return 0, err
+		}
+		r.body, r.bodyPos = body, r.pos
+	}
+	n, err := r.body.Read(p)
+	r.pos += int64(n)
+	r.bodyPos += int64(n)
+	if err == io.EOF {
+		// The file is longer than the data preceding the footer.
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (r *parquetRowGroupReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.dataEnd + int64(len(r.footer))
+	default:
+		return 0, errors.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.Errorf("invalid offset %d", offset)
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+// Close closes the underlying reader of the file, if any.
+func (r *parquetRowGroupReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+type parquetRowGroupResult struct {
+	rows []map[string]interface{}
+	err  error
+}
+
+type parquetRowGroup struct {
+	numRows int64
+	// results receives the rows of the row group once it is loaded. It is nil
+	// if the row group is not loaded because all its rows are skipped.
+	results chan parquetRowGroupResult
+}
+
+// parquetRowStream produces the rows of the row groups of a parquet file in
+// order, as they are loaded.
+type parquetRowStream struct {
+	ctx    context.Context
+	groups []parquetRowGroup
+	total  int64
+	// sem limits the number of row groups which are loaded but not consumed.
+	sem chan struct{}
+
+	// group is the index of the current row group, and pos the number of its
+	// rows which were scanned. rows are the rows of the group, unless it is
+	// skipped.
+	group  int
+	pos    int64
+	rows   []map[string]interface{}
+	loaded bool
+
+	scanned int64
+	err     error
+}
+
+var _ importRowProducer = &parquetRowStream{}
+
+// Scan implements importRowProducer.
+func (s *parquetRowStream) Scan() bool {
+	if s.group == len(s.groups) {
+		return false
+	}
+	for s.group < 0 || s.pos == s.groups[s.group].numRows {
+		if s.loaded {
+			<-s.sem
+			s.loaded = false
+		}
+		s.group++
+		s.pos, s.rows = 0, nil
+		if s.group == len(s.groups) {
+			return false
+		}
+		results := s.groups[s.group].results
+		if results == nil {
+			continue
+		}
+		select {
+		case res := <-results:
+			if res.err != nil {
+				s.err = res.err
+				return false
+			}
+			if int64(len(res.rows)) != s.groups[s.group].numRows {
+				s.err = errors.Errorf("expected %d rows in row group %d, found %d",
+					s.groups[s.group].numRows, s.group, len(res.rows))
+				return false
+			}
+			s.rows, s.loaded = res.rows, true
+		case <-s.ctx.Done():
+			s.err = s.ctx.Err()
+			return false
+		}
+	}
+	s.pos++
+	s.scanned++
+	return true
+}
+
+// Err implements importRowProducer.
+func (s *parquetRowStream) Err() error {
+	return s.err
+}
+
+// Skip implements importRowProducer.
+func (s *parquetRowStream) Skip() error {
+	return nil
+}
+
+// Row implements importRowProducer.
+func (s *parquetRowStream) Row() (interface{}, error) {
+	if s.rows == nil {
+		return nil, errors.AssertionFailedf("row group %d was not loaded", s.group)
+	}
+	return s.rows[s.pos-1], nil
+}
+
+// Progress implements importRowProducer.
+func (s *parquetRowStream) Progress() float32 {
+	if s.total == 0 {
+		return 0
+	}
+	return float32(s.scanned) / float32(s.total)
+}
+
+// parquetTargetColumn maps a column of a parquet file to the index of a
+// target column in the datums of a DatumRowConverter.
+type parquetTargetColumn struct {
+	col *parquetColumn
+	idx int
+}
+
+// parquetConsumer implements importRowConsumer.
+type parquetConsumer struct {
+	columns []parquetTargetColumn
+	// projection is the names of the columns of the file which are read.
+	projection []string
+}
+
+var _ importRowConsumer = &parquetConsumer{}
+
+// newParquetConsumer maps the top level columns of a parquet file to the
+// target columns of the import by name. Columns of the file which do not map
+// to a target column are not read, unless strict is set, in which case they
+// are an error.
+func newParquetConsumer(
+	importCtx *parallelImportContext, file *parquetFile, strict bool,
+) (*parquetConsumer, error) {
+	// The datums of a DatumRowConverter are ordered like the target columns,
+	// or the visible columns if there are no target columns.
+	targetIdxByName := make(map[string]int)
+	if len(importCtx.targetCols) > 0 {
+		for i, name := range importCtx.targetCols {
+			targetIdxByName[string(name)] = i
+		}
+	} else {
+		for i, col := range importCtx.tableDesc.VisibleColumns() {
+			targetIdxByName[col.GetName()] = i
+		}
+	}
+
+	c := &parquetConsumer{}
+	found := make(map[string]bool, len(targetIdxByName))
+	for _, col := range file.columns {
+		name := lexbase.NormalizeName(col.name)
+		idx, ok := targetIdxByName[name]
+		if !ok {
+			if strict {
+				return nil, errors.Errorf("could not find column for parquet column %s", col.name)
+			}
+			continue
+		}
+		if found[name] {
+			return nil, errors.Errorf("duplicate parquet column %s", col.name)
+		}
+		found[name] = true
+		c.columns = append(c.columns, parquetTargetColumn{col: col, idx: idx})
+		c.projection = append(c.projection, col.name)
+	}
+	if strict {
+		for name := range targetIdxByName {
+			if !found[name] {
+				return nil, errors.Errorf("column %s was not found in the parquet file", name)
+			}
+		}
+	}
+	return c, nil
+}
+
+// FillDatums implements importRowConsumer.
+func (c *parquetConsumer) FillDatums(
+	record interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	values, ok := record.(map[string]interface{})
+	if !ok {
+		return errors.AssertionFailedf("unexpected parquet record type %T", record)
+	}
+	for _, target := range c.columns {
+		datum, err := target.col.datum(values[target.col.name], conv.VisibleColTypes[target.idx], conv.EvalCtx)
+		if err != nil {
+			return err
+		}
+		conv.Datums[target.idx] = datum
+	}
+
+	// Columns which are not in the file are NULL.
+	for i := range conv.Datums {
+		if conv.TargetColOrds.Contains(i) && conv.Datums[i] == nil {
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// parquetLogicalType is the logical type of a parquet column, determined by
+// either its logical type or its (legacy) converted type.
+type parquetLogicalType int
+
+const (
+	parquetPlain parquetLogicalType = iota
+	// parquetString is a UTF-8 string, an enum or a JSON document.
+	parquetString
+	parquetDate
+	parquetTime
+	parquetTimestamp
+	parquetDecimal
+	parquetUUID
+	parquetList
+	// parquetUnsupported is a logical type which cannot be imported, such as a
+	// map or an interval.
+	parquetUnsupported
+)
+
+// parquetColumn is a column in the schema of a parquet file.
+type parquetColumn struct {
+	name     string
+	elem     *parquet.SchemaElement
+	children []*parquetColumn
+
+	logicalType parquetLogicalType
+	// unit is the unit of time and timestamp columns.
+	unit time.Duration
+	// adjustedToUTC is set for timestamp columns whose values are instants.
+	adjustedToUTC bool
+	// scale is the scale of decimal columns.
+	scale int32
+}
+
+// newParquetColumns builds the n columns whose schema elements start at the
+// given index in the flattened schema of a parquet file, and returns the
+// index following their elements.
+func newParquetColumns(
+	elems []*parquet.SchemaElement, start int, n int32,
+) ([]*parquetColumn, int, error) {
+	columns := make([]*parquetColumn, 0, n)
+	next := start
+	for i := int32(0); i < n; i++ {
+		if next >= len(elems) {
+			return nil, 0, errors.Errorf("invalid parquet file: malformed schema")
+		}
+		col := newParquetColumn(elems[next])
+		var err error
+		col.children, next, err = newParquetColumns(elems, next+1, elems[next].GetNumChildren())
+		if err != nil {
+			return nil, 0, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, next, nil
+}
+
+func newParquetColumn(elem *parquet.SchemaElement) *parquetColumn {
+	c := &parquetColumn{name: elem.Name, elem: elem}
+	if lt := elem.LogicalType; lt != nil {
+		switch {
+		case lt.IsSetSTRING(), lt.IsSetENUM(), lt.IsSetJSON():
+			c.logicalType = parquetString
+		case lt.IsSetDATE():
+			c.logicalType = parquetDate
+		case lt.IsSetTIME():
+			c.logicalType = parquetTime
+			c.unit = parquetTimeUnit(lt.TIME.GetUnit())
+		case lt.IsSetTIMESTAMP():
+			c.logicalType = parquetTimestamp
+			c.unit = parquetTimeUnit(lt.TIMESTAMP.GetUnit())
+			c.adjustedToUTC = lt.TIMESTAMP.GetIsAdjustedToUTC()
+		case lt.IsSetDECIMAL():
+			c.logicalType = parquetDecimal
+			c.scale = lt.DECIMAL.Scale
+		case lt.IsSetUUID():
+			c.logicalType = parquetUUID
+		case lt.IsSetLIST():
+			c.logicalType = parquetList
+		case lt.IsSetMAP(), lt.IsSetBSON():
+			c.logicalType = parquetUnsupported
+		}
+		return c
+	}
+	if elem.ConvertedType == nil {
+		return c
+	}
+	switch *elem.ConvertedType {
+	case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON:
+		c.logicalType = parquetString
+	case parquet.ConvertedType_DATE:
+		c.logicalType = parquetDate
+	case parquet.ConvertedType_TIME_MILLIS:
+		c.logicalType, c.unit = parquetTime, time.Millisecond
+	case parquet.ConvertedType_TIME_MICROS:
+		c.logicalType, c.unit = parquetTime, time.Microsecond
+	case parquet.ConvertedType_TIMESTAMP_MILLIS:
+		c.logicalType, c.unit, c.adjustedToUTC = parquetTimestamp, time.Millisecond, true
+	case parquet.ConvertedType_TIMESTAMP_MICROS:
+		c.logicalType, c.unit, c.adjustedToUTC = parquetTimestamp, time.Microsecond, true
+	case parquet.ConvertedType_DECIMAL:
+		c.logicalType, c.scale = parquetDecimal, elem.GetScale()
+	case parquet.ConvertedType_LIST:
+		c.logicalType = parquetList
+	case parquet.ConvertedType_MAP, parquet.ConvertedType_MAP_KEY_VALUE,
+		parquet.ConvertedType_BSON, parquet.ConvertedType_INTERVAL:
+		c.logicalType = parquetUnsupported
+	}
+	return c
+}
+
+func parquetTimeUnit(unit *parquet.TimeUnit) time.Duration {
+	switch {
+	case unit.IsSetMILLIS():
+		return time.Millisecond
+	case unit.IsSetNANOS():
+		return time.Nanosecond
+	}
+	return time.Microsecond
+}
+
+func (c *parquetColumn) repeated() bool {
+	return c.elem.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED
+}
+
+func (c *parquetColumn) unsupported(targetT *types.T) error {
+	return errors.Errorf("cannot convert parquet column %s to %s", c.name, targetT.SQLString())
+}
+
+// datum converts the value of the column in a row, as returned by the parquet
+// reader, to a datum of the target type.
+func (c *parquetColumn) datum(
+	v interface{}, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if v == nil {
+		return tree.DNull, nil
+	}
+	switch {
+	case c.logicalType == parquetList:
+		return c.listDatum(v, targetT, evalCtx)
+	case c.logicalType == parquetUnsupported || len(c.children) > 0:
+		return nil, c.unsupported(targetT)
+	case c.repeated():
+		// A repeated primitive column which is not part of a list is an array.
+		return c.arrayDatum(v, c, targetT, evalCtx)
+	}
+	return c.primitiveDatum(v, targetT, evalCtx)
+}
+
+// listDatum converts the value of a LIST column to an array. A LIST column is
+// a group with a single repeated field, which is either the element of the
+// list or a group whose single field is the element.
+func (c *parquetColumn) listDatum(
+	v interface{}, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if targetT.Family() != types.ArrayFamily || len(c.children) != 1 || !c.children[0].repeated() {
+		return nil, c.unsupported(targetT)
+	}
+	group, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v of parquet column %s", v, c.name)
+	}
+	repeated := c.children[0]
+	values, ok := group[repeated.name]
+	if !ok {
+		return tree.NewDArray(targetT.ArrayContents()), nil
+	}
+	if len(repeated.children) == 0 {
+		return c.arrayDatum(values, repeated, targetT, evalCtx)
+	}
+	if len(repeated.children) != 1 {
+		return nil, c.unsupported(targetT)
+	}
+	elem := repeated.children[0]
+	entries, ok := values.([]map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v of parquet column %s", values, c.name)
+	}
+	arr := tree.NewDArray(targetT.ArrayContents())
+	for _, entry := range entries {
+		d, err := elem.datum(entry[elem.name], targetT.ArrayContents(), evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		if err := arr.Append(d); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
+// arrayDatum converts the values of a repeated primitive column, which the
+// parquet reader returns as a slice, to an array.
+func (c *parquetColumn) arrayDatum(
+	v interface{}, elem *parquetColumn, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if targetT.Family() != types.ArrayFamily {
+		return nil, c.unsupported(targetT)
+	}
+	values := reflect.ValueOf(v)
+	if values.Kind() != reflect.Slice {
+		return nil, errors.Errorf("unexpected value %v of parquet column %s", v, c.name)
+	}
+	arr := tree.NewDArray(targetT.ArrayContents())
+	for i := 0; i < values.Len(); i++ {
+		d, err := elem.primitiveDatum(values.Index(i).Interface(), targetT.ArrayContents(), evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		if err := arr.Append(d); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
+// primitiveDatum converts a value of a primitive column to a datum of the
+// target type. The value is first converted to the datum its logical type
+// corresponds to, which is then cast to the target type if needed.
+func (c *parquetColumn) primitiveDatum(
+	v interface{}, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	var d tree.Datum
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return tree.DNull, nil
+	case bool:
+		d = tree.MakeDBool(tree.DBool(v))
+	case int32:
+		d, err = c.intDatum(int64(v), targetT)
+	case int64:
+		d, err = c.intDatum(v, targetT)
+	case [12]byte:
+		// INT96 is a deprecated encoding of timestamps.
+		d, err = parquetTimestampDatum(goparquet.Int96ToTime(v), false /* adjustedToUTC */, targetT)
+	case float32:
+		d = tree.NewDFloat(tree.DFloat(v))
+	case float64:
+		d = tree.NewDFloat(tree.DFloat(v))
+	case []byte:
+		d, err = c.bytesDatum(v, targetT, evalCtx)
+	default:
+		return nil, errors.Errorf("unexpected value %v of parquet column %s", v, c.name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parquet column %s", c.name)
+	}
+	if d == tree.DNull || d.ResolvedType().Equivalent(targetT) {
+		return d, nil
+	}
+	d, err = tree.PerformCast(evalCtx, d, targetT)
+	if err != nil {
+		return nil, errors.Wrapf(err, "converting parquet column %s to %s", c.name, targetT.SQLString())
+	}
+	return d, nil
+}
+
+func (c *parquetColumn) intDatum(v int64, targetT *types.T) (tree.Datum, error) {
+	switch c.logicalType {
+	case parquetDate:
+		date, err := pgdate.MakeDateFromUnixEpoch(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDDate(date), nil
+	case parquetTime:
+		return tree.MakeDTime(timeofday.FromInt(v * int64(c.unit) / int64(time.Microsecond))), nil
+	case parquetTimestamp:
+		perSecond := int64(time.Second / c.unit)
+		t := timeutil.Unix(v/perSecond, (v%perSecond)*int64(c.unit))
+		return parquetTimestampDatum(t, c.adjustedToUTC, targetT)
+	case parquetDecimal:
+		d := &tree.DDecimal{}
+		d.SetFinite(v, -c.scale)
+		return d, nil
+	case parquetPlain:
+		return tree.NewDInt(tree.DInt(v)), nil
+	}
+	return nil, c.unsupported(targetT)
+}
+
+// parquetTimestampDatum converts a timestamp to a TIMESTAMPTZ if that is the
+// target type or if the timestamp is an instant, and to a TIMESTAMP otherwise.
+func parquetTimestampDatum(t time.Time, adjustedToUTC bool, targetT *types.T) (tree.Datum, error) {
+	switch targetT.Family() {
+	case types.TimestampTZFamily:
+		return tree.MakeDTimestampTZ(t, time.Microsecond)
+	case types.TimestampFamily:
+		return tree.MakeDTimestamp(t, time.Microsecond)
+	}
+	if adjustedToUTC {
+		return tree.MakeDTimestampTZ(t, time.Microsecond)
+	}
+	return tree.MakeDTimestamp(t, time.Microsecond)
+}
+
+func (c *parquetColumn) bytesDatum(
+	v []byte, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	switch c.logicalType {
+	case parquetDecimal:
+		// Decimals are stored as their unscaled value in big-endian two's
+		// complement.
+		n := new(big.Int).SetBytes(v)
+		if len(v) > 0 && v[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(v))))
+		}
+		d := &tree.DDecimal{}
+		d.Coeff.Abs(n)
+		d.Negative = n.Sign() < 0
+		d.Exponent = -c.scale
+		return d, nil
+	case parquetUUID:
+		return tree.ParseDUuidFromBytes(v)
+	case parquetPlain:
+		if targetT.Family() == types.BytesFamily {
+			return tree.NewDBytes(tree.DBytes(v)), nil
+		}
+		// Binary columns without a logical type are commonly strings written
+		// by older writers.
+		fallthrough
+	case parquetString:
+		if targetT.Family() == types.JsonFamily {
+			return tree.ParseDJSON(string(v))
+		}
+		return rowenc.ParseDatumStringAs(targetT, string(v), evalCtx)
+	}
+	return nil, c.unsupported(targetT)
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// writeParquetFile writes the rows to a parquet file, starting a new row
+// group every rowGroupSize rows.
+func writeParquetFile(
+	t *testing.T, path string, schema string, rows []map[string]interface{}, rowGroupSize int,
+) {
+	sd, err := parquetschema.ParseSchemaDefinition(schema)
+	require.NoError(t, err)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	w := goparquet.NewFileWriter(f,
+		goparquet.WithSchemaDefinition(sd),
+		goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+	)
+	for i, row := range rows {
+		require.NoError(t, w.AddData(row))
+		if (i+1)%rowGroupSize == 0 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+}
+
+func TestImportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const schema = `message m {
+		required int64 id;
+		optional binary name (STRING);
+		optional int32 day (DATE);
+		optional int64 ts (TIMESTAMP(MICROS, true));
+		optional binary amount (DECIMAL(10, 2));
+		optional group tags (LIST) {
+			repeated group list {
+				required int64 element;
+			}
+		}
+		optional binary extra (STRING);
+	}`
+	const numRows = 10
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	var rows []map[string]interface{}
+	var expected [][]string
+	for i := 0; i < numRows; i++ {
+		amount := make([]byte, 2)
+		binary.BigEndian.PutUint16(amount, uint16(int16(i*100-250)))
+		row := map[string]interface{}{
+			"id":     int64(i),
+			"day":    int32(18628 + i),
+			"ts":     timeutil.ToUnixMicros(ts.Add(time.Duration(i) * time.Hour)),
+			"amount": amount,
+			"tags": map[string]interface{}{"list": []map[string]interface{}{
+				{"element": int64(i)}, {"element": int64(i * i)},
+			}},
+			"extra": []byte("extra"),
+		}
+		name := "NULL"
+		if i != 5 {
+			name = fmt.Sprintf("name-%d", i)
+			row["name"] = []byte(name)
+		}
+		rows = append(rows, row)
+		expected = append(expected, []string{
+			fmt.Sprint(i),
+			name,
+			fmt.Sprintf("2021-01-%02d", i+1),
+			fmt.Sprintf("2021-01-02 %02d:04:05+00:00", 3+i),
+			fmt.Sprintf("%.2f", float64(i*100-250)/100),
+			fmt.Sprintf("{%d,%d}", i, i*i),
+		})
+	}
+	// Rows are split into several row groups, which are read concurrently.
+	writeParquetFile(t, filepath.Join(dir, "data.parquet"), schema, rows, 3 /* rowGroupSize */)
+
+	sqlDB.Exec(t, `SET TIME ZONE 'UTC'`)
+	const createTable = `CREATE TABLE %s (
+		id INT PRIMARY KEY, name STRING, day DATE, ts TIMESTAMPTZ, amount DECIMAL(10, 2), tags INT[]
+	)`
+
+	t.Run("import-into", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "t"))
+		sqlDB.Exec(t, `IMPORT INTO t PARQUET DATA ('nodelocal://0/data.parquet')`)
+		sqlDB.CheckQueryResults(t,
+			`SELECT id, name, day::STRING, ts::STRING, amount, tags FROM t ORDER BY id`, expected)
+	})
+
+	t.Run("target-columns", func(t *testing.T) {
+		// Only the columns of the file which are imported are read.
+		sqlDB.Exec(t, `CREATE TABLE target (id INT PRIMARY KEY, name STRING, n INT DEFAULT 7)`)
+		sqlDB.Exec(t, `IMPORT INTO target (id, name) PARQUET DATA ('nodelocal://0/data.parquet')`)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM target WHERE id IN (4, 5)`,
+			[][]string{{"4", "name-4", "7"}, {"5", "NULL", "7"}})
+	})
+
+	t.Run("strict-validation", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "strict"))
+		sqlDB.ExpectErr(t, "could not find column for parquet column extra",
+			`IMPORT INTO strict PARQUET DATA ('nodelocal://0/data.parquet') WITH strict_validation`)
+
+		sqlDB.Exec(t, `CREATE TABLE strict_missing (
+			id INT PRIMARY KEY, name STRING, day DATE, ts TIMESTAMPTZ, amount DECIMAL(10, 2), tags INT[],
+			extra STRING, missing STRING
+		)`)
+		sqlDB.ExpectErr(t, "column missing was not found in the parquet file",
+			`IMPORT INTO strict_missing PARQUET DATA ('nodelocal://0/data.parquet') WITH strict_validation`)
+	})
+
+	t.Run("row-limit", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(createTable, "limited"))
+		sqlDB.Exec(t, `IMPORT INTO limited PARQUET DATA ('nodelocal://0/data.parquet') WITH row_limit = '4'`)
+		sqlDB.CheckQueryResults(t, `SELECT id FROM limited ORDER BY id`,
+			[][]string{{"0"}, {"1"}, {"2"}, {"3"}})
+	})
+
+	t.Run("incompatible-type", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE incompatible (id INT PRIMARY KEY, tags INT)`)
+		sqlDB.ExpectErr(t, "cannot convert parquet column tags to INT8",
+			`IMPORT INTO incompatible (id, tags) PARQUET DATA ('nodelocal://0/data.parquet')`)
+	})
+
+	t.Run("export-roundtrip", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE exported (
+			i INT PRIMARY KEY, d DECIMAL(10, 2), e DECIMAL, a INT[], j JSONB, ts TIMESTAMPTZ, s STRING, b BYTES
+		)`)
+		sqlDB.Exec(t, `INSERT INTO exported VALUES
+			(1, 1.5, 1.2345, ARRAY[1, 3], '{"b": [1, 2]}', '2021-01-02 03:04:05.123456+02', 'abc', '\x0102'),
+			(2, -1.25, NULL, ARRAY[], NULL, NULL, NULL, NULL)`)
+		sqlDB.Exec(t, `EXPORT INTO PARQUET 'nodelocal://0/exported' FROM SELECT * FROM exported`)
+		sqlDB.Exec(t, `CREATE TABLE imported (LIKE exported INCLUDING ALL)`)
+		sqlDB.Exec(t, `IMPORT INTO imported PARQUET DATA ('nodelocal://0/exported/export*-n1.0.parquet')`)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM imported ORDER BY i`,
+			sqlDB.QueryStr(t, `SELECT * FROM exported ORDER BY i`))
+	})
+}
@@ -310,6 +310,12 @@ type pgCopyConsumer struct {
 }
 
 var _ importRowConsumer = &pgCopyConsumer{}
+var _ importRowStringer = &pgCopyConsumer{}
+
+// RowString implements importRowStringer
+func (p *pgCopyConsumer) RowString(row interface{}) string {
+	return row.(copyData).String()
+}
 
 // FillDatums implements importRowConsumer
 func (p *pgCopyConsumer) FillDatums(
@@ -341,7 +347,11 @@ func (p *pgCopyConsumer) FillDatums(
 }
 
 func (d *pgCopyReader) readFile(
-	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	resumePos int64,
+	rejected chan *importRowError,
 ) error {
 	s := bufio.NewScanner(input)
 	s.Split(bufio.ScanLines)
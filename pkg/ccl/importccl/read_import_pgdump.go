@@ -10,7 +10,9 @@ package importccl
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"regexp"
 	"strings"
@@ -26,11 +28,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
@@ -42,16 +46,20 @@ import (
 )
 
 type postgreStream struct {
-	s    *bufio.Scanner
-	copy *postgreStreamCopy
+	s                     *bufio.Scanner
+	copy                  *postgreStreamCopy
+	unsupportedStmtLogger *unsupportedStmtLogger
 }
 
 // newPostgreStream returns a struct that can stream statements from an
-// io.Reader.
-func newPostgreStream(r io.Reader, max int) *postgreStream {
+// io.Reader. The statements which are skipped are reported to the
+// unsupportedStmtLogger.
+func newPostgreStream(
+	r io.Reader, max int, unsupportedStmtLogger *unsupportedStmtLogger,
+) *postgreStream {
 	s := bufio.NewScanner(r)
 	s.Buffer(nil, max)
-	p := &postgreStream{s: s}
+	p := &postgreStream{s: s, unsupportedStmtLogger: unsupportedStmtLogger}
 	s.Split(p.split)
 	return p
 }
@@ -98,12 +106,23 @@ func (p *postgreStream) Next() (interface{}, error) {
 		// Regardless if we can parse the statement, check that it's not something
 		// we want to ignore.
 		if isIgnoredStatement(t) {
+			if err := p.unsupportedStmtLogger.log(t, "ignored statement"); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
 		stmts, err := parser.Parse(t)
 		if err != nil {
-			return nil, err
+			if isIgnoredIfUnparseable(t) {
+				err = p.unsupportedStmtLogger.log(t, "ignored statement")
+			} else {
+				err = p.unsupportedStmtLogger.ignore(t, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			continue
 		}
 		switch len(stmts) {
 		case 0:
@@ -148,17 +167,22 @@ var (
 	ignoreComments   = regexp.MustCompile(`^\s*(--.*)`)
 	ignoreStatements = []*regexp.Regexp{
 		regexp.MustCompile("(?i)^alter function"),
-		regexp.MustCompile("(?i)^alter sequence .* owned by"),
-		regexp.MustCompile("(?i)^comment on"),
 		regexp.MustCompile("(?i)^create extension"),
 		regexp.MustCompile("(?i)^create function"),
 		regexp.MustCompile("(?i)^create trigger"),
 		regexp.MustCompile("(?i)^grant .* on sequence"),
 		regexp.MustCompile("(?i)^revoke .* on sequence"),
 	}
+	// The statements which are ignored if they cannot be parsed, e.g. the
+	// comments on extensions or functions, as opposed to the comments on tables
+	// or columns.
+	ignoreUnparseableStatements = []*regexp.Regexp{
+		regexp.MustCompile("(?i)^comment on"),
+	}
 )
 
-func isIgnoredStatement(s string) bool {
+// trimComments strips the comments and whitespace which precede a statement.
+func trimComments(s string) string {
 	// Look for the first line with no whitespace or comments.
 	for {
 		m := ignoreComments.FindStringIndex(s)
@@ -167,7 +191,11 @@ func isIgnoredStatement(s string) bool {
 		}
 		s = s[m[1]:]
 	}
-	s = strings.TrimSpace(s)
+	return strings.TrimSpace(s)
+}
+
+func isIgnoredStatement(s string) bool {
+	s = trimComments(s)
 	for _, re := range ignoreStatements {
 		if re.MatchString(s) {
 			return true
@@ -176,6 +204,112 @@ func isIgnoredStatement(s string) bool {
 	return false
 }
 
+func isIgnoredIfUnparseable(s string) bool {
+	s = trimComments(s)
+	for _, re := range ignoreUnparseableStatements {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredStmtLogFlushSize is the size of the logged statements above which
+// they are written to a new file of the log_ignored_statements directory.
+const ignoredStmtLogFlushSize = 10 << 20
+
+// unsupportedStmtLogger keeps track of the statements of a PGDUMP file which
+// are skipped, either because IMPORT always ignores them, or because they are
+// not supported and ignore_unsupported_statements is specified. If
+// log_ignored_statements is specified, the skipped statements are written,
+// along with the reason they were skipped, to files in that directory, whose
+// names contain logName, e.g. ignored_statements for the statements skipped
+// while reading the file.
+type unsupportedStmtLogger struct {
+	ctx               context.Context
+	ignoreUnsupported bool
+
+	// logBuffer is nil unless the skipped statements are logged.
+	logBuffer       *bytes.Buffer
+	logDir          string
+	logName         string
+	jobID           int64
+	user            security.SQLUsername
+	externalStorage cloud.ExternalStorageFromURIFactory
+	flushCount      int
+
+	numIgnoredStmts int
+}
+
+func makeUnsupportedStmtLogger(
+	ctx context.Context,
+	user security.SQLUsername,
+	jobID int64,
+	logName string,
+	opts roachpb.PgDumpOptions,
+	externalStorage cloud.ExternalStorageFromURIFactory,
+) *unsupportedStmtLogger {
+	l := &unsupportedStmtLogger{
+		ctx:               ctx,
+		ignoreUnsupported: opts.IgnoreUnsupported,
+		logDir:            opts.IgnoreUnsupportedLog,
+		logName:           logName,
+		jobID:             jobID,
+		user:              user,
+		externalStorage:   externalStorage,
+	}
+	if l.logDir != "" {
+		l.logBuffer = new(bytes.Buffer)
+	}
+	return l
+}
+
+// ignore returns the error which makes a statement unsupported, unless
+// ignore_unsupported_statements is specified, in which case the statement is
+// skipped.
+func (u *unsupportedStmtLogger) ignore(stmt string, err error) error {
+	if !u.ignoreUnsupported {
+		return err
+	}
+	return u.log(stmt, err.Error())
+}
+
+// log records a skipped statement.
+func (u *unsupportedStmtLogger) log(stmt string, reason string) error {
+	u.numIgnoredStmts++
+	if u.logBuffer == nil {
+		return nil
+	}
+	// The log reads like a SQL file, whose comments explain why the statements
+	// were skipped.
+	fmt.Fprintf(u.logBuffer, "-- %s\n%s;\n", strings.ReplaceAll(reason, "\n", "\n-- "),
+		strings.TrimSuffix(trimComments(stmt), ";"))
+	if u.logBuffer.Len() >= ignoredStmtLogFlushSize {
+		return u.flush()
+	}
+	return nil
+}
+
+// flush writes the statements logged since the last flush to a new file of
+// the log_ignored_statements directory.
+func (u *unsupportedStmtLogger) flush() error {
+	if u.logBuffer == nil || u.logBuffer.Len() == 0 {
+		return nil
+	}
+	store, err := u.externalStorage(u.ctx, u.logDir, u.user)
+	if err != nil {
+		return errors.Wrap(err, "failed to log ignored statements")
+	}
+	defer store.Close()
+	name := fmt.Sprintf("import%d_%s_%d.log", u.jobID, u.logName, u.flushCount)
+	if err := store.WriteFile(u.ctx, name, bytes.NewReader(u.logBuffer.Bytes())); err != nil {
+		return errors.Wrap(err, "failed to log ignored statements")
+	}
+	u.flushCount++
+	u.logBuffer.Reset()
+	return nil
+}
+
 type regclassRewriter struct{}
 
 var _ tree.Visitor = regclassRewriter{}
@@ -229,7 +363,8 @@ func readPostgresCreateTable(
 	fks fkHandler,
 	max int,
 	owner security.SQLUsername,
-) ([]*tabledesc.Mutable, error) {
+	unsupportedStmtLogger *unsupportedStmtLogger,
+) ([]*tabledesc.Mutable, []string, error) {
 	// Modify the CreateTable stmt with the various index additions. We do this
 	// instead of creating a full table descriptor first and adding indexes
 	// later because MakeSimpleTableDescriptor calls the sql package which calls
@@ -239,7 +374,13 @@ func readPostgresCreateTable(
 	createTbl := make(map[string]*tree.CreateTable)
 	createSeq := make(map[string]*tree.CreateSequence)
 	tableFKs := make(map[string][]*tree.ForeignKeyConstraintTableDef)
-	ps := newPostgreStream(input, max)
+	// enums are the labels of the enum types of the file, which are skipped
+	// when unsupported statements are ignored.
+	enums := make(map[string]tree.EnumValueList)
+	// The statements which are executed once the tables are published, in the
+	// order of the file.
+	var deferred []string
+	ps := newPostgreStream(input, max, unsupportedStmtLogger)
 	for {
 		stmt, err := ps.Next()
 		if err == io.EOF {
@@ -259,7 +400,7 @@ func readPostgresCreateTable(
 					nil, /* params */
 				)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				fks.resolver[desc.Name] = desc
 				ret = append(ret, desc)
@@ -270,10 +411,13 @@ func readPostgresCreateTable(
 					continue
 				}
 				removeDefaultRegclass(create)
+				if err := replaceEnumColumns(create, enums); err != nil {
+					return nil, nil, err
+				}
 				id := descpb.ID(int(defaultCSVTableID) + len(ret))
 				desc, err := MakeSimpleTableDescriptor(evalCtx.Ctx(), p.SemaCtx(), p.ExecCfg().Settings, create, parentID, keys.PublicSchemaID, id, fks, walltime)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				fks.resolver[desc.Name] = desc
 				backrefs[desc.ID] = desc
@@ -288,11 +432,11 @@ func readPostgresCreateTable(
 					if err := sql.ResolveFK(
 						evalCtx.Ctx(), nil /* txn */, fks.resolver, desc, constraint, backrefs, sql.NewTable, tree.ValidationDefault, evalCtx,
 					); err != nil {
-						return nil, err
+						return nil, nil, err
 					}
 				}
 				if err := fixDescriptorFKState(desc); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 			}
 			if match != "" && len(ret) != 1 {
@@ -300,18 +444,21 @@ func readPostgresCreateTable(
 				for name := range createTbl {
 					found = append(found, name)
 				}
-				return nil, errors.Errorf("table %q not found in file (found tables: %s)", match, strings.Join(found, ", "))
+				return nil, nil, errors.Errorf("table %q not found in file (found tables: %s)", match, strings.Join(found, ", "))
 			}
 			if len(ret) == 0 {
-				return nil, errors.Errorf("no table definition found")
+				return nil, nil, errors.Errorf("no table definition found")
 			}
-			return ret, nil
+			return ret, deferred, nil
 		}
 		if err != nil {
-			return nil, errors.Wrap(err, "postgres parse error")
+			return nil, nil, errors.Wrap(err, "postgres parse error")
 		}
-		if err := readPostgresStmt(ctx, evalCtx, match, fks, createTbl, createSeq, tableFKs, stmt, p, parentID); err != nil {
-			return nil, err
+		if err := readPostgresStmt(
+			ctx, evalCtx, match, fks, createTbl, createSeq, tableFKs, enums, &deferred, stmt, p, parentID,
+			unsupportedStmtLogger,
+		); err != nil {
+			return nil, nil, err
 		}
 	}
 }
@@ -324,9 +471,12 @@ func readPostgresStmt(
 	createTbl map[string]*tree.CreateTable,
 	createSeq map[string]*tree.CreateSequence,
 	tableFKs map[string][]*tree.ForeignKeyConstraintTableDef,
+	enums map[string]tree.EnumValueList,
+	deferred *[]string,
 	stmt interface{},
 	p sql.JobExecContext,
 	parentID descpb.ID,
+	unsupportedStmtLogger *unsupportedStmtLogger,
 ) error {
 	switch stmt := stmt.(type) {
 	case *tree.CreateTable:
@@ -402,7 +552,7 @@ func readPostgresStmt(
 				}
 			case *tree.AlterTableAddColumn:
 				if cmd.IfNotExists {
-					return errors.Errorf("unsupported statement: %s", stmt)
+					return unsupportedStmtLogger.ignore(stmt.String(), errors.Errorf("unsupported statement: %s", stmt))
 				}
 				create.Defs = append(create.Defs, cmd.ColumnDef)
 			case *tree.AlterTableSetNotNull:
@@ -425,7 +575,7 @@ func readPostgresStmt(
 			case *tree.AlterTableValidateConstraint:
 				// ignore
 			default:
-				return errors.Errorf("unsupported statement: %s", stmt)
+				return unsupportedStmtLogger.ignore(stmt.String(), errors.Errorf("unsupported statement: %s", stmt))
 			}
 		}
 	case *tree.AlterTableOwner:
@@ -438,6 +588,99 @@ func readPostgresStmt(
 		if match == "" || match == name {
 			createSeq[name] = stmt
 		}
+	case *tree.AlterSequence:
+		name, err := getTableName2(stmt.Name)
+		if err != nil {
+			return err
+		}
+		if createSeq[name] == nil {
+			break
+		}
+		// pg_dump makes the sequences of serial columns owned by their columns,
+		// which requires the tables to be created first.
+		ownerImported := true
+		for _, opt := range stmt.Options {
+			if opt.Name != tree.SeqOptOwnedBy || opt.ColumnItemVal == nil {
+				continue
+			}
+			if opt.ColumnItemVal.TableName == nil {
+				return errors.Errorf("unsupported statement: %s", stmt)
+			}
+			owner, err := getTableName2(opt.ColumnItemVal.TableName)
+			if err != nil {
+				return err
+			}
+			ownerImported = ownerImported && createTbl[owner] != nil
+		}
+		if ownerImported {
+			*deferred = append(*deferred, tree.AsString(stmt))
+		}
+	case *tree.CreateType:
+		if stmt.Variety != tree.Enum {
+			return unsupportedStmtLogger.ignore(stmt.String(), errors.Errorf("unsupported statement: %s", stmt))
+		}
+		// IMPORT does not create user defined types, so the enums are only
+		// skipped when unsupported statements are ignored, in which case the
+		// columns of their types are imported as STRING columns.
+		if !unsupportedStmtLogger.ignoreUnsupported {
+			return unimplemented.Newf("import enum",
+				"unsupported statement: %s: enum types cannot be imported, "+
+					"use ignore_unsupported_statements to import the columns of enum types as STRING columns", stmt)
+		}
+		name, err := getTableName2(stmt.TypeName)
+		if err != nil {
+			return err
+		}
+		enums[name] = stmt.EnumLabels
+		return unsupportedStmtLogger.log(stmt.String(), fmt.Sprintf(
+			"enum types cannot be imported: the columns of type %s are imported as STRING columns "+
+				"restricted to its labels by a CHECK constraint", stmt.TypeName))
+	case *tree.AlterType:
+		if _, ok := stmt.Cmd.(*tree.AlterTypeOwner); !ok {
+			return unsupportedStmtLogger.ignore(stmt.String(), errors.Errorf("unsupported statement: %s", stmt))
+		}
+	case *tree.CreateSchema:
+		// The tables of a non-public schema cannot be imported, but the schema
+		// is created along with the views which may be in it.
+		if match == "" && stmt.Schema.Schema() != tree.PublicSchema {
+			*deferred = append(*deferred, tree.AsString(stmt))
+		}
+	case *tree.AlterSchema:
+		if _, ok := stmt.Cmd.(*tree.AlterSchemaOwner); !ok {
+			return unsupportedStmtLogger.ignore(stmt.String(), errors.Errorf("unsupported statement: %s", stmt))
+		}
+	case *tree.CreateView, *tree.RefreshMaterializedView:
+		// Views are created once the tables they depend on are published, unless
+		// a single table is imported.
+		if match == "" {
+			*deferred = append(*deferred, tree.AsString(stmt.(tree.Statement)))
+		}
+	case *tree.CommentOnTable:
+		name, err := getTableName2(stmt.Table)
+		if err != nil {
+			return err
+		}
+		if createTbl[name] != nil {
+			*deferred = append(*deferred, tree.AsString(stmt))
+		}
+	case *tree.CommentOnColumn:
+		if stmt.ColumnItem.TableName == nil {
+			return errors.Errorf("unsupported statement: %s", stmt)
+		}
+		name, err := getTableName2(stmt.ColumnItem.TableName)
+		if err != nil {
+			return err
+		}
+		if createTbl[name] != nil {
+			*deferred = append(*deferred, tree.AsString(stmt))
+		}
+	case *tree.CommentOnIndex:
+		// pg_dump does not qualify the indexes with their tables.
+		if match == "" {
+			*deferred = append(*deferred, tree.AsString(stmt))
+		}
+	case *tree.CommentOnDatabase:
+		// ignore the comment of the database the file was dumped from.
 	// Some SELECT statements mutate schema. Search for those here.
 	case *tree.Select:
 		switch sel := stmt.Select.(type) {
@@ -465,7 +708,12 @@ func readPostgresStmt(
 						case "set_config", "setval":
 							continue
 						default:
-							return errors.Errorf("unsupported function call: %s", expr.Func.String())
+							if err := unsupportedStmtLogger.ignore(
+								stmt.String(), errors.Errorf("unsupported function call: %s", expr.Func.String()),
+							); err != nil {
+								return err
+							}
+							continue
 						}
 					}
 					// Attempt to convert all func exprs to datums.
@@ -493,7 +741,10 @@ func readPostgresStmt(
 					for _, fnStmt := range fnStmts {
 						switch ast := fnStmt.AST.(type) {
 						case *tree.AlterTable:
-							if err := readPostgresStmt(ctx, evalCtx, match, fks, createTbl, createSeq, tableFKs, ast, p, parentID); err != nil {
+							if err := readPostgresStmt(
+								ctx, evalCtx, match, fks, createTbl, createSeq, tableFKs, enums, deferred, ast, p, parentID,
+								unsupportedStmtLogger,
+							); err != nil {
 								return err
 							}
 						default:
@@ -546,11 +797,69 @@ func readPostgresStmt(
 			return stmt
 		}
 	default:
+		if s, ok := stmt.(tree.Statement); ok {
+			return unsupportedStmtLogger.ignore(s.String(), errors.Errorf("unsupported %T statement: %s", stmt, stmt))
+		}
 		return errors.Errorf("unsupported %T statement: %s", stmt, stmt)
 	}
 	return nil
 }
 
+// replaceEnumColumns replaces the columns of a table whose type is one of the
+// enums of the file, which are skipped, by STRING columns restricted to the
+// labels of the enum, like the ENUM columns of MySQL dumps.
+func replaceEnumColumns(create *tree.CreateTable, enums map[string]tree.EnumValueList) error {
+	enumLabels := func(typ tree.ResolvableTypeReference) (tree.EnumValueList, bool, error) {
+		typName, ok := typ.(*tree.UnresolvedObjectName)
+		if !ok {
+			return nil, false, nil
+		}
+		name, err := getTableName2(typName)
+		if err != nil {
+			return nil, false, err
+		}
+		labels, ok := enums[name]
+		return labels, ok, nil
+	}
+	for _, def := range create.Defs {
+		col, ok := def.(*tree.ColumnTableDef)
+		if !ok {
+			continue
+		}
+		labels, ok, err := enumLabels(col.Type)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		col.Type = types.String
+		// pg_dump casts the default values of the column to the enum, e.g.
+		// 'happy'::public.mood.
+		if cast, ok := col.DefaultExpr.Expr.(*tree.CastExpr); ok {
+			if _, ok, err := enumLabels(cast.Type); err != nil {
+				return err
+			} else if ok {
+				col.DefaultExpr.Expr = cast.Expr
+			}
+		}
+
+		values := make([]string, len(labels))
+		for i := range labels {
+			values[i] = lexbase.EscapeSQLString(string(labels[i]))
+		}
+		expr, err := parser.ParseExpr(fmt.Sprintf("%s IN (%s)", tree.AsString(&col.Name), strings.Join(values, ", ")))
+		if err != nil {
+			return err
+		}
+		create.Defs = append(create.Defs, &tree.CheckConstraintTableDef{
+			Name: tree.Name(fmt.Sprintf("imported_from_enum_%s", col.Name)),
+			Expr: expr,
+		})
+	}
+	return nil
+}
+
 func getTableName(tn *tree.TableName) (string, error) {
 	if sc := tn.Schema(); sc != "" && sc != "public" {
 		return "", unimplemented.NewWithIssueDetailf(
@@ -650,12 +959,18 @@ func (m *pgDumpReader) readFiles(
 }
 
 func (m *pgDumpReader) readFile(
-	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+	ctx context.Context,
+	input *fileReader,
+	inputIdx int32,
+	resumePos int64,
+	rejected chan *importRowError,
 ) error {
 	tableNameToRowsProcessed := make(map[string]int64)
 	var inserts, count int64
 	rowLimit := m.opts.RowLimit
-	ps := newPostgreStream(input, int(m.opts.MaxRowSize))
+	// The skipped statements are only logged while reading the schema.
+	unsupportedStmtLogger := &unsupportedStmtLogger{ignoreUnsupported: m.opts.IgnoreUnsupported}
+	ps := newPostgreStream(input, int(m.opts.MaxRowSize), unsupportedStmtLogger)
 	semaCtx := tree.MakeSemaContext()
 	for _, conv := range m.tables {
 		conv.KvBatch.Source = inputIdx
@@ -911,24 +1226,48 @@ func (m *pgDumpReader) readFile(
 			case "addgeometrycolumn":
 				// handled during schema extraction.
 			default:
-				return errors.Errorf("unsupported function: %s", funcName)
+				if err := unsupportedStmtLogger.ignore(
+					i.String(), errors.Errorf("unsupported function: %s", funcName),
+				); err != nil {
+					return err
+				}
 			}
 		case *tree.SetVar, *tree.BeginTransaction, *tree.CommitTransaction, *tree.Analyze:
 			// ignored.
 		case *tree.CreateTable, *tree.AlterTable, *tree.AlterTableOwner, *tree.CreateIndex, *tree.CreateSequence, *tree.DropTable:
 			// handled during schema extraction.
+		case *tree.AlterSequence, *tree.CreateType, *tree.AlterType, *tree.CreateSchema, *tree.AlterSchema,
+			*tree.CreateView, *tree.RefreshMaterializedView,
+			*tree.CommentOnTable, *tree.CommentOnColumn, *tree.CommentOnIndex, *tree.CommentOnDatabase:
+			// handled during schema extraction.
 		case *tree.Delete:
 			switch stmt := i.Table.(type) {
 			case *tree.AliasedTableExpr:
 				// ogr2ogr has `DELETE FROM geometry_columns / geography_columns ...` statements.
 				// We're not planning to support this functionality in CRDB, so it is safe to ignore it when countered in PGDUMP.
 				if tn, ok := stmt.Expr.(*tree.TableName); !(ok && (tn.Table() == "geometry_columns" || tn.Table() == "geography_columns")) {
-					return errors.Errorf("unsupported DELETE FROM %T statement: %s", stmt, stmt)
+					if err := unsupportedStmtLogger.ignore(
+						i.String(), errors.Errorf("unsupported DELETE FROM %T statement: %s", stmt, stmt),
+					); err != nil {
+						return err
+					}
 				}
 			default:
-				return errors.Errorf("unsupported %T statement: %s", i, i)
+				if err := unsupportedStmtLogger.ignore(
+					i.String(), errors.Errorf("unsupported %T statement: %s", i, i),
+				); err != nil {
+					return err
+				}
 			}
 		default:
+			if s, ok := i.(tree.Statement); ok {
+				if err := unsupportedStmtLogger.ignore(
+					s.String(), errors.Errorf("unsupported %T statement: %v", i, i),
+				); err != nil {
+					return err
+				}
+				continue
+			}
 			return errors.Errorf("unsupported %T statement: %v", i, i)
 		}
 	}
@@ -232,10 +232,11 @@ func evalExport(
 		reply.Files = append(reply.Files, exported)
 		start = resume
 
-		// If we are not returning the SSTs to the processor, there is no need to
-		// paginate the ExportRequest since the reply size will not grow large
-		// enough to cause an OOM.
-		if args.ReturnSST && h.TargetBytes > 0 {
+		// If we are not returning the SSTs to the processor, the reply size will
+		// not grow large enough to cause an OOM, but the ExportRequest is still
+		// paginated if asked to, so that the processor can limit the rate at
+		// which files are written.
+		if h.TargetBytes > 0 {
 			curSizeOfExportedSSTs += summary.DataSize
 			// There could be a situation where the size of exported SSTs is larger
 			// than the TargetBytes. In such a scenario, we want to report back
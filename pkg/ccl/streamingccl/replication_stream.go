@@ -0,0 +1,30 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingccl
+
+// The options of a CREATE REPLICATION STREAM statement, which streams the KVs
+// of a tenant out of the source cluster of a cluster to cluster stream.
+//
+// The statement returns `(key BYTES, value BYTES, resolved DECIMAL)` rows. KV
+// rows hold a key and its value, a marshaled roachpb.Value which carries the
+// MVCC timestamp of the write. Checkpoint rows only hold a resolved timestamp,
+// below which every KV in the streamed span has been emitted.
+const (
+	// ReplicationStreamOptCursor is the timestamp after which KVs are streamed.
+	// Without a cursor, every version of every KV still in the source cluster
+	// is streamed.
+	ReplicationStreamOptCursor = `cursor`
+	// ReplicationStreamOptResolved is the minimum interval between checkpoints.
+	ReplicationStreamOptResolved = `resolved`
+	// ReplicationStreamOptStartKey and ReplicationStreamOptEndKey restrict the
+	// stream to a hex-encoded span of the tenant's keyspace, which is how a
+	// stream is split into partitions.
+	ReplicationStreamOptStartKey = `start_key`
+	ReplicationStreamOptEndKey   = `end_key`
+)
@@ -24,13 +24,14 @@ type Client interface {
 	GetTopology(address streamingccl.StreamAddress) (streamingccl.Topology, error)
 
 	// ConsumePartition returns a channel on which we can start listening for
-	// events from a given partition that occur after a startTime.
+	// events from a given partition that occur after a startTime, and a channel
+	// on which an error encountered while reading the partition is reported.
+	// At most one error is sent, after which the event channel is closed. The
+	// error channel is closed after the event channel.
 	//
 	// Canceling the context will stop reading the partition and close the event
 	// channel.
-	// TODO: Add an error channel so that the client can report any errors
-	// encountered while reading the stream.
-	ConsumePartition(ctx context.Context, address streamingccl.PartitionAddress, startTime time.Time) (chan streamingccl.Event, error)
+	ConsumePartition(ctx context.Context, address streamingccl.PartitionAddress, startTime time.Time) (chan streamingccl.Event, chan error, error)
 }
 
 // NewStreamClient creates a new stream client based on the stream
@@ -48,6 +49,8 @@ func NewStreamClient(streamAddress streamingccl.StreamAddress) (Client, error) {
 		if err != nil {
 			return streamClient, err
 		}
+	case SQLScheme, SQLSchemeAlt:
+		streamClient = &sqlStreamClient{}
 	default:
 		streamClient = &mockClient{}
 	}
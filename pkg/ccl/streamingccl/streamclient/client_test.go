@@ -39,7 +39,7 @@ func (sc testStreamClient) GetTopology(
 // ConsumePartition implements the Client interface.
 func (sc testStreamClient) ConsumePartition(
 	_ context.Context, _ streamingccl.PartitionAddress, _ time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	sampleKV := roachpb.KeyValue{
 		Key: []byte("key_1"),
 		Value: roachpb.Value{
@@ -52,8 +52,10 @@ func (sc testStreamClient) ConsumePartition(
 	events <- streamingccl.MakeKVEvent(sampleKV)
 	events <- streamingccl.MakeCheckpointEvent(hlc.Timestamp{WallTime: 100})
 	close(events)
+	errCh := make(chan error)
+	close(errCh)
 
-	return events, nil
+	return events, errCh, nil
 }
 
 // ExampleClientUsage serves as documentation to indicate how a stream
@@ -68,7 +70,7 @@ func ExampleClient() {
 	startTimestamp := timeutil.Now()
 
 	for _, partition := range topology.Partitions {
-		eventCh, err := client.ConsumePartition(context.Background(), partition, startTimestamp)
+		eventCh, errCh, err := client.ConsumePartition(context.Background(), partition, startTimestamp)
 		if err != nil {
 			panic(err)
 		}
@@ -87,6 +89,9 @@ func ExampleClient() {
 				panic(fmt.Sprintf("unexpected event type %v", event.Type()))
 			}
 		}
+		if err := <-errCh; err != nil {
+			panic(err)
+		}
 	}
 
 	// Output:
@@ -112,12 +117,14 @@ func TestImplementationsCloseChannel(t *testing.T) {
 
 	for _, impl := range impls {
 		ctx, cancel := context.WithCancel(context.Background())
-		eventCh, err := impl.ConsumePartition(ctx, "test://53/", timeutil.Now())
+		eventCh, errCh, err := impl.ConsumePartition(ctx, "test://53/", timeutil.Now())
 		require.NoError(t, err)
 
-		// Ensure that the eventCh closes when the context is canceled.
+		// Ensure that the eventCh and errCh close when the context is canceled.
 		cancel()
 		for range eventCh {
 		}
+		for range errCh {
+		}
 	}
 }
@@ -152,8 +152,9 @@ func (m *randomStreamClient) GetTopology(
 // ConsumePartition implements the Client interface.
 func (m *randomStreamClient) ConsumePartition(
 	ctx context.Context, _ streamingccl.PartitionAddress, startTime time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	eventCh := make(chan streamingccl.Event)
+	errCh := make(chan error)
 	now := timeutil.Now()
 	if startTime.After(now) {
 		panic("cannot start random stream client event stream in the future")
@@ -161,6 +162,7 @@ func (m *randomStreamClient) ConsumePartition(
 	lastResolvedTime := startTime
 
 	go func() {
+		defer close(errCh)
 		defer close(eventCh)
 
 		// rand is not thread safe, so create a random source for each partition.
@@ -212,7 +214,7 @@ func (m *randomStreamClient) ConsumePartition(
 		}
 	}()
 
-	return eventCh, nil
+	return eventCh, errCh, nil
 }
 
 func (m *randomStreamClient) makeRandomKey(r *rand.Rand, minTs time.Time) roachpb.KeyValue {
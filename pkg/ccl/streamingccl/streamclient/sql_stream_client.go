@@ -0,0 +1,269 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamclient
+
+import (
+	"context"
+	gosql "database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	// Registers the postgres driver used to connect to the source cluster.
+	_ "github.com/lib/pq"
+)
+
+const (
+	// SQLScheme and SQLSchemeAlt are the URI schemes of streams read from a
+	// CockroachDB source cluster over SQL.
+	SQLScheme    = "postgres"
+	SQLSchemeAlt = "postgresql"
+
+	// TenantIDKey is the ID of the tenant streamed out of the source cluster.
+	TenantIDKey = "TENANT_ID"
+	// PartitionStartKey and PartitionEndKey are the hex-encoded bounds of the
+	// span of the tenant's keyspace streamed by a partition.
+	PartitionStartKey = "START_KEY"
+	PartitionEndKey   = "END_KEY"
+)
+
+// sqlStreamClient reads a stream from a CockroachDB source cluster. The
+// stream's topology has one partition per range of the tenant's keyspace in
+// the source cluster, and each partition is read by running a CREATE
+// REPLICATION STREAM statement, which is backed by a rangefeed, for the span
+// of the partition.
+//
+// The stream address is the postgres URL of the source cluster, with the
+// tenant to stream in the TENANT_ID parameter.
+type sqlStreamClient struct{}
+
+var _ Client = &sqlStreamClient{}
+
+// StreamAddressForTenant returns the address of the stream of the tenant's
+// keyspace, if the stream is read from a CockroachDB source cluster and the
+// address doesn't already specify a tenant.
+func StreamAddressForTenant(
+	streamAddress streamingccl.StreamAddress, tenantID roachpb.TenantID,
+) (streamingccl.StreamAddress, error) {
+	streamURL, err := streamAddress.URL()
+	if err != nil {
+		return "", err
+	}
+	if streamURL.Scheme != SQLScheme && streamURL.Scheme != SQLSchemeAlt {
+		return streamAddress, nil
+	}
+	q := streamURL.Query()
+	if q.Get(TenantIDKey) != "" {
+		return streamAddress, nil
+	}
+	q.Set(TenantIDKey, strconv.FormatUint(tenantID.ToUint64(), 10))
+	streamURL.RawQuery = q.Encode()
+	return streamingccl.StreamAddress(streamURL.String()), nil
+}
+
+// parseSQLStreamURL returns the URL used to connect to the source cluster,
+// without the parameters of the stream, and the tenant whose keyspace is
+// streamed.
+func parseSQLStreamURL(address string) (*url.URL, roachpb.TenantID, error) {
+	streamURL, err := url.Parse(address)
+	if err != nil {
+		return nil, roachpb.TenantID{}, err
+	}
+	q := streamURL.Query()
+	tenantIDStr := q.Get(TenantIDKey)
+	if tenantIDStr == "" {
+		return nil, roachpb.TenantID{}, errors.Newf("stream address %s does not specify %s",
+			streamURL.Redacted(), TenantIDKey)
+	}
+	tenantID, err := strconv.ParseUint(tenantIDStr, 10, 64)
+	if err != nil {
+		return nil, roachpb.TenantID{}, errors.Wrapf(err, "parsing %s", TenantIDKey)
+	}
+	if tenantID == 0 {
+		return nil, roachpb.TenantID{}, errors.New("invalid tenant ID")
+	}
+	connURL := *streamURL
+	q.Del(TenantIDKey)
+	q.Del(PartitionStartKey)
+	q.Del(PartitionEndKey)
+	connURL.RawQuery = q.Encode()
+	return &connURL, roachpb.MakeTenantID(tenantID), nil
+}
+
+// GetTopology implements the Client interface.
+func (m *sqlStreamClient) GetTopology(
+	address streamingccl.StreamAddress,
+) (streamingccl.Topology, error) {
+	ctx := context.TODO()
+	connURL, tenantID, err := parseSQLStreamURL(string(address))
+	if err != nil {
+		return streamingccl.Topology{}, err
+	}
+	db, err := gosql.Open("postgres", connURL.String())
+	if err != nil {
+		return streamingccl.Topology{}, err
+	}
+	defer db.Close()
+
+	prefix := keys.MakeTenantPrefix(tenantID)
+	tenantSpan := roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}
+	rows, err := db.QueryContext(ctx, `SELECT start_key, end_key FROM crdb_internal.ranges_no_leases
+WHERE start_key < $2 AND end_key > $1 ORDER BY start_key`,
+		[]byte(tenantSpan.Key), []byte(tenantSpan.EndKey))
+	if err != nil {
+		return streamingccl.Topology{}, errors.Wrap(err, "fetching the ranges of the stream")
+	}
+	defer rows.Close()
+
+	streamURL, err := address.URL()
+	if err != nil {
+		return streamingccl.Topology{}, err
+	}
+	var topology streamingccl.Topology
+	for rows.Next() {
+		var rangeSpan roachpb.Span
+		if err := rows.Scan(&rangeSpan.Key, &rangeSpan.EndKey); err != nil {
+			return streamingccl.Topology{}, err
+		}
+		partitionSpan := rangeSpan
+		if partitionSpan.Key.Compare(tenantSpan.Key) < 0 {
+			partitionSpan.Key = tenantSpan.Key
+		}
+		if partitionSpan.EndKey.Compare(tenantSpan.EndKey) > 0 {
+			partitionSpan.EndKey = tenantSpan.EndKey
+		}
+		partitionURL := *streamURL
+		q := partitionURL.Query()
+		q.Set(PartitionStartKey, hex.EncodeToString(partitionSpan.Key))
+		q.Set(PartitionEndKey, hex.EncodeToString(partitionSpan.EndKey))
+		partitionURL.RawQuery = q.Encode()
+		topology.Partitions = append(topology.Partitions,
+			streamingccl.PartitionAddress(partitionURL.String()))
+	}
+	if err := rows.Err(); err != nil {
+		return streamingccl.Topology{}, err
+	}
+	if len(topology.Partitions) == 0 {
+		return streamingccl.Topology{}, errors.Newf("no ranges found for tenant %s", tenantID)
+	}
+	return topology, nil
+}
+
+// ConsumePartition implements the Client interface.
+func (m *sqlStreamClient) ConsumePartition(
+	ctx context.Context, address streamingccl.PartitionAddress, startTime time.Time,
+) (chan streamingccl.Event, chan error, error) {
+	connURL, tenantID, err := parseSQLStreamURL(string(address))
+	if err != nil {
+		return nil, nil, err
+	}
+	partitionURL, err := url.Parse(string(address))
+	if err != nil {
+		return nil, nil, err
+	}
+	startKey := partitionURL.Query().Get(PartitionStartKey)
+	endKey := partitionURL.Query().Get(PartitionEndKey)
+	if startKey == "" || endKey == "" {
+		return nil, nil, errors.Newf("partition address %s does not specify %s and %s",
+			partitionURL.Redacted(), PartitionStartKey, PartitionEndKey)
+	}
+
+	stmt := fmt.Sprintf(`CREATE REPLICATION STREAM FOR TENANT %d WITH %s = $1, %s = $2`,
+		tenantID.ToUint64(), streamingccl.ReplicationStreamOptStartKey,
+		streamingccl.ReplicationStreamOptEndKey)
+	args := []interface{}{startKey, endKey}
+	// The stream ingestion processor passes the Unix epoch when it has no start
+	// time, in which case every version of every KV is streamed.
+	if startTime.UnixNano() > 0 {
+		stmt += fmt.Sprintf(`, %s = $3`, streamingccl.ReplicationStreamOptCursor)
+		args = append(args, hlc.Timestamp{WallTime: startTime.UnixNano()}.AsOfSystemTime())
+	}
+
+	db, err := gosql.Open("postgres", connURL.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, errors.Wrapf(err, "starting stream of partition %s", partitionURL.Redacted())
+	}
+
+	eventCh := make(chan streamingccl.Event)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer close(eventCh)
+		defer func() {
+			_ = rows.Close()
+			_ = db.Close()
+		}()
+
+		if err := readSQLStream(ctx, rows, eventCh); err != nil && ctx.Err() == nil {
+			errCh <- errors.Wrapf(err, "reading stream of partition %s", partitionURL.Redacted())
+		}
+	}()
+	return eventCh, errCh, nil
+}
+
+// readSQLStream sends the events of the rows returned by CREATE REPLICATION
+// STREAM on eventCh until the rows are exhausted or the context is canceled.
+func readSQLStream(
+	ctx context.Context, rows *gosql.Rows, eventCh chan<- streamingccl.Event,
+) error {
+	for rows.Next() {
+		var key, value []byte
+		var resolved gosql.NullString
+		if err := rows.Scan(&key, &value, &resolved); err != nil {
+			return err
+		}
+		event, err := parseSQLStreamEvent(key, value, resolved)
+		if err != nil {
+			return err
+		}
+		select {
+		case eventCh <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return rows.Err()
+}
+
+// parseSQLStreamEvent converts a row returned by CREATE REPLICATION STREAM
+// into an event.
+func parseSQLStreamEvent(
+	key, value []byte, resolved gosql.NullString,
+) (streamingccl.Event, error) {
+	if resolved.Valid {
+		d, err := tree.ParseDDecimal(resolved.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing resolved timestamp")
+		}
+		ts, err := tree.DecimalToHLC(&d.Decimal)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing resolved timestamp")
+		}
+		return streamingccl.MakeCheckpointEvent(ts), nil
+	}
+	kv := roachpb.KeyValue{Key: key}
+	if err := protoutil.Unmarshal(value, &kv.Value); err != nil {
+		return nil, errors.Wrapf(err, "decoding value of key %s", roachpb.Key(key))
+	}
+	return streamingccl.MakeKVEvent(kv), nil
+}
@@ -10,18 +10,45 @@ package streamingest
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
 
+// cutoverSignalPollInterval is the interval at which a stream ingestion job
+// polls its progress for a signal to cut over.
+var cutoverSignalPollInterval = settings.RegisterDurationSetting(
+	"bulkio.stream_ingestion.cutover_signal_poll_interval",
+	"the interval at which the stream ingestion job checks if it has been signaled to cutover",
+	30*time.Second,
+	settings.NonNegativeDuration,
+)
+
+// errCutoverReached is returned by the cutover signal poller to stop the
+// ingestion once the job has ingested all KVs up to its cutover time.
+var errCutoverReached = errors.New("stream ingestion reached its cutover time")
+
+// errStreamEndedBeforeCutover is returned when the stream ends before the job
+// has ingested all KVs up to its cutover time. The ingested cluster is only
+// consistent once it has been reverted to the cutover time, so the job must
+// not succeed in that case.
+var errStreamEndedBeforeCutover = errors.New("stream ended before the stream ingestion job reached its cutover time")
+
 type streamIngestionResumer struct {
 	job *jobs.Job
 }
@@ -77,13 +104,37 @@ func ingest(
 }
 
 // Resume is part of the jobs.Resumer interface.
-func (s *streamIngestionResumer) Resume(ctx context.Context, execCtx interface{}) error {
+func (s *streamIngestionResumer) Resume(resumerCtx context.Context, execCtx interface{}) error {
 	details := s.job.Details().(jobspb.StreamIngestionDetails)
 	p := execCtx.(sql.JobExecContext)
 
-	err := ingest(ctx, p, details.StreamAddress, s.job.Progress(),
-		*s.job.ID())
-	if err != nil {
+	// Ingest KVs from the stream while polling the job progress for a signal to
+	// cut over. Once the job has ingested all KVs up to the cutover time, the
+	// poller stops the ingestion.
+	stopPoller := make(chan struct{})
+	g := ctxgroup.WithContext(resumerCtx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(stopPoller)
+		if err := ingest(ctx, p, details.StreamAddress, s.job.Progress(), *s.job.ID()); err != nil {
+			return err
+		}
+		// The stream ended on its own. The job can only complete if it has been
+		// signaled to cut over and has ingested every KV up to the cutover time.
+		reached, err := s.cutoverReached(ctx, p.ExecCfg())
+		if err != nil {
+			return err
+		}
+		if !reached {
+			return errStreamEndedBeforeCutover
+		}
+		return errCutoverReached
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		return s.checkForCutoverSignal(ctx, stopPoller, p.ExecCfg())
+	})
+	if err := g.Wait(); !errors.Is(err, errCutoverReached) {
+		// The ingestion only stops without an error once it has reached its
+		// cutover time, so err is never nil here.
 		return err
 	}
 
@@ -91,13 +142,119 @@ func (s *streamIngestionResumer) Resume(ctx context.Context, execCtx interface{}
 	// the processors have completed setup. We can then return the job ID in the
 	// plan hook similar to how changefeeds do it.
 
+	return s.revertToCutoverTimestamp(resumerCtx, p.ExecCfg())
+}
+
+// checkForCutoverSignal periodically loads the job progress to check whether
+// the job has been signaled to complete. It returns errCutoverReached once the
+// resolved timestamp of the job has reached the requested cutover time, and
+// nil if stopPoller is closed first.
+func (s *streamIngestionResumer) checkForCutoverSignal(
+	ctx context.Context, stopPoller chan struct{}, execCfg *sql.ExecutorConfig,
+) error {
+	tick := time.NewTicker(cutoverSignalPollInterval.Get(&execCfg.Settings.SV))
+	defer tick.Stop()
+	for {
+		select {
+		case <-stopPoller:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+			reached, err := s.cutoverReached(ctx, execCfg)
+			if err != nil {
+				return err
+			}
+			if reached {
+				return errCutoverReached
+			}
+		}
+	}
+}
+
+// cutoverReached loads the job progress and returns whether the job has been
+// signaled to cut over and has ingested every KV up to the cutover time.
+func (s *streamIngestionResumer) cutoverReached(
+	ctx context.Context, execCfg *sql.ExecutorConfig,
+) (bool, error) {
+	jobID := *s.job.ID()
+	j, err := execCfg.JobRegistry.LoadJob(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	progress := j.Progress()
+	sp, ok := progress.GetDetails().(*jobspb.Progress_StreamIngest)
+	if !ok {
+		return false, errors.Newf("unknown progress type %T in stream ingestion job %d",
+			progress.Progress, jobID)
+	}
+	cutoverTime := sp.StreamIngest.CutoverTime
+	if cutoverTime.IsEmpty() {
+		return false, nil
+	}
+	// The job has been signaled to complete, but it can only cut over once it
+	// has ingested every KV up to the cutover time.
+	highWater := progress.GetHighWater()
+	if highWater == nil || highWater.Less(cutoverTime) {
+		return false, nil
+	}
+	log.Infof(ctx, "stream ingestion job %d reached cutover time %s", jobID, cutoverTime)
+	return true, nil
+}
+
+// revertToCutoverTimestamp reverts the ingested span to the cutover time
+// recorded in the job progress, rolling back any KVs ingested above it.
+func (s *streamIngestionResumer) revertToCutoverTimestamp(
+	ctx context.Context, execCfg *sql.ExecutorConfig,
+) error {
+	j, err := execCfg.JobRegistry.LoadJob(ctx, *s.job.ID())
+	if err != nil {
+		return err
+	}
+	details := j.Details().(jobspb.StreamIngestionDetails)
+	cutoverTime := j.Progress().GetStreamIngest().CutoverTime
+	log.Infof(ctx, "reverting stream ingestion job %d to cutover time %s", *s.job.ID(), cutoverTime)
+	return revertSpan(ctx, execCfg.DB, details.Span, cutoverTime)
+}
+
+// revertSpan reverts all the KVs in the span to their state as of the target
+// time.
+func revertSpan(ctx context.Context, db *kv.DB, sp roachpb.Span, targetTime hlc.Timestamp) error {
+	spans := []roachpb.Span{sp}
+	for len(spans) != 0 {
+		var b kv.Batch
+		for _, sp := range spans {
+			b.AddRawRequest(&roachpb.RevertRangeRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key:    sp.Key,
+					EndKey: sp.EndKey,
+				},
+				TargetTime:                          targetTime,
+				EnableTimeBoundIteratorOptimization: true,
+			})
+		}
+		b.Header.MaxSpanRequestKeys = sql.RevertTableDefaultBatchSize
+		if err := db.Run(ctx, &b); err != nil {
+			return err
+		}
+
+		spans = spans[:0]
+		for _, raw := range b.RawResponse().Responses {
+			r := raw.GetRevertRange()
+			if r.ResumeSpan != nil {
+				if !r.ResumeSpan.Valid() {
+					return errors.Errorf("invalid resume span: %s", r.ResumeSpan)
+				}
+				spans = append(spans, *r.ResumeSpan)
+			}
+		}
+	}
 	return nil
 }
 
 // OnFailOrCancel is part of the jobs.Resumer interface.
 func (s *streamIngestionResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
 	p := execCtx.(sql.JobExecContext)
-	db := p.ExecCfg().DB
 	details := s.job.Details().(jobspb.StreamIngestionDetails)
 
 	resolvedTime := details.StartTime
@@ -109,22 +266,60 @@ func (s *streamIngestionResumer) OnFailOrCancel(ctx context.Context, execCtx int
 		}
 		resolvedTime = *highWatermark
 	}
-	var b kv.Batch
-	b.AddRawRequest(&roachpb.RevertRangeRequest{
-		RequestHeader: roachpb.RequestHeader{
-			Key:    details.Span.Key,
-			EndKey: details.Span.EndKey,
-		},
-		TargetTime:                          resolvedTime,
-		EnableTimeBoundIteratorOptimization: true,
-	})
-	b.Header.MaxSpanRequestKeys = sql.RevertTableDefaultBatchSize
-	return db.Run(ctx, &b)
+	return revertSpan(ctx, p.ExecCfg().DB, details.Span, resolvedTime)
+}
+
+// completeStreamIngestion signals a running stream ingestion job to complete
+// its ingestion as of the cutover timestamp, by recording the cutover time in
+// the job progress polled by the job.
+func completeStreamIngestion(
+	evalCtx *tree.EvalContext, txn *kv.Txn, jobID int64, cutoverTimestamp hlc.Timestamp,
+) error {
+	p, ok := evalCtx.Planner.(sql.PlanHookState)
+	if !ok {
+		return errors.AssertionFailedf("unexpected planner type %T", evalCtx.Planner)
+	}
+	ctx := evalCtx.Context
+	execCfg := p.ExecCfg()
+	if err := utilccl.CheckEnterpriseEnabled(
+		execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(),
+		"RESTORE FROM REPLICATION STREAM",
+	); err != nil {
+		return err
+	}
+	if err := p.RequireAdminRole(ctx, "complete a stream ingestion job"); err != nil {
+		return err
+	}
+	return execCfg.JobRegistry.UpdateJobWithTxn(ctx, jobID, txn,
+		func(txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			if err := md.CheckRunningOrReverting(); err != nil {
+				return err
+			}
+			details, ok := md.Payload.UnwrapDetails().(jobspb.StreamIngestionDetails)
+			if !ok {
+				return errors.Newf("job %d is not a stream ingestion job", jobID)
+			}
+			if cutoverTimestamp.Less(details.StartTime) {
+				return errors.Newf("cutover time %s is before the start time %s of job %d",
+					cutoverTimestamp, details.StartTime, jobID)
+			}
+			// TODO: Allow the cutover time to be corrected as long as the job has
+			// not started reverting to it.
+			progress := md.Progress.GetStreamIngest()
+			if !progress.CutoverTime.IsEmpty() {
+				return errors.Newf("cutover time already set to %s, job %d is in the process of cutting over",
+					progress.CutoverTime, jobID)
+			}
+			progress.CutoverTime = cutoverTimestamp
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
 }
 
 var _ jobs.Resumer = &streamIngestionResumer{}
 
 func init() {
+	builtins.CompleteStreamIngestion = completeStreamIngestion
 	jobs.RegisterConstructor(
 		jobspb.TypeStreamIngestion,
 		func(job *jobs.Job,
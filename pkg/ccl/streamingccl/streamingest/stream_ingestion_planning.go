@@ -12,6 +12,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -77,10 +78,18 @@ func ingestionPlanHook(
 
 		// TODO(adityamaru): Add privileges checks. Probably the same as RESTORE.
 
+		// Streams read from a CockroachDB source cluster need to know which
+		// tenant to stream.
+		streamAddress, err := streamclient.StreamAddressForTenant(
+			streamingccl.StreamAddress(from[0]), ingestionStmt.Targets.Tenant)
+		if err != nil {
+			return err
+		}
+
 		prefix := keys.MakeTenantPrefix(ingestionStmt.Targets.Tenant)
 		streamIngestionDetails := jobspb.StreamIngestionDetails{
-			StreamAddress: streamingccl.StreamAddress(from[0]),
-			Span:          roachpb.Span{Key: prefix, EndKey: prefix.Next()},
+			StreamAddress: streamAddress,
+			Span:          roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
 			// TODO: Figure out what the initial ts should be.
 			StartTime: hlc.Timestamp{},
 		}
@@ -67,6 +67,9 @@ type streamIngestionProcessor struct {
 
 	// eventCh is the merged event channel of all of the partition event streams.
 	eventCh chan partitionEvent
+
+	// errCh is the merged error channel of all of the partition event streams.
+	errCh chan error
 }
 
 // partitionEvent augments a normal event with the partition it came from.
@@ -130,14 +133,16 @@ func (sip *streamIngestionProcessor) Start(ctx context.Context) context.Context
 
 	startTime := timeutil.Unix(0 /* sec */, sip.spec.StartTime.WallTime)
 	eventChs := make(map[streamingccl.PartitionAddress]chan streamingccl.Event)
+	errChs := make(map[streamingccl.PartitionAddress]chan error)
 	for _, partitionAddress := range sip.spec.PartitionAddresses {
-		eventCh, err := sip.client.ConsumePartition(ctx, partitionAddress, startTime)
+		eventCh, errCh, err := sip.client.ConsumePartition(ctx, partitionAddress, startTime)
 		if err != nil {
 			sip.ingestionErr = errors.Wrapf(err, "consuming partition %v", partitionAddress)
 		}
 		eventChs[partitionAddress] = eventCh
+		errChs[partitionAddress] = errCh
 	}
-	sip.eventCh = merge(ctx, eventChs)
+	sip.eventCh, sip.errCh = merge(ctx, eventChs, errChs)
 
 	return ctx
 }
@@ -208,17 +213,27 @@ func (sip *streamIngestionProcessor) flush() error {
 }
 
 // merge takes events from all the streams and merges them into a single
-// channel.
+// channel, and does the same for the errors of the streams. An error of a
+// stream is sent on the merged error channel before the merged event channel
+// is closed.
 func merge(
-	ctx context.Context, partitionStreams map[streamingccl.PartitionAddress]chan streamingccl.Event,
-) chan partitionEvent {
+	ctx context.Context,
+	partitionStreams map[streamingccl.PartitionAddress]chan streamingccl.Event,
+	errChs map[streamingccl.PartitionAddress]chan error,
+) (chan partitionEvent, chan error) {
 	merged := make(chan partitionEvent)
+	// Each stream sends at most one error.
+	mergedErrCh := make(chan error, len(partitionStreams))
 
 	var wg sync.WaitGroup
 	wg.Add(len(partitionStreams))
 
 	for partition, eventCh := range partitionStreams {
-		go func(partition streamingccl.PartitionAddress, eventCh <-chan streamingccl.Event) {
+		go func(
+			partition streamingccl.PartitionAddress,
+			eventCh <-chan streamingccl.Event,
+			errCh <-chan error,
+		) {
 			defer wg.Done()
 			for event := range eventCh {
 				pe := partitionEvent{
@@ -229,19 +244,25 @@ func merge(
 				select {
 				case merged <- pe:
 				case <-ctx.Done():
-					// TODO: Add ctx.Err() to an error channel once ConsumePartition
-					// supports an error ch.
+					mergedErrCh <- ctx.Err()
 					return
 				}
 			}
-		}(partition, eventCh)
+			// The error channel is closed once the stream has sent its error, if
+			// any.
+			if errCh != nil {
+				if err := <-errCh; err != nil {
+					mergedErrCh <- err
+				}
+			}
+		}(partition, eventCh, errChs[partition])
 	}
 	go func() {
 		wg.Wait()
 		close(merged)
 	}()
 
-	return merged
+	return merged, mergedErrCh
 }
 
 // consumeEvents handles processing events on the merged event queue and returns
@@ -252,7 +273,18 @@ func merge(
 // increasing after it has flushed all KV events previously received by that
 // partition.
 func (sip *streamIngestionProcessor) consumeEvents() (*jobspb.ResolvedSpan, error) {
-	for event := range sip.eventCh {
+	for {
+		var event partitionEvent
+		var ok bool
+		select {
+		case event, ok = <-sip.eventCh:
+		case err := <-sip.errCh:
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
 		switch event.Type() {
 		case streamingccl.KVEvent:
 			kv := event.GetKV()
@@ -293,6 +325,13 @@ func (sip *streamIngestionProcessor) consumeEvents() (*jobspb.ResolvedSpan, erro
 		}
 	}
 
+	// Every stream has ended. Any error is sent before the event channel is
+	// closed.
+	select {
+	case err := <-sip.errCh:
+		return nil, err
+	default:
+	}
 	return nil, nil
 }
 
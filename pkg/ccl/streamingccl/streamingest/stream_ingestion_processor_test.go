@@ -63,11 +63,11 @@ func (m *mockStreamClient) GetTopology(
 // ConsumePartition implements the StreamClient interface.
 func (m *mockStreamClient) ConsumePartition(
 	_ context.Context, address streamingccl.PartitionAddress, _ time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	var events []streamingccl.Event
 	var ok bool
 	if events, ok = m.partitionEvents[address]; !ok {
-		return nil, errors.Newf("no events found for paritition %s", address)
+		return nil, nil, errors.Newf("no events found for paritition %s", address)
 	}
 
 	eventCh := make(chan streamingccl.Event, len(events))
@@ -76,8 +76,10 @@ func (m *mockStreamClient) ConsumePartition(
 		eventCh <- event
 	}
 	close(eventCh)
+	errCh := make(chan error)
+	close(errCh)
 
-	return eventCh, nil
+	return eventCh, errCh, nil
 }
 
 // Close implements the StreamClient interface.
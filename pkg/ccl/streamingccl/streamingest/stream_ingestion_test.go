@@ -0,0 +1,194 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamproducer"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// startSourceCluster starts a cluster with tenant 10 whose keyspace can be
+// streamed out of it, and returns the postgres URL of the cluster.
+func startSourceCluster(t *testing.T) (*kv.DB, url.URL, func()) {
+	source, sourceDB, sourceKVDB := serverutils.StartServer(t, base.TestServerArgs{})
+	sourceSQL := sqlutils.MakeSQLRunner(sourceDB)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	sourceSQL.Exec(t, `SELECT crdb_internal.create_tenant(10)`)
+
+	pgURL, cleanupURL := sqlutils.PGUrl(t, source.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	return sourceKVDB, pgURL, func() {
+		cleanupURL()
+		source.Stopper().Stop(context.Background())
+	}
+}
+
+// tenantKey returns the i-th test key in the keyspace of tenant 10.
+func tenantKey(i int) roachpb.Key {
+	return append(keys.MakeTenantPrefix(roachpb.MakeTenantID(10)), fmt.Sprintf("key%d", i)...)
+}
+
+// TestStreamIngestionFromSourceCluster streams a tenant's keyspace out of one
+// in-process cluster into another using the SQL stream client.
+func TestStreamIngestionFromSourceCluster(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	defer jobs.TestingSetAdoptAndCancelIntervals(100*time.Millisecond, 100*time.Millisecond)()
+
+	sourceKVDB, pgURL, cleanup := startSourceCluster(t)
+	defer cleanup()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, sourceKVDB.Put(ctx, tenantKey(i), fmt.Sprintf("value%d", i)))
+	}
+
+	t.Run("client", func(t *testing.T) {
+		streamAddress, err := streamclient.StreamAddressForTenant(
+			streamingccl.StreamAddress(pgURL.String()), roachpb.MakeTenantID(10))
+		require.NoError(t, err)
+		client, err := streamclient.NewStreamClient(streamAddress)
+		require.NoError(t, err)
+		topology, err := client.GetTopology(streamAddress)
+		require.NoError(t, err)
+		require.NotEmpty(t, topology.Partitions)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var resolved bool
+		seen := make(map[string]string)
+		for _, partition := range topology.Partitions {
+			eventCh, _, err := client.ConsumePartition(ctx, partition, time.Unix(0, 0))
+			require.NoError(t, err)
+			// Every KV written before the stream started is emitted before the
+			// first checkpoint of its partition.
+			for event := range eventCh {
+				if event.Type() == streamingccl.CheckpointEvent {
+					resolved = true
+					break
+				}
+				kv := event.GetKV()
+				value, err := kv.Value.GetBytes()
+				if err != nil {
+					// Not one of the KVs written above.
+					continue
+				}
+				seen[string(kv.Key)] = string(value)
+			}
+		}
+		require.True(t, resolved)
+		for i := 0; i < 10; i++ {
+			require.Equal(t, fmt.Sprintf("value%d", i), seen[string(tenantKey(i))])
+		}
+	})
+
+	t.Run("ingestion", func(t *testing.T) {
+		dest, destDB, destKVDB := serverutils.StartServer(t, base.TestServerArgs{})
+		defer dest.Stopper().Stop(ctx)
+		destSQL := sqlutils.MakeSQLRunner(destDB)
+		destSQL.Exec(t, `RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1`, pgURL.String())
+
+		// KVs written to the source after the ingestion job started are streamed
+		// too.
+		require.NoError(t, sourceKVDB.Put(ctx, tenantKey(10), "value10"))
+		testutils.SucceedsSoon(t, func() error {
+			for i := 0; i <= 10; i++ {
+				kv, err := destKVDB.Get(ctx, tenantKey(i))
+				if err != nil {
+					return err
+				}
+				if !kv.Exists() {
+					return errors.Newf("%s has not been ingested", tenantKey(i))
+				}
+				if value := string(kv.ValueBytes()); value != fmt.Sprintf("value%d", i) {
+					return errors.Newf("unexpected value %s for %s", value, tenantKey(i))
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// TestStreamIngestionCutover tests that a stream ingestion job signaled to
+// complete stops ingesting once it has reached the cutover time, and reverts
+// the KVs it ingested above it.
+func TestStreamIngestionCutover(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	defer jobs.TestingSetAdoptAndCancelIntervals(100*time.Millisecond, 100*time.Millisecond)()
+
+	sourceKVDB, pgURL, cleanup := startSourceCluster(t)
+	defer cleanup()
+
+	// The cutover time has microsecond precision, so leave some room around it
+	// to be sure which writes happen before and after it.
+	require.NoError(t, sourceKVDB.Put(ctx, tenantKey(0), "before"))
+	time.Sleep(time.Millisecond)
+	cutoverTime := timeutil.Now().Truncate(time.Microsecond)
+	time.Sleep(time.Millisecond)
+	require.NoError(t, sourceKVDB.Put(ctx, tenantKey(0), "after"))
+	require.NoError(t, sourceKVDB.Put(ctx, tenantKey(1), "after"))
+
+	dest, destDB, destKVDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer dest.Stopper().Stop(ctx)
+	destSQL := sqlutils.MakeSQLRunner(destDB)
+	destSQL.Exec(t, `SET CLUSTER SETTING bulkio.stream_ingestion.cutover_signal_poll_interval = '100ms'`)
+	destSQL.Exec(t, `RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1`, pgURL.String())
+	var jobID int64
+	destSQL.QueryRow(t, `SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'STREAM INGESTION'`).Scan(&jobID)
+
+	// Wait for the writes above the cutover time to be ingested, so that the
+	// job has to revert them.
+	testutils.SucceedsSoon(t, func() error {
+		kv, err := destKVDB.Get(ctx, tenantKey(1))
+		if err != nil {
+			return err
+		}
+		if !kv.Exists() {
+			return errors.Newf("%s has not been ingested", tenantKey(1))
+		}
+		return nil
+	})
+
+	destSQL.Exec(t, `SELECT crdb_internal.complete_stream_ingestion_job($1, $2)`, jobID, cutoverTime)
+	jobutils.WaitForJob(t, destSQL, jobID)
+
+	kv, err := destKVDB.Get(ctx, tenantKey(0))
+	require.NoError(t, err)
+	require.Equal(t, "before", string(kv.ValueBytes()))
+	kv, err = destKVDB.Get(ctx, tenantKey(1))
+	require.NoError(t, err)
+	require.False(t, kv.Exists())
+
+	// The job can only be signaled to complete while it is running.
+	destSQL.ExpectErr(t, "cannot update progress on succeeded job", `SELECT crdb_internal.complete_stream_ingestion_job($1, $2)`,
+		jobID, cutoverTime)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamproducer
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// defaultResolvedInterval is the minimum interval between checkpoints when
+// the resolved option isn't specified.
+const defaultResolvedInterval = 200 * time.Millisecond
+
+var replicationStreamOptionExpectValues = map[string]sql.KVStringOptValidate{
+	streamingccl.ReplicationStreamOptCursor:   sql.KVStringOptRequireValue,
+	streamingccl.ReplicationStreamOptResolved: sql.KVStringOptRequireValue,
+	streamingccl.ReplicationStreamOptStartKey: sql.KVStringOptRequireValue,
+	streamingccl.ReplicationStreamOptEndKey:   sql.KVStringOptRequireValue,
+}
+
+var replicationStreamHeader = colinfo.ResultColumns{
+	{Name: "key", Typ: types.Bytes},
+	{Name: "value", Typ: types.Bytes},
+	{Name: "resolved", Typ: types.Decimal},
+}
+
+// replicationStreamPlanHook implements sql.PlanHookFn.
+//
+// Like a core changefeed, CREATE REPLICATION STREAM blocks until it is
+// canceled and returns the stream of events directly over pgwire.
+func replicationStreamPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	streamStmt, ok := stmt.(*tree.ReplicationStream)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	optsFn, err := p.TypeAsStringOpts(ctx, streamStmt.Options, replicationStreamOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(),
+			"CREATE REPLICATION STREAM",
+		); err != nil {
+			return err
+		}
+		if err := p.RequireAdminRole(ctx, "CREATE REPLICATION STREAM"); err != nil {
+			return err
+		}
+		if !kvserver.RangefeedEnabled.Get(&p.ExecCfg().Settings.SV) {
+			return errors.Errorf("streaming requires the kv.rangefeed.enabled setting")
+		}
+
+		// We only support a TENANT target.
+		targets := streamStmt.Targets
+		if targets.Tenant == (roachpb.TenantID{}) || targets.Types != nil ||
+			targets.Databases != nil || targets.Tables != nil || targets.Schemas != nil {
+			return errors.Newf("unsupported target in replication stream query, "+
+				"only tenant streaming is supported: %s", streamStmt.String())
+		}
+
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		streamSpan, err := replicationStreamSpan(targets.Tenant, opts)
+		if err != nil {
+			return err
+		}
+
+		startTime := hlc.MinTimestamp
+		if cursor, ok := opts[streamingccl.ReplicationStreamOptCursor]; ok {
+			asOf := tree.AsOfClause{Expr: tree.NewStrVal(cursor)}
+			if startTime, err = p.EvalAsOfTimestamp(ctx, asOf); err != nil {
+				return err
+			}
+		}
+
+		resolvedInterval := defaultResolvedInterval
+		if resolved, ok := opts[streamingccl.ReplicationStreamOptResolved]; ok {
+			if resolvedInterval, err = time.ParseDuration(resolved); err != nil {
+				return errors.Wrapf(err, "parsing %s", streamingccl.ReplicationStreamOptResolved)
+			}
+		}
+
+		return streamKVs(ctx, p.ExecCfg(), streamSpan, startTime, resolvedInterval, resultsCh)
+	}
+	return fn, replicationStreamHeader, nil, true /* avoidBuffering */, nil
+}
+
+// replicationStreamSpan returns the span streamed for the tenant, which is
+// its whole keyspace unless it's restricted by the options.
+func replicationStreamSpan(tenant roachpb.TenantID, opts map[string]string) (roachpb.Span, error) {
+	prefix := keys.MakeTenantPrefix(tenant)
+	tenantSpan := roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}
+	streamSpan := tenantSpan
+	for opt, key := range map[string]*roachpb.Key{
+		streamingccl.ReplicationStreamOptStartKey: &streamSpan.Key,
+		streamingccl.ReplicationStreamOptEndKey:   &streamSpan.EndKey,
+	} {
+		if encoded, ok := opts[opt]; ok {
+			decoded, err := hex.DecodeString(encoded)
+			if err != nil {
+				return roachpb.Span{}, errors.Wrapf(err, "decoding %s", opt)
+			}
+			*key = decoded
+		}
+	}
+	if !streamSpan.Valid() || !tenantSpan.Contains(streamSpan) {
+		return roachpb.Span{}, errors.Errorf("span %s is not a part of the keyspace of tenant %s",
+			streamSpan, tenant)
+	}
+	return streamSpan, nil
+}
+
+// streamKVs runs a rangefeed over the span and sends its events as rows to
+// resultsCh until the context is canceled.
+func streamKVs(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	streamSpan roachpb.Span,
+	startTime hlc.Timestamp,
+	resolvedInterval time.Duration,
+	resultsCh chan<- tree.Datums,
+) error {
+	// Like the changefeed kvfeed, drain the rangefeed into a buffered channel,
+	// since it blocks raft.
+	eventCh := make(chan *roachpb.RangeFeedEvent, 128)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		return execCfg.DistSender.RangeFeed(ctx, streamSpan, startTime, false /* withDiff */, eventCh)
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		frontier := span.MakeFrontier(streamSpan)
+		var lastResolved hlc.Timestamp
+		var lastEmit time.Time
+		for {
+			var ev *roachpb.RangeFeedEvent
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ev = <-eventCh:
+			}
+
+			var row tree.Datums
+			switch t := ev.GetValue().(type) {
+			case *roachpb.RangeFeedValue:
+				value, err := protoutil.Marshal(&t.Value)
+				if err != nil {
+					return err
+				}
+				row = tree.Datums{
+					tree.NewDBytes(tree.DBytes(t.Key)),
+					tree.NewDBytes(tree.DBytes(value)),
+					tree.DNull,
+				}
+			case *roachpb.RangeFeedCheckpoint:
+				if t.ResolvedTS.IsEmpty() {
+					// Checkpoints without a timestamp are sent before a range has
+					// finished its catch-up scan.
+					continue
+				}
+				frontier.Forward(t.Span, t.ResolvedTS)
+				resolved := frontier.Frontier()
+				if !lastResolved.Less(resolved) || timeutil.Since(lastEmit) < resolvedInterval {
+					continue
+				}
+				lastResolved, lastEmit = resolved, timeutil.Now()
+				row = tree.Datums{
+					tree.DNull,
+					tree.DNull,
+					tree.TimestampToDecimalDatum(resolved),
+				}
+			case *roachpb.RangeFeedError:
+				return t.Error.GoError()
+			default:
+				return errors.AssertionFailedf("unexpected rangefeed event type %T", t)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case resultsCh <- row:
+			}
+		}
+	})
+	return g.Wait()
+}
+
+func init() {
+	sql.AddPlanHook(replicationStreamPlanHook)
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package bulk
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// RateLimiter is a quotapool.RateLimiter whose limit can be changed, or
+// removed by setting it to 0, at any time.
+type RateLimiter struct {
+	// limit is accessed atomically.
+	limit   int64
+	limiter *quotapool.RateLimiter
+}
+
+// NewRateLimiter returns a RateLimiter without a limit.
+func NewRateLimiter(name string) *RateLimiter {
+	return &RateLimiter{limiter: quotapool.NewRateLimiter(name, quotapool.Limit(1), 1)}
+}
+
+// SetLimit sets the limit of the limiter to limit per second, allowing bursts
+// of up to a second's worth of quota.
+func (l *RateLimiter) SetLimit(limit int64) {
+	if atomic.SwapInt64(&l.limit, limit) != limit && limit > 0 {
+		l.limiter.UpdateLimit(quotapool.Limit(limit), limit)
+	}
+}
+
+// Limited returns whether the limiter has a limit.
+func (l *RateLimiter) Limited() bool {
+	return atomic.LoadInt64(&l.limit) > 0
+}
+
+// Wait blocks until n quota is available.
+func (l *RateLimiter) Wait(ctx context.Context, n int64) error {
+	if n <= 0 || !l.Limited() {
+		return nil
+	}
+	return l.limiter.WaitN(ctx, n)
+}
+
+// Acquire blocks until n quota is available and takes it ahead of the
+// operation it's for, whose actual size is only known once it's done. The
+// returned function settles the quota with the size of the operation, waiting
+// for more quota if it used more than n, and giving back what it didn't use.
+func (l *RateLimiter) Acquire(
+	ctx context.Context, n int64,
+) (settle func(ctx context.Context, used int64) error, _ error) {
+	if n <= 0 || !l.Limited() {
+		return l.Wait, nil
+	}
+	alloc, err := l.limiter.Acquire(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, used int64) error {
+		if used >= n {
+			alloc.Consume()
+			return l.Wait(ctx, used-n)
+		}
+		alloc.Return()
+		return l.Wait(ctx, used)
+	}, nil
+}
+
+// ConcurrencyLimiter is a quotapool.IntPool bounding the number of concurrent
+// operations, whose limit can be changed, or removed by setting it to 0, at any
+// time.
+type ConcurrencyLimiter struct {
+	// limit is accessed atomically.
+	limit int64
+	pool  *quotapool.IntPool
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter without a limit.
+func NewConcurrencyLimiter(name string) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{pool: quotapool.NewIntPool(name, 1)}
+}
+
+// SetLimit sets the number of operations which may run concurrently.
+func (l *ConcurrencyLimiter) SetLimit(limit int64) {
+	if atomic.SwapInt64(&l.limit, limit) != limit && limit > 0 {
+		l.pool.UpdateCapacity(uint64(limit))
+	}
+}
+
+// Acquire blocks until the operation can start, and returns a function to be
+// called once the operation is done.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (release func(), _ error) {
+	if atomic.LoadInt64(&l.limit) <= 0 {
+		return func() {}, nil
+	}
+	alloc, err := l.pool.Acquire(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	return alloc.Release, nil
+}
+
+// IOThrottle holds the limiters shared by all the BACKUP and RESTORE
+// processors running on a node, and the limiters shared by the processors of
+// each job running on the node. It's created when the server starts, and the
+// limits of its limiters are set by the processors using them.
+type IOThrottle struct {
+	BackupBytes    *RateLimiter
+	ExportRequests *ConcurrencyLimiter
+	RestoreBytes   *RateLimiter
+
+	mu struct {
+		syncutil.Mutex
+		// jobs maps the ID of every job with processors running on the node to
+		// the limiters shared by those processors.
+		jobs map[int64]*JobIOThrottle
+	}
+}
+
+// JobIOThrottle holds the limiters shared by the processors of a job running
+// on a node.
+type JobIOThrottle struct {
+	Bytes          *RateLimiter
+	ExportRequests *ConcurrencyLimiter
+
+	// refs is the number of processors of the job using the throttle, protected
+	// by the mutex of the IOThrottle.
+	refs int
+}
+
+// NewIOThrottle returns an IOThrottle without limits.
+func NewIOThrottle() *IOThrottle {
+	t := &IOThrottle{
+		BackupBytes:    NewRateLimiter("backup-bytes"),
+		ExportRequests: NewConcurrencyLimiter("backup-export-requests"),
+		RestoreBytes:   NewRateLimiter("restore-bytes"),
+	}
+	t.mu.jobs = make(map[int64]*JobIOThrottle)
+	return t
+}
+
+func newJobIOThrottle() *JobIOThrottle {
+	return &JobIOThrottle{
+		Bytes:          NewRateLimiter("job-bytes"),
+		ExportRequests: NewConcurrencyLimiter("job-export-requests"),
+	}
+}
+
+// AcquireJob returns the limiters of the job for one of its processors, which
+// must call ReleaseJob once it's done. Processors run outside of a job, with a
+// job ID of 0, don't share their limiters.
+func (t *IOThrottle) AcquireJob(jobID int64) *JobIOThrottle {
+	if jobID == 0 {
+		return newJobIOThrottle()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	jt, ok := t.mu.jobs[jobID]
+	if !ok {
+		jt = newJobIOThrottle()
+		t.mu.jobs[jobID] = jt
+	}
+	jt.refs++
+	return jt
+}
+
+// ReleaseJob releases the limiters returned by AcquireJob, removing them once
+// no processor of the job uses them anymore.
+func (t *IOThrottle) ReleaseJob(jobID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if jt, ok := t.mu.jobs[jobID]; ok {
+		if jt.refs--; jt.refs == 0 {
+			delete(t.mu.jobs, jobID)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package bulk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIOThrottleJobs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The processors of a job running on a node share the limiters of the job,
+	// while the processors of other jobs don't.
+	throttle := NewIOThrottle()
+	j1 := throttle.AcquireJob(1)
+	j2 := throttle.AcquireJob(1)
+	j3 := throttle.AcquireJob(2)
+	require.True(t, j1 == j2)
+	require.False(t, j1 == j3)
+	require.False(t, throttle.AcquireJob(0) == throttle.AcquireJob(0))
+
+	// The limiters of a job are removed once no processor uses them anymore.
+	throttle.ReleaseJob(1)
+	throttle.ReleaseJob(2)
+	require.Len(t, throttle.mu.jobs, 1)
+	throttle.ReleaseJob(1)
+	require.Empty(t, throttle.mu.jobs)
+}
+
+func TestRateLimiterAcquire(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	l := NewRateLimiter("test")
+	l.SetLimit(100)
+
+	// Quota acquired ahead of an operation which turns out smaller is given
+	// back.
+	settle, err := l.Acquire(ctx, 100)
+	require.NoError(t, err)
+	require.False(t, l.limiter.AdmitN(1))
+	require.NoError(t, settle(ctx, 10))
+	require.True(t, l.limiter.AdmitN(90))
+
+	// Without a limit, nothing is acquired.
+	l.SetLimit(0)
+	settle, err = l.Acquire(ctx, 1000)
+	require.NoError(t, err)
+	require.NoError(t, settle(ctx, 1000))
+}
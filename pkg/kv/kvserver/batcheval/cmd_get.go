@@ -36,6 +36,8 @@ func Get(
 		Inconsistent:     h.ReadConsistency != roachpb.CONSISTENT,
 		Txn:              h.Txn,
 		FailOnMoreRecent: args.KeyLocking != lock.None,
+		SkipLocked:       h.WaitPolicy == lock.WaitPolicy_SkipLocked,
+		LockTable:        cArgs.Concurrency,
 	})
 	if err != nil {
 		return result.Result{}, err
@@ -45,6 +45,8 @@ func ReverseScan(
 		MaxKeys:          h.MaxSpanRequestKeys,
 		TargetBytes:      h.TargetBytes,
 		FailOnMoreRecent: args.KeyLocking != lock.None,
+		SkipLocked:       h.WaitPolicy == lock.WaitPolicy_SkipLocked,
+		LockTable:        cArgs.Concurrency,
 		Reverse:          true,
 	}
 
@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -106,4 +107,8 @@ type CommandArgs struct {
 	Args    roachpb.Request
 	// *Stats should be mutated to reflect any writes made by the command.
 	Stats *enginepb.MVCCStats
+	// Concurrency is the request's concurrency guard, if any. It is consulted
+	// by reads with a SkipLocked wait policy to find keys that are locked by
+	// other transactions.
+	Concurrency *concurrency.Guard
 }
@@ -460,6 +460,17 @@ type lockTable interface {
 	// function.
 	ScanAndEnqueue(Request, lockTableGuard) lockTableGuard
 
+	// ScanOptimistic takes a snapshot of the lock table for later checking of
+	// conflicts, without entering the lock wait-queue of any conflicting locks.
+	// It is used by requests that want to evaluate without waiting on locks,
+	// such as those with a SkipLocked wait policy, which consult the returned
+	// lockTableGuard during evaluation to determine whether individual keys
+	// are locked. The returned guard never returns true from ShouldWait.
+	//
+	// The latches needed by the request must be held when calling this
+	// function.
+	ScanOptimistic(Request) lockTableGuard
+
 	// Dequeue removes the request from its lock wait-queues. It should be
 	// called when the request is finished, whether it evaluated or not. The
 	// guard should not be used after being dequeued.
@@ -599,6 +610,12 @@ type lockTableGuard interface {
 	// This must be called after the waiting state has transitioned to
 	// doneWaiting.
 	ResolveBeforeScanning() []roachpb.LockUpdate
+
+	// IsKeyLockedByConflictingTxn returns whether the specified key is locked
+	// or reserved by a conflicting transaction in the lockTableGuard's snapshot
+	// of the lock table, given the caller's own desired locking strength. A key
+	// locked by the request's own transaction is never considered locked.
+	IsKeyLockedByConflictingTxn(roachpb.Key, lock.Strength) bool
 }
 
 // lockTableWaiter is concerned with waiting in lock wait-queues for locks held
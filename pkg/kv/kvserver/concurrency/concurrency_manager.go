@@ -164,6 +164,18 @@ func (m *managerImpl) sequenceReqWithGuard(
 			return nil, nil
 		}
 
+		// Requests that skip locked keys don't wait on locks either. Instead,
+		// they snapshot the lock table and consult this snapshot during
+		// evaluation to determine which keys to skip over.
+		if req.WaitPolicy == lock.WaitPolicy_SkipLocked {
+			log.Event(ctx, "scanning lock table for locks to skip")
+			if g.ltg != nil {
+				m.lt.Dequeue(g.moveLockTableGuard())
+			}
+			g.ltg = m.lt.ScanOptimistic(g.Req)
+			return nil, nil
+		}
+
 		// Scan for conflicting locks.
 		log.Event(ctx, "scanning lock table for conflicting locks")
 		g.ltg = m.lt.ScanAndEnqueue(g.Req, g.ltg)
@@ -459,6 +471,18 @@ func (g *Guard) AssertNoLatches() {
 	}
 }
 
+// IsKeyLockedByConflictingTxn returns whether the specified key is locked or
+// reserved by a conflicting transaction, given the caller's own desired locking
+// strength. The method consults the guard's snapshot of the lock table, which
+// is only captured for requests with a SkipLocked wait policy. It implements
+// the storage.LockTableView interface.
+func (g *Guard) IsKeyLockedByConflictingTxn(key roachpb.Key, strength lock.Strength) bool {
+	if g == nil || g.ltg == nil {
+		return false
+	}
+	return g.ltg.IsKeyLockedByConflictingTxn(key, strength)
+}
+
 func (g *Guard) moveLatchGuard() latchGuard {
 	lg := g.lg
 	g.lg = nil
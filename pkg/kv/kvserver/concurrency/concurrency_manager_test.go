@@ -58,6 +58,8 @@ import (
 // sequence     req=<req-name>
 // finish       req=<req-name>
 //
+// is-key-locked-by-conflicting-txn  req=<req-name> key=<key> strength=<strength>
+//
 // handle-write-intent-error  req=<req-name> txn=<txn-name> key=<key> lease-seq=<seq>
 // handle-txn-push-error      req=<req-name> txn=<txn-name> key=<key>  TODO(nvanbenschoten): implement this
 //
@@ -228,6 +230,19 @@ func TestConcurrencyManagerBasic(t *testing.T) {
 				})
 				return c.waitAndCollect(t, mon)
 
+			case "is-key-locked-by-conflicting-txn":
+				var reqName string
+				d.ScanArgs(t, "req", &reqName)
+				guard, ok := c.guardsByReqName[reqName]
+				if !ok {
+					d.Fatalf(t, "unknown request: %s", reqName)
+				}
+				var key string
+				d.ScanArgs(t, "key", &key)
+				strength := scanLockStrength(t, d)
+				locked := guard.IsKeyLockedByConflictingTxn(roachpb.Key(key), strength)
+				return fmt.Sprintf("locked: %t", locked)
+
 			case "handle-write-intent-error":
 				var reqName string
 				d.ScanArgs(t, "req", &reqName)
@@ -418,6 +418,22 @@ func (g *lockTableGuardImpl) CurState() waitingState {
 	return g.mu.state
 }
 
+func (g *lockTableGuardImpl) IsKeyLockedByConflictingTxn(
+	key roachpb.Key, strength lock.Strength,
+) bool {
+	ss := spanset.SpanGlobal
+	if keys.IsLocal(key) {
+		ss = spanset.SpanLocal
+	}
+	iter := g.tableSnapshot[ss].MakeIter()
+	iter.SeekGE(&lockState{key: key})
+	if !iter.Valid() || !iter.Cur().key.Equal(key) {
+		// No lock on key.
+		return false
+	}
+	return iter.Cur().isLockedByConflictingTxn(g, strength)
+}
+
 func (g *lockTableGuardImpl) notify() {
 	select {
 	case g.mu.signal <- struct{}{}:
@@ -1078,6 +1094,36 @@ func (l *lockState) isLockedBy(id uuid.UUID) bool {
 	return false
 }
 
+// Returns true iff the lock is held or reserved by a transaction that
+// conflicts with the request g, which desires the given locking strength.
+// Non-locking reads only conflict with locks held at or below their read
+// timestamp, while locking reads also conflict with locks held above their
+// read timestamp and with reservations.
+// Acquires l.mu.
+func (l *lockState) isLockedByConflictingTxn(
+	g *lockTableGuardImpl, strength lock.Strength,
+) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lockHolderTxn, lockHolderTS := l.getLockHolder()
+	if lockHolderTxn == nil {
+		// Non-locking reads only care about the lock holder, not a reservation.
+		if strength == lock.None || l.reservation == nil {
+			return false
+		}
+		return !g.isSameTxn(l.reservation.txn)
+	}
+	if g.isSameTxn(lockHolderTxn) {
+		// Already locked by this txn.
+		return false
+	}
+	if strength == lock.None && g.ts.Less(lockHolderTS) {
+		// Non-locking reads below the lock's timestamp do not conflict.
+		return false
+	}
+	return true
+}
+
 // Returns information about the current lock holder if the lock is held, else
 // returns nil.
 // REQUIRES: l.mu is locked.
@@ -1975,14 +2021,7 @@ func (t *lockTableImpl) ScanAndEnqueue(req Request, guard lockTableGuard) lockTa
 
 	var g *lockTableGuardImpl
 	if guard == nil {
-		g = newLockTableGuardImpl()
-		g.seqNum = atomic.AddUint64(&t.seqNum, 1)
-		g.lt = t
-		g.txn = req.txnMeta()
-		g.ts = req.Timestamp
-		g.spans = req.LockSpans
-		g.sa = spanset.NumSpanAccess - 1
-		g.index = -1
+		g = t.newGuardForReq(req)
 	} else {
 		g = guard.(*lockTableGuardImpl)
 		g.key = nil
@@ -1995,6 +2034,31 @@ func (t *lockTableImpl) ScanAndEnqueue(req Request, guard lockTableGuard) lockTa
 		g.mu.Unlock()
 		g.toResolve = g.toResolve[:0]
 	}
+	t.doSnapshotForGuard(g)
+	g.findNextLockAfter(true /* notify */)
+	return g
+}
+
+// ScanOptimistic implements the lockTable interface.
+func (t *lockTableImpl) ScanOptimistic(req Request) lockTableGuard {
+	g := t.newGuardForReq(req)
+	t.doSnapshotForGuard(g)
+	return g
+}
+
+func (t *lockTableImpl) newGuardForReq(req Request) *lockTableGuardImpl {
+	g := newLockTableGuardImpl()
+	g.seqNum = atomic.AddUint64(&t.seqNum, 1)
+	g.lt = t
+	g.txn = req.txnMeta()
+	g.ts = req.Timestamp
+	g.spans = req.LockSpans
+	g.sa = spanset.NumSpanAccess - 1
+	g.index = -1
+	return g
+}
+
+func (t *lockTableImpl) doSnapshotForGuard(g *lockTableGuardImpl) {
 	for ss := spanset.SpanScope(0); ss < spanset.NumSpanScope; ss++ {
 		for sa := spanset.SpanAccess(0); sa < spanset.NumSpanAccess; sa++ {
 			if len(g.spans.GetSpans(sa, ss)) > 0 {
@@ -2010,8 +2074,6 @@ func (t *lockTableImpl) ScanAndEnqueue(req Request, guard lockTableGuard) lockTa
 			}
 		}
 	}
-	g.findNextLockAfter(true /* notify */)
-	return g
 }
 
 // Dequeue implements the lockTable interface.
@@ -78,6 +78,9 @@ func (g *mockLockTableGuard) CurState() waitingState {
 func (g *mockLockTableGuard) ResolveBeforeScanning() []roachpb.LockUpdate {
 	return g.toResolve
 }
+func (g *mockLockTableGuard) IsKeyLockedByConflictingTxn(roachpb.Key, lock.Strength) bool {
+	panic("unimplemented")
+}
 func (g *mockLockTableGuard) notify() { g.signal <- struct{}{} }
 
 // mockLockTable overrides TransactionIsFinalized, which is the only LockTable
@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -143,6 +144,10 @@ func optimizePuts(
 // evaluateBatch evaluates a batch request by splitting it up into its
 // individual commands, passing them to evaluateCommand, and combining
 // the results.
+//
+// The concurrency guard, if provided, is made available to the commands so
+// that requests with a SkipLocked wait policy can determine which keys are
+// locked by other transactions.
 func evaluateBatch(
 	ctx context.Context,
 	idKey kvserverbase.CmdIDKey,
@@ -150,6 +155,7 @@ func evaluateBatch(
 	rec batcheval.EvalContext,
 	ms *enginepb.MVCCStats,
 	ba *roachpb.BatchRequest,
+	g *concurrency.Guard,
 	readOnly bool,
 ) (_ *roachpb.BatchResponse, _ result.Result, retErr *roachpb.Error) {
 
@@ -268,7 +274,7 @@ func evaluateBatch(
 		// may carry a response transaction and in the case of WriteTooOldError
 		// (which is sometimes deferred) it is fully populated.
 		curResult, err := evaluateCommand(
-			ctx, idKey, index, readWriter, rec, ms, baHeader, args, reply)
+			ctx, idKey, index, readWriter, rec, ms, g, baHeader, args, reply)
 
 		if filter := rec.EvalKnobs().TestingPostEvalFilter; filter != nil {
 			filterArgs := kvserverbase.FilterArgs{
@@ -484,6 +490,7 @@ func evaluateCommand(
 	readWriter storage.ReadWriter,
 	rec batcheval.EvalContext,
 	ms *enginepb.MVCCStats,
+	g *concurrency.Guard,
 	h roachpb.Header,
 	args roachpb.Request,
 	reply roachpb.Response,
@@ -493,10 +500,11 @@ func evaluateCommand(
 
 	if cmd, ok := batcheval.LookupCommand(args.Method()); ok {
 		cArgs := batcheval.CommandArgs{
-			EvalCtx: rec,
-			Header:  h,
-			Args:    args,
-			Stats:   ms,
+			EvalCtx:     rec,
+			Header:      h,
+			Args:        args,
+			Stats:       ms,
+			Concurrency: g,
 		}
 
 		if cmd.EvalRW != nil {
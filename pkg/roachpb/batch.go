@@ -637,5 +637,8 @@ func (ba BatchRequest) ValidateForEvaluation() error {
 			return errors.AssertionFailedf("WriteTooOld set but no offset in timestamps. txn: %s", ba.Txn)
 		}
 	}
+	if ba.WaitPolicy == lock.WaitPolicy_SkipLocked && !ba.IsReadOnly() {
+		return errors.AssertionFailedf("batch with %s wait policy must be read-only", ba.WaitPolicy)
+	}
 	return nil
 }
@@ -334,6 +334,16 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 
 	backfillMemoryMonitor := execinfra.NewMonitor(ctx, bulkMemoryMonitor, "backfill-mon")
 
+	// changefeedMemoryMonitor is the parent to the memory monitors of all
+	// changefeeds running on this node, limiting the memory they use to buffer
+	// events. Changefeeds block rather than fail when it's exhausted.
+	changefeedMemoryMonitor := mon.NewMonitorInheritWithLimit(
+		"changefeed-mon", execinfra.SettingChangefeedMemBytes.Get(&cfg.Settings.SV), rootSQLMemoryMonitor)
+	changefeedMemoryMonitor.Start(context.Background(), rootSQLMemoryMonitor, mon.BoundAccount{})
+	execinfra.SettingChangefeedMemBytes.SetOnChange(&cfg.Settings.SV, func() {
+		changefeedMemoryMonitor.SetLimit(execinfra.SettingChangefeedMemBytes.Get(&cfg.Settings.SV))
+	})
+
 	// Set up the DistSQL temp engine.
 
 	useStoreSpec := cfg.TempStorageConfig.Spec
@@ -392,6 +402,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		VecFDSemaphore:    semaphore.New(envutil.EnvOrDefaultInt("COCKROACH_VEC_MAX_OPEN_FDS", colexec.VecMaxOpenFDsLimit)),
 		DiskMonitor:       cfg.TempStorageConfig.Mon,
 		BackfillerMonitor: backfillMemoryMonitor,
+		ChangefeedMonitor: changefeedMemoryMonitor,
 
 		ParentMemoryMonitor: rootSQLMemoryMonitor,
 		BulkAdder: func(
@@ -407,6 +418,8 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 			return bulk.MakeBulkAdder(ctx, db, cfg.distSender.RangeDescriptorCache(), cfg.Settings, ts, opts, bulkMon)
 		},
 
+		BulkIOThrottle: bulk.NewIOThrottle(),
+
 		Metrics: &distSQLMetrics,
 
 		SQLLivenessReader: cfg.sqlLivenessProvider,
@@ -10,7 +10,15 @@
 
 package schemaexpr
 
-import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
 
 // RunFilter runs a filter expression and returns whether the filter passes.
 func RunFilter(filter tree.TypedExpr, evalCtx *tree.EvalContext) (bool, error) {
@@ -26,6 +34,34 @@ func RunFilter(filter tree.TypedExpr, evalCtx *tree.EvalContext) (bool, error) {
 	return d == tree.DBoolTrue, nil
 }
 
+// MakeFilterExpr resolves, type checks and normalizes the boolean expression
+// expr over the given columns of tableDesc. The IndexedVars in the returned
+// expression refer to ordinals in cols, so it can be evaluated with RunFilter
+// using a RowIndexedVarContainer over rows laid out like cols.
+func MakeFilterExpr(
+	ctx context.Context,
+	expr tree.Expr,
+	cols []descpb.ColumnDescriptor,
+	tableDesc catalog.TableDescriptor,
+	evalCtx *tree.EvalContext,
+	semaCtx *tree.SemaContext,
+) (tree.TypedExpr, error) {
+	tn := tree.NewUnqualifiedTableName(tree.Name(tableDesc.GetName()))
+	nr := newNameResolver(evalCtx, tableDesc.GetID(), tn, columnDescriptorsToPtrs(cols))
+	nr.addIVarContainerToSemaCtx(semaCtx)
+
+	expr, err := nr.resolveNames(expr)
+	if err != nil {
+		return nil, err
+	}
+	typedExpr, err := tree.TypeCheck(ctx, expr, semaCtx, types.Bool)
+	if err != nil {
+		return nil, err
+	}
+	var txCtx transform.ExprTransformContext
+	return txCtx.NormalizeExpr(evalCtx, typedExpr)
+}
+
 type ivarRemapper struct {
 	indexVarMap []int
 }
@@ -3545,7 +3545,8 @@ func (dsp *DistSQLPlanner) createPlanForWindow(
 }
 
 // createPlanForExport creates a physical plan for EXPORT.
-// We add a new stage of CSVWriter processors to the input plan.
+// We add a new stage of CSVWriter processors to the input plan, which write
+// the files in the format of the export.
 func (dsp *DistSQLPlanner) createPlanForExport(
 	planCtx *PlanningCtx, n *exportNode,
 ) (*PhysicalPlan, error) {
@@ -3560,7 +3561,11 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		ChunkRows:        int64(n.chunkRows),
 		CompressionCodec: n.fileCompression,
 		UserProto:        planCtx.planner.User().EncodeProto(),
+		Format:           n.format,
 	}}
+	for _, col := range planColumns(n.source) {
+		core.CSVWriter.ColNames = append(core.CSVWriter.ColNames, col.Name)
+	}
 
 	resTypes := make([]*types.T, len(colinfo.ExportColumns))
 	for i := range colinfo.ExportColumns {
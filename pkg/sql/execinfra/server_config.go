@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/bulk"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangecache"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/diskmap"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
@@ -48,6 +49,15 @@ var SettingWorkMemBytes = settings.RegisterByteSizeSetting(
 	64*1024*1024, /* 64MB */
 )
 
+// SettingChangefeedMemBytes is a cluster setting that determines the maximum
+// amount of memory all the changefeeds running on a node may use to buffer
+// events.
+var SettingChangefeedMemBytes = settings.RegisterByteSizeSetting(
+	"changefeed.memory.node_limit",
+	"maximum amount of memory in bytes the changefeeds on a node can use to buffer events",
+	1<<30, /* 1GiB */
+)
+
 // ServerConfig encompasses the configuration required to create a
 // DistSQLServer.
 type ServerConfig struct {
@@ -99,10 +109,19 @@ type ServerConfig struct {
 	// BulkAdder is used by some processors to bulk-ingest data as SSTs.
 	BulkAdder kvserverbase.BulkAdderFactory
 
+	// BulkIOThrottle limits the IO of the BACKUP and RESTORE processors running
+	// on the node.
+	BulkIOThrottle *bulk.IOThrottle
+
 	// Child monitor of the bulk monitor which will be used to monitor the memory
 	// used by the column and index backfillers.
 	BackfillerMonitor *mon.BytesMonitor
 
+	// ChangefeedMonitor is the parent of the memory monitors of all changefeeds
+	// running on the node. Its limit is the node-wide budget for events
+	// buffered by changefeeds.
+	ChangefeedMonitor *mon.BytesMonitor
+
 	// DiskMonitor is used to monitor temporary storage disk usage. Actual disk
 	// space used will be a small multiple (~1.1) of this because of RocksDB
 	// space amplification.
@@ -43,6 +43,7 @@ type exportNode struct {
 	csvOpts         roachpb.CSVOptions
 	chunkRows       int
 	fileCompression execinfrapb.FileCompression
+	format          execinfrapb.ExportFormat
 }
 
 func (e *exportNode) startExec(params runParams) error {
@@ -79,9 +80,19 @@ var exportOptionExpectValues = map[string]KVStringOptValidate{
 
 const exportChunkRowsDefault = 100000
 const exportFilePatternPart = "%part%"
-const exportFilePatternDefault = exportFilePatternPart + ".csv"
 const exportCompressionCodec = "gzip"
 
+// exportFormats maps the formats supported by EXPORT to the format of the
+// files written by the CSVWriter processor and their extension.
+var exportFormats = map[string]struct {
+	format    execinfrapb.ExportFormat
+	extension string
+}{
+	"CSV":     {format: execinfrapb.ExportFormat_CSV, extension: "csv"},
+	"PARQUET": {format: execinfrapb.ExportFormat_Parquet, extension: "parquet"},
+	"JSON":    {format: execinfrapb.ExportFormat_JSON, extension: "json"},
+}
+
 // featureExportEnabled is used to enable and disable the EXPORT feature.
 var featureExportEnabled = settings.RegisterBoolSetting(
 	"feature.export.enabled",
@@ -113,7 +124,8 @@ func (ef *execFactory) ConstructExport(
 		return nil, errors.Errorf("EXPORT cannot be used inside a transaction")
 	}
 
-	if fileFormat != "CSV" {
+	format, ok := exportFormats[fileFormat]
+	if !ok {
 		return nil, errors.Errorf("unsupported export format: %q", fileFormat)
 	}
 
@@ -147,6 +159,15 @@ func (ef *execFactory) ConstructExport(
 		return nil, err
 	}
 
+	if format.format != execinfrapb.ExportFormat_CSV {
+		for _, opt := range []string{exportOptionDelimiter, exportOptionNullAs} {
+			if _, ok := optVals[opt]; ok {
+				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					"%s option is only supported by the CSV format", opt)
+			}
+		}
+	}
+
 	csvOpts := roachpb.CSVOptions{}
 
 	if override, ok := optVals[exportOptionDelimiter]; ok {
@@ -167,7 +188,7 @@ func (ef *execFactory) ConstructExport(
 			return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
 		}
 		if chunkRows < 1 {
-			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid csv chunk size")
+			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid chunk size")
 		}
 	}
 
@@ -184,7 +205,7 @@ func (ef *execFactory) ConstructExport(
 	}
 
 	exportID := ef.planner.stmt.QueryID.String()
-	namePattern := fmt.Sprintf("export%s-%s", exportID, exportFilePatternDefault)
+	namePattern := fmt.Sprintf("export%s-%s.%s", exportID, exportFilePatternPart, format.extension)
 
 	return &exportNode{
 		source:          input.(planNode),
@@ -193,5 +214,6 @@ func (ef *execFactory) ConstructExport(
 		csvOpts:         csvOpts,
 		chunkRows:       chunkRows,
 		fileCompression: codec,
+		format:          format.format,
 	}, nil
 }
@@ -282,9 +282,11 @@ func init() {
 		&tree.ShowBackup{},
 		&tree.Restore{},
 		&tree.CreateChangefeed{},
+		&tree.AlterChangefeed{},
 		&tree.Import{},
 		&tree.ScheduledBackup{},
 		&tree.StreamIngestion{},
+		&tree.ReplicationStream{},
 	} {
 		typ := optbuilder.OpaqueReadOnly
 		if tree.CanModifySchema(stmt) {
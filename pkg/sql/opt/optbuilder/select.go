@@ -530,6 +530,12 @@ func (b *Builder) buildScan(
 	}
 	if locking.isSet() {
 		private.Locking = locking.get()
+		if private.Locking.WaitPolicy == tree.LockWaitSkip && tab.FamilyCount() > 1 {
+			// Locks are acquired on individual column family keys, so skipping
+			// locked keys could return partial rows.
+			panic(unimplementedWithIssueDetailf(40476, "",
+				"SKIP LOCKED is not supported on tables with multiple column families"))
+		}
 	}
 
 	b.addCheckConstraintsForTable(tabMeta)
@@ -1326,8 +1332,7 @@ func (b *Builder) validateLockingInFrom(
 		case tree.LockWaitBlock:
 			// Default. Block on conflicting locks.
 		case tree.LockWaitSkip:
-			panic(unimplementedWithIssueDetailf(40476, "",
-				"SKIP LOCKED lock wait policy is not supported"))
+			// Skip over rows that are locked by other transactions.
 		case tree.LockWaitError:
 			// Raise an error on conflicting locks.
 		default:
@@ -82,6 +82,9 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER RANGE foo CONFIGURE ??`, `ALTER RANGE`},
 		{`ALTER RANGE ??`, `ALTER RANGE`},
 
+		{`ALTER CHANGEFEED ??`, `ALTER CHANGEFEED`},
+		{`ALTER CHANGEFEED 123 ADD ??`, `ALTER CHANGEFEED`},
+
 		{`ALTER PARTITION ??`, `ALTER PARTITION`},
 		{`ALTER PARTITION p OF INDEX tbl@idx ??`, `ALTER PARTITION`},
 
@@ -304,6 +307,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW BACKUP 'foo' ??`, `SHOW BACKUP`},
 
+		{`VALIDATE ??`, `VALIDATE BACKUP`},
+		{`VALIDATE BACKUP 'foo' ??`, `VALIDATE BACKUP`},
+
 		{`SHOW CLUSTER SETTING all ??`, `SHOW CLUSTER SETTING`},
 		{`SHOW ALL CLUSTER ??`, `SHOW CLUSTER SETTING`},
 
@@ -1682,6 +1682,11 @@ func TestParse(t *testing.T) {
 		{`SHOW BACKUPS IN $1`},
 		{`SHOW BACKUP 'foo' IN 'bar'`},
 		{`SHOW BACKUP $1 IN $2 WITH foo = 'bar'`},
+		{`SHOW BACKUP 'bar' WITH check_files`},
+		{`VALIDATE BACKUP 'bar'`},
+		{`VALIDATE BACKUP 'bar' WITH verify_checksums`},
+		{`VALIDATE BACKUP $1 IN $2 WITH encryption_passphrase = 'secret', verify_checksums`},
+		{`EXPLAIN VALIDATE BACKUP 'bar'`},
 
 		{`BACKUP TABLE foo TO 'bar' AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz'`},
 		{`BACKUP TABLE foo TO $1 INCREMENTAL FROM 'bar', $2, 'baz'`},
@@ -1729,6 +1734,8 @@ func TestParse(t *testing.T) {
 
 		{`RESTORE TENANT 123 FROM REPLICATION STREAM FROM 'bar'`},
 		{`RESTORE TENANT 123 FROM REPLICATION STREAM FROM $1`},
+		{`CREATE REPLICATION STREAM FOR TENANT 123`},
+		{`CREATE REPLICATION STREAM FOR TENANT 123 WITH cursor = '1.0', resolved = '1s'`},
 
 		{`BACKUP TABLE foo TO 'bar' WITH revision_history, detached`},
 		{`RESTORE TABLE foo FROM 'bar' WITH skip_missing_foreign_keys, skip_missing_sequences, detached`},
@@ -1761,6 +1768,9 @@ func TestParse(t *testing.T) {
 		// {`CREATE CHANGEFEED FOR TABLE foo PARTITION bar, baz INTO 'sink'`},
 		// {`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
+		{`ALTER CHANGEFEED 123 ADD TABLE foo`},
+		{`ALTER CHANGEFEED 123 ADD TABLE foo, db.bar DROP TABLE baz`},
+		{`ALTER CHANGEFEED $1 SET sink = 'sink', diff UNSET resolved, updated`},
 
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
@@ -2367,6 +2377,8 @@ $function$`,
 
 		{`CREATE CHANGEFEED FOR TABLE foo INTO sink`,
 			`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`ALTER CHANGEFEED 123 ADD foo DROP bar`,
+			`ALTER CHANGEFEED 123 ADD TABLE foo DROP TABLE bar`},
 
 		{`SHOW CLUSTER SETTING ALL`, `SHOW ALL CLUSTER SETTINGS`},
 		{`SHOW CLUSTER SETTINGS`, `SHOW PUBLIC CLUSTER SETTINGS`},
@@ -2570,6 +2582,10 @@ skip_missing_foreign_keys, skip_missing_sequences, skip_missing_sequence_owners,
 		{`RESTORE foo FROM 'bar' WITH ENCRYPTION_PASSPHRASE = 'secret', INTO_DB=baz,
 SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS, SKIP_MISSING_VIEWS`,
 			`RESTORE TABLE foo FROM 'bar' WITH encryption_passphrase='secret', into_db='baz', skip_missing_foreign_keys, skip_missing_sequence_owners, skip_missing_sequences, skip_missing_views`},
+		{`RESTORE DATABASE foo FROM 'bar' WITH new_db_name = 'baz', schema_only`,
+			`RESTORE DATABASE foo FROM 'bar' WITH new_db_name='baz', schema_only`},
+		{`RESTORE TABLE foo FROM 'bar' WITH into_db = 'baz', filter = 'id < 10'`,
+			`RESTORE TABLE foo FROM 'bar' WITH into_db='baz', filter='id < 10'`},
 
 		{`CREATE CHANGEFEED FOR foo INTO 'sink'`, `CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
 
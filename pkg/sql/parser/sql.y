@@ -410,6 +410,12 @@ func (u *sqlSymUnion) alterTableCmd() tree.AlterTableCmd {
 func (u *sqlSymUnion) alterTableCmds() tree.AlterTableCmds {
     return u.val.(tree.AlterTableCmds)
 }
+func (u *sqlSymUnion) alterChangefeedCmd() tree.AlterChangefeedCmd {
+    return u.val.(tree.AlterChangefeedCmd)
+}
+func (u *sqlSymUnion) alterChangefeedCmds() tree.AlterChangefeedCmds {
+    return u.val.(tree.AlterChangefeedCmds)
+}
 func (u *sqlSymUnion) alterIndexCmd() tree.AlterIndexCmd {
     return u.val.(tree.AlterIndexCmd)
 }
@@ -659,7 +665,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
 
-%token <str> NAN NAME NAMES NATURAL NEVER NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED NOCONTROLJOB
+%token <str> NAN NAME NAMES NATURAL NEVER NEW_DB_NAME NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED NOCONTROLJOB
 %token <str> NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING NO_INDEX_JOIN
 %token <str> NONE NORMAL NOT NOTHING NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
 
@@ -679,7 +685,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETRY REVISION_HISTORY REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE RUNNING
 
-%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCHEMA_ONLY SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_MISSING_FOREIGN_KEYS
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
@@ -693,7 +699,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> TRUNCATE TRUSTED TYPE TYPES
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSET UNSPLIT
 %token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL VISIBLE
@@ -736,6 +742,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> alter_range_stmt
 %type <tree.Statement> alter_partition_stmt
 %type <tree.Statement> alter_role_stmt
+%type <tree.Statement> alter_changefeed_stmt
 %type <tree.Statement> alter_type_stmt
 %type <tree.Statement> alter_schema_stmt
 
@@ -811,6 +818,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_changefeed_stmt
+%type <tree.Statement> create_replication_stream_stmt
 %type <tree.Statement> create_ddl_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_extension_stmt
@@ -883,6 +891,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %type <tree.Statement> show_stmt
 %type <tree.Statement> show_backup_stmt
+%type <tree.Statement> validate_backup_stmt
 %type <tree.Statement> show_columns_stmt
 %type <tree.Statement> show_constraints_stmt
 %type <tree.Statement> show_create_stmt
@@ -958,6 +967,8 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %type <tree.AlterTableCmd> alter_table_cmd
 %type <tree.AlterTableCmds> alter_table_cmds
+%type <tree.AlterChangefeedCmd> alter_changefeed_cmd
+%type <tree.AlterChangefeedCmds> alter_changefeed_cmds
 %type <tree.AlterIndexCmd> alter_index_cmd
 %type <tree.AlterIndexCmds> alter_index_cmds
 
@@ -1340,6 +1351,7 @@ alter_ddl_stmt:
 | alter_partition_stmt // EXTEND WITH HELP: ALTER PARTITION
 | alter_schema_stmt    // EXTEND WITH HELP: ALTER SCHEMA
 | alter_type_stmt      // EXTEND WITH HELP: ALTER TYPE
+| alter_changefeed_stmt // EXTEND WITH HELP: ALTER CHANGEFEED
 
 // %Help: ALTER TABLE - change the definition of a table
 // %Category: DDL
@@ -2479,6 +2491,9 @@ backup_options:
 //     If backups were already created in the destination in which a new schedule references,
 //     this flag must be passed in to acknowledge that the new schedule may be backing up different
 //     objects.
+//   * retention=INTERVAL:
+//     After each successful backup, delete the full backups, along with their incremental
+//     backups, whose most recent backup is older than the specified interval.
 //
 // %SeeAlso: BACKUP
 create_schedule_for_backup_stmt:
@@ -2574,6 +2589,7 @@ opt_with_schedule_options:
 //
 // Options:
 //    into_db: specify target database
+//    new_db_name: specify the name of the restored database
 //    skip_missing_foreign_keys: remove foreign key constraints before restoring
 //    skip_missing_sequences: ignore sequence dependencies
 //    skip_missing_views: skip restoring views because of dependencies that cannot be restored
@@ -2581,6 +2597,8 @@ opt_with_schedule_options:
 //    encryption_passphrase=passphrase: decrypt BACKUP with specified passphrase
 //    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt backups using KMS
 //    detached: execute restore job asynchronously, without waiting for its completion
+//    schema_only: restore the descriptors of the backup without its data
+//    filter="predicate": restore only the rows of a single table whose primary key satisfies the predicate
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
   RESTORE FROM list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
@@ -2712,6 +2730,18 @@ restore_options:
   {
     $$.val = &tree.RestoreOptions{Detached: true}
   }
+| NEW_DB_NAME '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{NewDBName: $3.expr()}
+  }
+| SCHEMA_ONLY
+  {
+    $$.val = &tree.RestoreOptions{SchemaOnly: true}
+  }
+| FILTER '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{Filter: $3.expr()}
+  }
 
 import_format:
   name
@@ -2735,9 +2765,12 @@ import_format:
 //        [ WITH <option> [= <value>] [, ...] ]
 //
 // Formats:
+//    CHANGEFEED
 //    CSV
 //    DELIMITED
 //    MYSQLDUMP
+//    NDJSON
+//    PARQUET
 //    PGCOPY
 //    PGDUMP
 //
@@ -2748,6 +2781,13 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    on_conflict = '...'    [IMPORT INTO-specific]
+//    max_row_errors = '...'        [CSV, DELIMITED, NDJSON-specific]
+//    rejected_rows_dir = '...'     [CSV, DELIMITED, NDJSON-specific]
+//    ignore_unsupported_statements [PGDUMP-specific]
+//    log_ignored_statements = '...' [PGDUMP-specific]
+//    resolved = '...'       [CHANGEFEED-specific]
+//    topic = '...'          [CHANGEFEED-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -2800,9 +2840,14 @@ import_stmt:
 //
 // Formats:
 //    CSV
+//    PARQUET
+//    JSON                one object per line
 //
 // Options:
 //    delimiter = '...'   [CSV-specific]
+//    nullas = '...'      [CSV-specific]
+//    chunk_rows = '...'
+//    compression = 'gzip'
 //
 // %SeeAlso: SELECT
 export_stmt:
@@ -3183,6 +3228,7 @@ drop_unsupported:
 
 create_ddl_stmt:
   create_changefeed_stmt
+| create_replication_stream_stmt
 | create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
 | create_index_stmt    // EXTEND WITH HELP: CREATE INDEX
 | create_schema_stmt   // EXTEND WITH HELP: CREATE SCHEMA
@@ -3307,6 +3353,15 @@ create_changefeed_stmt:
     }
   }
 
+create_replication_stream_stmt:
+  CREATE REPLICATION STREAM FOR targets opt_with_options
+  {
+    $$.val = &tree.ReplicationStream{
+      Targets: $5.targetList(),
+      Options: $6.kvOptions(),
+    }
+  }
+
 changefeed_targets:
   single_table_pattern_list
   {
@@ -3327,6 +3382,58 @@ single_table_pattern_list:
     $$.val = append($1.tablePatterns(), $3.unresolvedObjectName().ToUnresolvedName())
   }
 
+// %Help: ALTER CHANGEFEED - alter the targets and options of a paused changefeed
+// %Category: CCL
+// %Text: ALTER CHANGEFEED <job_id> <command> [...]
+//
+// Commands:
+//   ALTER CHANGEFEED ... ADD [TABLE] <targets...>
+//   ALTER CHANGEFEED ... DROP [TABLE] <targets...>
+//   ALTER CHANGEFEED ... SET <option> [= <value>] [, ...]
+//   ALTER CHANGEFEED ... UNSET <option> [, ...]
+//
+// %SeeAlso: CREATE CHANGEFEED, PAUSE JOBS, RESUME JOBS
+alter_changefeed_stmt:
+  ALTER CHANGEFEED a_expr alter_changefeed_cmds
+  {
+    $$.val = &tree.AlterChangefeed{
+      Jobs: $3.expr(),
+      Cmds: $4.alterChangefeedCmds(),
+    }
+  }
+| ALTER CHANGEFEED error // SHOW HELP: ALTER CHANGEFEED
+
+alter_changefeed_cmds:
+  alter_changefeed_cmd
+  {
+    $$.val = tree.AlterChangefeedCmds{$1.alterChangefeedCmd()}
+  }
+| alter_changefeed_cmds alter_changefeed_cmd
+  {
+    $$.val = append($1.alterChangefeedCmds(), $2.alterChangefeedCmd())
+  }
+
+alter_changefeed_cmd:
+  // ALTER CHANGEFEED <job_id> ADD [TABLE] <targets>
+  ADD changefeed_targets
+  {
+    $$.val = &tree.AlterChangefeedAddTarget{Targets: $2.targetList()}
+  }
+  // ALTER CHANGEFEED <job_id> DROP [TABLE] <targets>
+| DROP changefeed_targets
+  {
+    $$.val = &tree.AlterChangefeedDropTarget{Targets: $2.targetList()}
+  }
+  // ALTER CHANGEFEED <job_id> SET <options>
+| SET kv_option_list
+  {
+    $$.val = &tree.AlterChangefeedSetOptions{Options: $2.kvOptions()}
+  }
+  // ALTER CHANGEFEED <job_id> UNSET <options>
+| UNSET name_list
+  {
+    $$.val = &tree.AlterChangefeedUnsetOptions{Options: $2.nameList()}
+  }
 
 opt_changefeed_sink:
   INTO string_or_placeholder
@@ -3724,6 +3831,7 @@ preparable_stmt:
 | truncate_stmt     // EXTEND WITH HELP: TRUNCATE
 | update_stmt       // EXTEND WITH HELP: UPDATE
 | upsert_stmt       // EXTEND WITH HELP: UPSERT
+| validate_backup_stmt // EXTEND WITH HELP: VALIDATE BACKUP
 
 // These are statements that can be used as a data source using the special
 // syntax with brackets. These are a subset of preparable_stmt.
@@ -4530,8 +4638,17 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
-// %SeeAlso: WEBDOCS/show-backup.html
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Options:
+//    encryption_passphrase="secret": decrypt the backup
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt the backup using KMS
+//    privileges: show the privileges of the backed up objects
+//    check_files: check that every file referenced by the backup exists
+//
+// %SeeAlso: VALIDATE BACKUP, WEBDOCS/show-backup.html
 show_backup_stmt:
   SHOW BACKUPS IN string_or_placeholder
  {
@@ -4585,6 +4702,41 @@ show_backup_stmt:
   }
 | SHOW BACKUP error // SHOW HELP: SHOW BACKUP
 
+// %Help: VALIDATE BACKUP - check the files of a backup without restoring it
+// %Category: CCL
+// %Text:
+// VALIDATE BACKUP <location> [ IN <collection> ]
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Reports, for every file referenced by the backup, whether it exists and is
+// non-empty. The files of locality-aware backups can't be checked yet.
+//
+// Options:
+//    encryption_passphrase="secret": decrypt the backup
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt the backup using KMS
+//    verify_checksums: read every file, verifying its checksum, keys and size
+//
+// %SeeAlso: SHOW BACKUP
+validate_backup_stmt:
+  VALIDATE BACKUP string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupValidateDetails,
+      Path:    $3.expr(),
+      Options: $4.kvOptions(),
+    }
+  }
+| VALIDATE BACKUP string_or_placeholder IN string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupValidateDetails,
+      Path:    $3.expr(),
+      InCollection: $5.expr(),
+      Options: $6.kvOptions(),
+    }
+  }
+| VALIDATE error // SHOW HELP: VALIDATE BACKUP
+
 // %Help: SHOW CLUSTER SETTING - display cluster settings
 // %Category: Cfg
 // %Text:
@@ -12383,6 +12535,7 @@ unreserved_keyword:
 | NAMES
 | NAN
 | NEVER
+| NEW_DB_NAME
 | NEXT
 | NO
 | NORMAL
@@ -12476,6 +12629,7 @@ unreserved_keyword:
 | SCATTER
 | SCHEMA
 | SCHEMAS
+| SCHEMA_ONLY
 | SCRUB
 | SEARCH
 | SECOND
@@ -12535,6 +12689,7 @@ unreserved_keyword:
 | UNCOMMITTED
 | UNKNOWN
 | UNLOGGED
+| UNSET
 | UNSPLIT
 | UNTIL
 | UPDATE
@@ -271,7 +271,8 @@ type Fetcher struct {
 	isCheck bool
 
 	// IgnoreUnexpectedNulls allows Fetcher to return null values for non-nullable
-	// columns and is only used for decoding for error messages or debugging.
+	// columns and is only used for decoding for error messages or debugging, and
+	// by changefeeds, which decode one column family of a row at a time.
 	IgnoreUnexpectedNulls bool
 
 	// Buffered allocation of decoded datums.
@@ -47,6 +47,8 @@ func (i KVInserter) Del(key ...interface{}) {
 	// to worry about it.
 	//
 	// IMPORT INTO disallows overwriting an existing row, so we're also okay here.
+	// With the on_conflict option, rows colliding with an existing row are not
+	// inserted through a KVInserter but updated transactionally instead.
 	// The reason this works is that row existence is precisely defined as whether
 	// column family 0 exists, meaning that we write column family 0 even if all
 	// the non-pk columns in it are NULL. It follows that either the row does
@@ -242,7 +242,8 @@ func (c *RowContainer) AddRow(ctx context.Context, row tree.Datums) (tree.Datums
 	}
 	// Note that it is important that we perform the memory accounting before
 	// actually adding the row.
-	if err := c.memAcc.Grow(ctx, c.rowSize(row)); err != nil {
+	rowSize := c.rowSize(row)
+	if err := c.memAcc.Grow(ctx, rowSize); err != nil {
 		return nil, err
 	}
 	chunk, pos := c.getChunkAndPos(c.numRows)
@@ -250,6 +251,8 @@ func (c *RowContainer) AddRow(ctx context.Context, row tree.Datums) (tree.Datums
 		// Grow the number of chunks by a fraction.
 		numChunks := 1 + len(c.chunks)/8
 		if err := c.allocChunks(ctx, numChunks); err != nil {
+			// The row wasn't added, so callers may retry once memory frees up.
+			c.memAcc.Shrink(ctx, rowSize)
 			return nil, err
 		}
 	}
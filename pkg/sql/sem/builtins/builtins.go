@@ -55,11 +55,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/fuzzystrmatch"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -4708,6 +4710,113 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"crdb_internal.complete_stream_ingestion_job": makeBuiltin(
+		tree.FunctionProperties{
+			Category:     categoryMultiTenancy,
+			Undocumented: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"job_id", types.Int},
+				{"cutover_ts", types.TimestampTZ},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if CompleteStreamIngestion == nil {
+					return nil, sqlerrors.NewCCLRequiredError(errors.New(
+						"completing a stream ingestion job requires a CCL binary"))
+				}
+				jobID := int64(tree.MustBeDInt(args[0]))
+				cutoverTime := tree.MustBeDTimestampTZ(args[1]).Time
+				cutoverTimestamp := hlc.Timestamp{WallTime: cutoverTime.UnixNano()}
+				if err := CompleteStreamIngestion(evalCtx, evalCtx.Txn, jobID, cutoverTimestamp); err != nil {
+					return nil, err
+				}
+				return args[0], nil
+			},
+			Info: "This function can be used to signal a running stream ingestion job to complete. " +
+				"The job will stop ingesting once it has ingested all data up to the specified " +
+				"timestamp, revert any data ingested above it and leave the ingested keyspace " +
+				"in a consistent state as of that timestamp. The function returns the job ID " +
+				"as soon as the job has been signaled, without waiting for it to complete.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.compact_backup": makeBuiltin(
+		tree.FunctionProperties{
+			Category:     categorySystemInfo,
+			Undocumented: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"backup_uri", types.String},
+				{"destination_uri", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return compactBackup(evalCtx, args[0], args[1], "" /* passphrase */)
+			},
+			Info: "This function creates a job compacting the full backup stored at backup_uri " +
+				"and the incremental backups appended to it into a new full backup stored at " +
+				"destination_uri, and returns the ID of the job.",
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"backup_uri", types.String},
+				{"destination_uri", types.String},
+				{"encryption_passphrase", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return compactBackup(evalCtx, args[0], args[1], string(tree.MustBeDString(args[2])))
+			},
+			Info: "This function creates a job compacting the full backup stored at backup_uri " +
+				"and the incremental backups appended to it, which are encrypted with " +
+				"encryption_passphrase, into a new full backup stored at destination_uri, and " +
+				"returns the ID of the job.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.set_job_io_limits": makeBuiltin(
+		tree.FunctionProperties{
+			Category:     categorySystemInfo,
+			Undocumented: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"job_id", types.Int},
+				{"max_bytes_per_second", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return setJobIOLimits(evalCtx, args[0], args[1], tree.NewDInt(0))
+			},
+			Info: "This function limits the rate at which each node reads or writes data for " +
+				"the running BACKUP or RESTORE job with the given ID. 0 means unlimited. The " +
+				"limit is picked up by the job while it is running.",
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"job_id", types.Int},
+				{"max_bytes_per_second", types.Int},
+				{"max_concurrent_export_requests", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return setJobIOLimits(evalCtx, args[0], args[1], args[2])
+			},
+			Info: "This function limits the rate at which each node writes data and the number " +
+				"of export requests each node sends concurrently for the running BACKUP job " +
+				"with the given ID. 0 means unlimited. The limits are picked up by the job " +
+				"while it is running.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	"crdb_internal.compact_engine_span": makeBuiltin(
 		tree.FunctionProperties{
 			Category:         categorySystemRepair,
@@ -6918,6 +7027,58 @@ func checkPrivilegedUser(ctx *tree.EvalContext) error {
 // if an enterprise license is not installed.
 var EvalFollowerReadOffset func(clusterID uuid.UUID, _ *cluster.Settings) (time.Duration, error)
 
+// CompleteStreamIngestion signals a running stream ingestion job to complete
+// its ingestion as of the cutover timestamp. It is injected by streamingest.
+var CompleteStreamIngestion func(
+	evalCtx *tree.EvalContext, txn *kv.Txn, jobID int64, cutoverTimestamp hlc.Timestamp,
+) error
+
+// CompactBackup creates a job compacting the full backup at uri and the
+// incremental backups appended to it into a new full backup at destination,
+// and returns the ID of the job. It is injected by backupccl.
+var CompactBackup func(
+	evalCtx *tree.EvalContext, txn *kv.Txn, uri, destination, passphrase string,
+) (int64, error)
+
+func compactBackup(
+	evalCtx *tree.EvalContext, uri, destination tree.Datum, passphrase string,
+) (tree.Datum, error) {
+	if CompactBackup == nil {
+		return nil, sqlerrors.NewCCLRequiredError(errors.New(
+			"compacting a backup requires a CCL binary"))
+	}
+	jobID, err := CompactBackup(evalCtx, evalCtx.Txn,
+		string(tree.MustBeDString(uri)), string(tree.MustBeDString(destination)), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDInt(tree.DInt(jobID)), nil
+}
+
+// SetJobIOLimits records the limits of a BACKUP or RESTORE job in its progress,
+// from which they are picked up by the running job. It is injected by
+// backupccl.
+var SetJobIOLimits func(
+	evalCtx *tree.EvalContext, txn *kv.Txn, jobID, maxBytesPerSecond, maxConcurrentExportRequests int64,
+) error
+
+func setJobIOLimits(
+	evalCtx *tree.EvalContext, jobID, maxBytesPerSecond, maxConcurrentExportRequests tree.Datum,
+) (tree.Datum, error) {
+	if SetJobIOLimits == nil {
+		return nil, sqlerrors.NewCCLRequiredError(errors.New(
+			"limiting the IO of a job requires a CCL binary"))
+	}
+	if err := SetJobIOLimits(evalCtx, evalCtx.Txn,
+		int64(tree.MustBeDInt(jobID)),
+		int64(tree.MustBeDInt(maxBytesPerSecond)),
+		int64(tree.MustBeDInt(maxConcurrentExportRequests)),
+	); err != nil {
+		return nil, err
+	}
+	return jobID, nil
+}
+
 func recentTimestamp(ctx *tree.EvalContext) (time.Time, error) {
 	if EvalFollowerReadOffset == nil {
 		telemetry.Inc(sqltelemetry.FollowerReadDisabledCCLCounter)
@@ -114,6 +114,9 @@ type RestoreOptions struct {
 	SkipMissingSequenceOwners bool
 	SkipMissingViews          bool
 	Detached                  bool
+	NewDBName                 Expr
+	SchemaOnly                bool
+	Filter                    Expr
 }
 
 var _ NodeFormatter = &RestoreOptions{}
@@ -321,6 +324,23 @@ func (o *RestoreOptions) Format(ctx *FmtCtx) {
 		maybeAddSep()
 		ctx.WriteString("detached")
 	}
+
+	if o.NewDBName != nil {
+		maybeAddSep()
+		ctx.WriteString("new_db_name=")
+		o.NewDBName.Format(ctx)
+	}
+
+	if o.SchemaOnly {
+		maybeAddSep()
+		ctx.WriteString("schema_only")
+	}
+
+	if o.Filter != nil {
+		maybeAddSep()
+		ctx.WriteString("filter=")
+		o.Filter.Format(ctx)
+	}
 }
 
 // CombineWith merges other backup options into this backup options struct.
@@ -384,6 +404,26 @@ func (o *RestoreOptions) CombineWith(other *RestoreOptions) error {
 		o.Detached = other.Detached
 	}
 
+	if o.NewDBName == nil {
+		o.NewDBName = other.NewDBName
+	} else if other.NewDBName != nil {
+		return errors.New("new_db_name specified multiple times")
+	}
+
+	if o.SchemaOnly {
+		if other.SchemaOnly {
+			return errors.New("schema_only specified multiple times")
+		}
+	} else {
+		o.SchemaOnly = other.SchemaOnly
+	}
+
+	if o.Filter == nil {
+		o.Filter = other.Filter
+	} else if other.Filter != nil {
+		return errors.New("filter specified multiple times")
+	}
+
 	return nil
 }
 
@@ -397,5 +437,8 @@ func (o RestoreOptions) IsDefault() bool {
 		cmp.Equal(o.DecryptionKMSURI, options.DecryptionKMSURI) &&
 		o.EncryptionPassphrase == options.EncryptionPassphrase &&
 		o.IntoDB == options.IntoDB &&
-		o.Detached == options.Detached
+		o.Detached == options.Detached &&
+		o.NewDBName == options.NewDBName &&
+		o.SchemaOnly == options.SchemaOnly &&
+		o.Filter == options.Filter
 }
@@ -83,9 +83,11 @@ const (
 	BackupRangeDetails
 	// BackupFileDetails identifies a SHOW BACKUP FILES statement.
 	BackupFileDetails
+	// BackupValidateDetails identifies a VALIDATE BACKUP statement.
+	BackupValidateDetails
 )
 
-// ShowBackup represents a SHOW BACKUP statement.
+// ShowBackup represents a SHOW BACKUP or VALIDATE BACKUP statement.
 type ShowBackup struct {
 	Path                 Expr
 	InCollection         Expr
@@ -101,7 +103,11 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 		ctx.FormatNode(node.InCollection)
 		return
 	}
-	ctx.WriteString("SHOW BACKUP ")
+	if node.Details == BackupValidateDetails {
+		ctx.WriteString("VALIDATE BACKUP ")
+	} else {
+		ctx.WriteString("SHOW BACKUP ")
+	}
 	if node.Details == BackupRangeDetails {
 		ctx.WriteString("RANGES ")
 	} else if node.Details == BackupFileDetails {
@@ -147,10 +147,12 @@ var _ CCLOnlyStatement = &Backup{}
 var _ CCLOnlyStatement = &ShowBackup{}
 var _ CCLOnlyStatement = &Restore{}
 var _ CCLOnlyStatement = &CreateChangefeed{}
+var _ CCLOnlyStatement = &AlterChangefeed{}
 var _ CCLOnlyStatement = &Import{}
 var _ CCLOnlyStatement = &Export{}
 var _ CCLOnlyStatement = &ScheduledBackup{}
 var _ CCLOnlyStatement = &StreamIngestion{}
+var _ CCLOnlyStatement = &ReplicationStream{}
 
 // StatementType implements the Statement interface.
 func (*AlterDatabaseOwner) StatementType() StatementType { return DDL }
@@ -374,6 +376,14 @@ func (*CopyFrom) StatementType() StatementType { return CopyIn }
 // StatementTag returns a short string identifying the type of statement.
 func (*CopyFrom) StatementTag() string { return "COPY" }
 
+// StatementType implements the Statement interface.
+func (*AlterChangefeed) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterChangefeed) StatementTag() string { return "ALTER CHANGEFEED" }
+
+func (*AlterChangefeed) cclOnlyStatement() {}
+
 // StatementType implements the Statement interface.
 func (*CreateChangefeed) StatementType() StatementType { return Rows }
 
@@ -676,6 +686,14 @@ func (n *Relocate) StatementTag() string {
 	return "EXPERIMENTAL_RELOCATE"
 }
 
+// StatementType implements the Statement interface.
+func (*ReplicationStream) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ReplicationStream) StatementTag() string { return "CREATE REPLICATION STREAM" }
+
+func (*ReplicationStream) cclOnlyStatement() {}
+
 // StatementType implements the Statement interface.
 func (*Restore) StatementType() StatementType { return Rows }
 
@@ -819,7 +837,12 @@ func (*ShowCreate) StatementTag() string { return "SHOW CREATE" }
 func (*ShowBackup) StatementType() StatementType { return Rows }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*ShowBackup) StatementTag() string { return "SHOW BACKUP" }
+func (n *ShowBackup) StatementTag() string {
+	if n.Details == BackupValidateDetails {
+		return "VALIDATE BACKUP"
+	}
+	return "SHOW BACKUP"
+}
 
 func (*ShowBackup) cclOnlyStatement() {}
 
@@ -1092,6 +1115,7 @@ func (n *AlterTableSetNotNull) String() string           { return AsString(n) }
 func (n *AlterTableOwner) String() string                { return AsString(n) }
 func (n *AlterTableSetSchema) String() string            { return AsString(n) }
 func (n *AlterType) String() string                      { return AsString(n) }
+func (n *AlterChangefeed) String() string                { return AsString(n) }
 func (n *AlterRole) String() string                      { return AsString(n) }
 func (n *AlterSequence) String() string                  { return AsString(n) }
 func (n *Analyze) String() string                        { return AsString(n) }
@@ -1149,6 +1173,7 @@ func (n *RenameDatabase) String() string                 { return AsString(n) }
 func (n *ReparentDatabase) String() string               { return AsString(n) }
 func (n *RenameIndex) String() string                    { return AsString(n) }
 func (n *RenameTable) String() string                    { return AsString(n) }
+func (n *ReplicationStream) String() string              { return AsString(n) }
 func (n *Restore) String() string                        { return AsString(n) }
 func (n *Revoke) String() string                         { return AsString(n) }
 func (n *RevokeRole) String() string                     { return AsString(n) }
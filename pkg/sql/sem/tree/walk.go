@@ -1081,6 +1081,26 @@ func (stmt *Restore) walkStmt(v Visitor) Statement {
 			ret.Options.IntoDB = intoDB
 		}
 	}
+
+	if stmt.Options.NewDBName != nil {
+		newDBName, changed := WalkExpr(v, stmt.Options.NewDBName)
+		if changed {
+			if ret == stmt {
+				ret = stmt.copyNode()
+			}
+			ret.Options.NewDBName = newDBName
+		}
+	}
+
+	if stmt.Options.Filter != nil {
+		filter, changed := WalkExpr(v, stmt.Options.Filter)
+		if changed {
+			if ret == stmt {
+				ret = stmt.copyNode()
+			}
+			ret.Options.Filter = filter
+		}
+	}
 	return ret
 }
 
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -769,6 +770,12 @@ type MVCCGetOptions struct {
 	Tombstones       bool
 	FailOnMoreRecent bool
 	Txn              *roachpb.Transaction
+	// SkipLocked indicates that the get should ignore a key that is locked by
+	// another transaction, returning no value instead of a WriteIntentError.
+	// Locks are found by consulting both the intents stored in the engine and
+	// the LockTable, if one is provided.
+	SkipLocked bool
+	LockTable  LockTableView
 }
 
 func (opts *MVCCGetOptions) validate() error {
@@ -778,9 +785,25 @@ func (opts *MVCCGetOptions) validate() error {
 	if opts.Inconsistent && opts.FailOnMoreRecent {
 		return errors.Errorf("cannot allow inconsistent reads with fail on more recent option")
 	}
+	if opts.Inconsistent && opts.SkipLocked {
+		return errors.Errorf("cannot allow inconsistent reads with skip locked option")
+	}
 	return nil
 }
 
+// LockTableView is a transaction-bound view into an in-memory collection of
+// key-level locks. The set of locks stored in the in-memory lock table
+// overlaps with the intents stored in the engine, but one is not a subset of
+// the other: there are intents which have not been discovered by the lock
+// table and there are unreplicated locks which are only stored in the lock
+// table.
+type LockTableView interface {
+	// IsKeyLockedByConflictingTxn returns whether the specified key is locked or
+	// reserved by a conflicting transaction, given the caller's own desired
+	// locking strength.
+	IsKeyLockedByConflictingTxn(roachpb.Key, lock.Strength) bool
+}
+
 func newMVCCIterator(reader Reader, inlineMeta bool, opts IterOptions) MVCCIterator {
 	iterKind := MVCCKeyAndIntentsIterKind
 	if inlineMeta {
@@ -853,6 +876,8 @@ func mvccGet(
 		inconsistent:     opts.Inconsistent,
 		tombstones:       opts.Tombstones,
 		failOnMoreRecent: opts.FailOnMoreRecent,
+		skipLocked:       opts.SkipLocked,
+		lockTable:        opts.LockTable,
 		keyBuf:           mvccScanner.keyBuf,
 	}
 
@@ -2312,6 +2337,8 @@ func mvccScanToBytes(
 		inconsistent:     opts.Inconsistent,
 		tombstones:       opts.Tombstones,
 		failOnMoreRecent: opts.FailOnMoreRecent,
+		skipLocked:       opts.SkipLocked,
+		lockTable:        opts.LockTable,
 		keyBuf:           mvccScanner.keyBuf,
 	}
 
@@ -2425,6 +2452,12 @@ type MVCCScanOptions struct {
 	//
 	// The zero value indicates no limit.
 	TargetBytes int64
+	// SkipLocked indicates that the scan should skip over keys that are locked
+	// by other transactions instead of returning a WriteIntentError. Locks are
+	// found by consulting both the intents stored in the engine and the
+	// LockTable, if one is provided.
+	SkipLocked bool
+	LockTable  LockTableView
 }
 
 func (opts *MVCCScanOptions) validate() error {
@@ -2434,6 +2467,9 @@ func (opts *MVCCScanOptions) validate() error {
 	if opts.Inconsistent && opts.FailOnMoreRecent {
 		return errors.Errorf("cannot allow inconsistent reads with fail on more recent option")
 	}
+	if opts.Inconsistent && opts.SkipLocked {
+		return errors.Errorf("cannot allow inconsistent reads with skip locked option")
+	}
 	return nil
 }
 
@@ -2484,6 +2520,11 @@ type MVCCScanResult struct {
 // the read timestamp, the maximum will be returned in the WriteTooOldError.
 // Similarly, a WriteIntentError will be returned if the scan observes another
 // transaction's intent, even if it has a timestamp above the read timestamp.
+//
+// When scanning in "skip locked" mode, keys that are locked by transactions
+// other than the reader are not included in the result set and do not result
+// in a WriteIntentError. Keys are considered locked if they have a conflicting
+// intent or if the LockTableView reports a conflicting lock on them.
 func MVCCScan(
 	ctx context.Context,
 	reader Reader,
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -734,6 +735,92 @@ func TestMVCCScanWriteIntentError(t *testing.T) {
 	}
 }
 
+// exclusiveLockTable is a LockTableView which reports the configured keys as
+// locked by a conflicting transaction to locking reads only.
+type exclusiveLockTable map[string]bool
+
+func (lt exclusiveLockTable) IsKeyLockedByConflictingTxn(key roachpb.Key, str lock.Strength) bool {
+	return str == lock.Exclusive && lt[string(key)]
+}
+
+// TestMVCCScanSkipLocked verifies that gets and scans with the SkipLocked
+// option omit keys with conflicting intents or locks instead of returning a
+// WriteIntentError.
+func TestMVCCScanSkipLocked(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			ts1 := hlc.Timestamp{WallTime: 1}
+			for _, key := range []roachpb.Key{testKey1, testKey2, testKey3, testKey4} {
+				require.NoError(t, MVCCPut(ctx, engine, nil, key, ts1, value1, nil))
+			}
+			txn1ts := makeTxn(*txn1, hlc.Timestamp{WallTime: 2})
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey2, txn1ts.WriteTimestamp, value2, txn1ts))
+			lockTable := exclusiveLockTable{string(testKey3): true}
+
+			readTS := hlc.Timestamp{WallTime: 3}
+			txn2ts := makeTxn(*txn2, readTS)
+			keysOf := func(kvs []roachpb.KeyValue) []roachpb.Key {
+				var res []roachpb.Key
+				for _, kv := range kvs {
+					res = append(res, kv.Key)
+				}
+				return res
+			}
+
+			for _, tc := range []struct {
+				name    string
+				txn     *roachpb.Transaction
+				locking bool
+				reverse bool
+				expKeys []roachpb.Key
+			}{
+				{"locking", txn2ts, true, false, []roachpb.Key{testKey1, testKey4}},
+				{"locking-reverse", txn2ts, true, true, []roachpb.Key{testKey4, testKey1}},
+				{"non-locking", txn2ts, false, false, []roachpb.Key{testKey1, testKey3, testKey4}},
+				{"own-intent", txn1ts, true, false, []roachpb.Key{testKey1, testKey2, testKey4}},
+			} {
+				t.Run(tc.name, func(t *testing.T) {
+					res, err := MVCCScan(ctx, engine, testKey1, testKey5, readTS, MVCCScanOptions{
+						Txn:              tc.txn,
+						Reverse:          tc.reverse,
+						FailOnMoreRecent: tc.locking,
+						SkipLocked:       true,
+						LockTable:        lockTable,
+					})
+					require.NoError(t, err)
+					require.Empty(t, res.Intents)
+					require.Equal(t, tc.expKeys, keysOf(res.KVs))
+				})
+			}
+
+			for _, key := range []roachpb.Key{testKey2, testKey3} {
+				val, intent, err := MVCCGet(ctx, engine, key, readTS, MVCCGetOptions{
+					Txn:              txn2ts,
+					FailOnMoreRecent: true,
+					SkipLocked:       true,
+					LockTable:        lockTable,
+				})
+				require.NoError(t, err)
+				require.Nil(t, intent)
+				require.Nil(t, val)
+			}
+
+			_, err := MVCCScan(ctx, engine, testKey1, testKey5, readTS, MVCCScanOptions{
+				Inconsistent: true,
+				SkipLocked:   true,
+			})
+			require.Regexp(t, "cannot allow inconsistent reads with skip locked option", err)
+		})
+	}
+}
+
 // TestMVCCGetInconsistent verifies the behavior of get with
 // consistent set to false.
 func TestMVCCGetInconsistent(t *testing.T) {
@@ -16,6 +16,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -109,12 +110,16 @@ type pebbleMVCCScanner struct {
 	txnEpoch          enginepb.TxnEpoch
 	txnSequence       enginepb.TxnSeq
 	txnIgnoredSeqNums []enginepb.IgnoredSeqNumRange
+	// View into the in-memory lock table, consulted when skipLocked is set to
+	// find locks which are not stored as intents. May be nil.
+	lockTable LockTableView
 	// Metadata object for unmarshalling intents.
 	meta enginepb.MVCCMetadata
 	// Bools copied over from MVCC{Scan,Get}Options. See the comment on the
 	// package level MVCCScan for what these mean.
 	inconsistent, tombstones bool
 	failOnMoreRecent         bool
+	skipLocked               bool
 	checkUncertainty         bool
 	isGet                    bool
 	keyBuf                   []byte
@@ -429,6 +434,12 @@ func (p *pebbleMVCCScanner) getAndAdvance() bool {
 		// Note that this will trigger an error higher up the stack. We
 		// continue scanning so that we can return all of the intents
 		// in the scan range.
+		//
+		// If the scanner has been configured to skip locked keys, we
+		// instead ignore the key entirely and move on to the next one.
+		if p.skipLocked {
+			return p.advanceKey()
+		}
 		p.err = p.intents.Set(p.curRawKey, p.curValue, nil)
 		if p.err != nil {
 			return false
@@ -492,6 +503,22 @@ func (p *pebbleMVCCScanner) getAndAdvance() bool {
 	return p.seekVersion(prevTS, false)
 }
 
+// isKeyLockedByConflictingTxn returns whether the current key is locked by a
+// transaction other than the reader's in the lock table. Locking reads (those
+// that fail on more recent values) conflict with all locks held by other
+// transactions, while non-locking reads only conflict with locks held at or
+// below the read timestamp.
+func (p *pebbleMVCCScanner) isKeyLockedByConflictingTxn() bool {
+	if p.lockTable == nil {
+		return false
+	}
+	strength := lock.None
+	if p.failOnMoreRecent {
+		strength = lock.Exclusive
+	}
+	return p.lockTable.IsKeyLockedByConflictingTxn(p.curKey.Key, strength)
+}
+
 // nextKey advances to the next user key.
 func (p *pebbleMVCCScanner) nextKey() bool {
 	p.keyBuf = append(p.keyBuf[:0], p.curKey.Key...)
@@ -610,6 +637,11 @@ func (p *pebbleMVCCScanner) addAndAdvance(rawKey []byte, val []byte) bool {
 	// Don't include deleted versions len(val) == 0, unless we've been instructed
 	// to include tombstones in the results.
 	if len(val) > 0 || p.tombstones {
+		if p.skipLocked && p.isKeyLockedByConflictingTxn() {
+			// The key is locked by another transaction in the lock table, so
+			// leave it out of the results.
+			return p.advanceKey()
+		}
 		p.results.put(rawKey, val)
 		if p.targetBytes > 0 && p.results.bytes >= p.targetBytes {
 			// When the target bytes are met or exceeded, stop producing more
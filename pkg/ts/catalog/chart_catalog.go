@@ -1045,6 +1045,18 @@ var charts = []sectionDescription{
 					"changefeed.max_behind_nanos",
 				},
 			},
+			{
+				Title: "Current Memory Usage",
+				Metrics: []string{
+					"changefeed.mem.current",
+				},
+			},
+			{
+				Title: "Memory Usage per Changefeed",
+				Metrics: []string{
+					"changefeed.mem.max",
+				},
+			},
 			{
 				Title: "Min High Water",
 				Metrics: []string{
@@ -1066,6 +1078,7 @@ var charts = []sectionDescription{
 			{
 				Title: "Total Time Spent",
 				Metrics: []string{
+					"changefeed.buffer_pushback_nanos",
 					"changefeed.emit_nanos",
 					"changefeed.flush_nanos",
 					"changefeed.processing_nanos",
@@ -2312,6 +2325,7 @@ var charts = []sectionDescription{
 				Metrics: []string{
 					"jobs.auto_create_stats.currently_running",
 					"jobs.backup.currently_running",
+					"jobs.backup_compaction.currently_running",
 					"jobs.changefeed.currently_running",
 					"jobs.create_stats.currently_running",
 					"jobs.import.currently_running",
@@ -2347,6 +2361,18 @@ var charts = []sectionDescription{
 				},
 				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
 			},
+			{
+				Title: "Backup Compaction",
+				Metrics: []string{
+					"jobs.backup_compaction.fail_or_cancel_completed",
+					"jobs.backup_compaction.fail_or_cancel_failed",
+					"jobs.backup_compaction.fail_or_cancel_retry_error",
+					"jobs.backup_compaction.resume_completed",
+					"jobs.backup_compaction.resume_failed",
+					"jobs.backup_compaction.resume_retry_error",
+				},
+				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
 			{
 				Title: "Changefeed",
 				Metrics: []string{
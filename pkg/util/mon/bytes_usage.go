@@ -444,6 +444,19 @@ func (mm *BytesMonitor) AllocBytes() int64 {
 	return mm.mu.curAllocated
 }
 
+// SetLimit changes the limit local to this monitor. A limit of zero or less
+// means the monitor is only limited by its pool. Allocations already made
+// above a lowered limit are not affected, but new ones are denied until the
+// monitor is back under it.
+func (mm *BytesMonitor) SetLimit(limit int64) {
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.limit = limit
+}
+
 // SetMetrics sets the metric objects for the monitor.
 func (mm *BytesMonitor) SetMetrics(curCount *metric.Gauge, maxHist *metric.Histogram) {
 	mm.mu.Lock()
@@ -348,6 +348,46 @@ func TestMemoryAllocationEdgeCases(t *testing.T) {
 	m.Stop(ctx)
 }
 
+func TestBytesMonitorSetLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	m := NewMonitorWithLimit("test", MemoryResource, 100, /* limit */
+		nil /* curCount */, nil /* maxHist */, 1 /* increment */, 1e9 /* noteworthy */, st)
+	m.Start(ctx, nil, MakeStandaloneBudget(1e9))
+
+	a := m.MakeBoundAccount()
+	if err := a.Grow(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Grow(ctx, 1); err == nil {
+		t.Fatalf("expected error, but found success")
+	}
+
+	m.SetLimit(200)
+	if err := a.Grow(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	m.SetLimit(50)
+	if err := a.Grow(ctx, 1); err == nil {
+		t.Fatalf("expected error, but found success")
+	}
+	a.Shrink(ctx, 160)
+	if err := a.Grow(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	m.SetLimit(0)
+	if err := a.Grow(ctx, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Close(ctx)
+	m.Stop(ctx)
+}
+
 func BenchmarkBoundAccountGrow(b *testing.B) {
 	ctx := context.Background()
 	m := NewMonitor("test", MemoryResource,